@@ -48,7 +48,7 @@ func (a *Api) ChatMessage(c *gin.Context) {
 
 	// 验证会话是否属于当前用户
 	var conversation business.AIConversation
-	if err := global.JY_DB.Where("id = ? AND user_id = ?", req.ConversationID, userID).First(&conversation).Error; err != nil {
+	if err := global.JY_DB.WithContext(c).Where("id = ? AND user_id = ?", req.ConversationID, userID).First(&conversation).Error; err != nil {
 		common.FailWithMsg(c, "会话不存在或无权限")
 		return
 	}
@@ -60,14 +60,14 @@ func (a *Api) ChatMessage(c *gin.Context) {
 		Content:        req.Content,
 		UserID:         userID,
 	}
-	if err := global.JY_DB.Create(&userMessage).Error; err != nil {
+	if err := global.JY_DB.WithContext(c).Create(&userMessage).Error; err != nil {
 		common.FailWithMsg(c, "保存消息失败")
 		return
 	}
 
 	// 获取会话历史消息（用于上下文）
 	var messages []business.AIMessage
-	global.JY_DB.Where("conversation_id = ?", req.ConversationID).Order("created_at ASC").Find(&messages)
+	global.JY_DB.WithContext(c).Where("conversation_id = ?", req.ConversationID).Order("created_at ASC").Find(&messages)
 
 	// 设置 SSE 响应头
 	c.Header("Content-Type", "text/event-stream")
@@ -119,7 +119,7 @@ func (a *Api) ChatMessage(c *gin.Context) {
 		Content:        assistantContent.String(),
 		UserID:         userID,
 	}
-	if err := global.JY_DB.Create(&assistantMessage).Error; err != nil {
+	if err := global.JY_DB.WithContext(c).Create(&assistantMessage).Error; err != nil {
 		// 记录错误但不中断流式返回
 		global.JY_LOG.Error("保存助手消息失败", zap.Error(err))
 	}
@@ -129,7 +129,7 @@ func (a *Api) ChatMessage(c *gin.Context) {
 	if len(lastMsg) > 100 {
 		lastMsg = lastMsg[:100]
 	}
-	global.JY_DB.Model(&conversation).Updates(map[string]interface{}{
+	global.JY_DB.WithContext(c).Model(&conversation).Updates(map[string]interface{}{
 		"last_msg":      lastMsg,
 		"message_count": conversation.MessageCount + 2, // 用户消息 + 助手消息
 	})
@@ -42,7 +42,7 @@ func (a *Api) CreateConversation(c *gin.Context) {
 		MessageCount:  0,
 	}
 
-	if err := global.JY_DB.Create(&conversation).Error; err != nil {
+	if err := global.JY_DB.WithContext(c).Create(&conversation).Error; err != nil {
 		common.FailWithMsg(c, "创建会话失败")
 		return
 	}
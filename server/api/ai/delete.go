@@ -38,16 +38,16 @@ func (a *Api) DeleteConversation(c *gin.Context) {
 
 	// 验证会话是否属于当前用户
 	var conversation business.AIConversation
-	if err := global.JY_DB.Where("id = ? AND user_id = ?", id, userID).First(&conversation).Error; err != nil {
+	if err := global.JY_DB.WithContext(c).Where("id = ? AND user_id = ?", id, userID).First(&conversation).Error; err != nil {
 		common.FailWithMsg(c, "会话不存在或无权限")
 		return
 	}
 
 	// 删除会话下的所有消息
-	global.JY_DB.Where("conversation_id = ?", id).Delete(&business.AIMessage{})
+	global.JY_DB.WithContext(c).Where("conversation_id = ?", id).Delete(&business.AIMessage{})
 
 	// 删除会话
-	if err := global.JY_DB.Delete(&conversation).Error; err != nil {
+	if err := global.JY_DB.WithContext(c).Delete(&conversation).Error; err != nil {
 		common.FailWithMsg(c, "删除失败")
 		return
 	}
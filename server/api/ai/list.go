@@ -44,7 +44,7 @@ func (a *Api) GetConversationList(c *gin.Context) {
 
 	var conversations []business.AIConversation
 	var total int64
-	db := global.JY_DB.Model(&business.AIConversation{}).Where("user_id = ?", userID)
+	db := global.JY_DB.WithContext(c).Model(&business.AIConversation{}).Where("user_id = ?", userID)
 	err := db.Count(&total).Error
 	if err != nil {
 		common.FailWithMsg(c, "统计失败")
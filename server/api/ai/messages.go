@@ -46,7 +46,7 @@ func (a *Api) GetMessageList(c *gin.Context) {
 
 	// 验证会话是否属于当前用户
 	var conversation business.AIConversation
-	if err := global.JY_DB.Where("id = ? AND user_id = ?", id, userID).First(&conversation).Error; err != nil {
+	if err := global.JY_DB.WithContext(c).Where("id = ? AND user_id = ?", id, userID).First(&conversation).Error; err != nil {
 		common.FailWithMsg(c, "会话不存在或无权限")
 		return
 	}
@@ -63,7 +63,7 @@ func (a *Api) GetMessageList(c *gin.Context) {
 	// 按创建时间倒序（最新的在前），分页
 	var messages []business.AIMessage
 	var total int64
-	db := global.JY_DB.Model(&business.AIMessage{}).Where("conversation_id = ?", id)
+	db := global.JY_DB.WithContext(c).Model(&business.AIMessage{}).Where("conversation_id = ?", id)
 	if err := db.Count(&total).Error; err != nil {
 		common.FailWithMsg(c, "统计失败")
 		return
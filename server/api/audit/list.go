@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+type AuditListRequest struct {
+	Page         int        `json:"page" form:"page"`                                             // 页码
+	PageSize     int        `json:"pageSize" form:"pageSize"`                                     // 每页大小
+	Action       string     `json:"action" form:"action"`                                         // 操作，如 customer.merge
+	UserID       uint       `json:"userId" form:"userId"`                                         // 操作人ID
+	ResourceType string     `json:"resourceType" form:"resourceType"`                             // 资源类型
+	ResourceID   uint       `json:"resourceId" form:"resourceId"`                                 // 资源ID
+	StartTime    *time.Time `json:"startTime" form:"startTime" time_format:"2006-01-02 15:04:05"` // 操作时间起
+	EndTime      *time.Time `json:"endTime" form:"endTime" time_format:"2006-01-02 15:04:05"`     // 操作时间止
+}
+
+// GetAuditList 获取审计日志
+// @Summary      分页获取审计日志
+// @Description  分页获取当前租户的审计日志，按操作时间倒序，可按操作、操作人、资源、操作时间筛选。需要查看审计日志的操作权限（audit:view）
+// @Security     ApiKeyAuth
+// @Tags         Audit
+// @Produce      json
+// @Param        data  query     AuditListRequest  false  "页码, 每页大小, 操作, 操作人ID, 资源类型, 资源ID, 操作时间"
+// @Success      200   {object}  common.Response{data=common.PageResult{list=[]system.SysAuditLog},msg=string}  "查询成功"
+// @Router       /audit/list [get]
+func (a *Api) GetAuditList(c *gin.Context) {
+	if !utils.HasPermission(c, system.PermissionAuditView) {
+		common.FailWithMsg(c, "没有查看审计日志的权限")
+		return
+	}
+
+	var params AuditListRequest
+	if err := c.ShouldBindQuery(&params); err != nil {
+		common.FailWithMsg(c, "绑定失败")
+		return
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PageSize <= 0 {
+		params.PageSize = 10
+	}
+
+	query := global.JY_DB.WithContext(c).Model(&system.SysAuditLog{})
+	if params.Action != "" {
+		query = query.Where("action = ?", params.Action)
+	}
+	if params.UserID != 0 {
+		query = query.Where("user_id = ?", params.UserID)
+	}
+	if params.ResourceType != "" {
+		query = query.Where("resource_type = ?", params.ResourceType)
+	}
+	if params.ResourceID != 0 {
+		query = query.Where("resource_id = ?", params.ResourceID)
+	}
+	if params.StartTime != nil {
+		query = query.Where("created_at >= ?", *params.StartTime)
+	}
+	if params.EndTime != nil {
+		query = query.Where("created_at <= ?", *params.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		common.FailWithMsg(c, "查询失败")
+		return
+	}
+	var logs []system.SysAuditLog
+	if err := query.Order("id DESC").Limit(params.PageSize).Offset((params.Page - 1) * params.PageSize).Find(&logs).Error; err != nil {
+		common.FailWithMsg(c, "查询失败")
+		return
+	}
+
+	common.OkWithDetailed(c, common.PageResult{
+		List:     logs,
+		Total:    total,
+		Page:     params.Page,
+		PageSize: params.PageSize,
+	}, "查询成功")
+}
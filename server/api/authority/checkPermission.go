@@ -0,0 +1,94 @@
+package authority
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+type CheckPermissionRequest struct {
+	UserId      uint   `json:"userId"`      // 用户ID（与角色ID二选一）
+	AuthorityId string `json:"authorityId"` // 角色ID
+	Method      string `json:"method"`      // 请求方法
+	Path        string `json:"path"`        // 请求路径，需与实际请求一致（包含路由前缀）
+	Action      string `json:"action"`      // 操作权限标识，见操作权限列表接口（与请求路径至少填一项）
+}
+
+// CheckPermission 权限模拟
+// @Summary      权限模拟
+// @Description  模拟指定用户或角色访问某个接口或执行某个操作（如查看客户完整电话），返回是否放行以及决定该结果的规则链。指定用户时会同时判定其生效中的临时授权角色
+// @Description  同时指定接口和操作权限时，两者都放行才放行
+// @Security     ApiKeyAuth
+// @Tags         Authority
+// @Accept       json
+// @Produce      json
+// @Param        data  body      CheckPermissionRequest  true  "用户ID或角色ID, 请求方法, 请求路径, 操作权限标识"
+// @Success      200   {object}  common.Response{data=utils.PermissionDecision,msg=string}  "获取成功"
+// @Router       /authority/checkPermission [post]
+func (a *Api) CheckPermission(c *gin.Context) {
+	var req CheckPermissionRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+	if req.UserId == 0 && req.AuthorityId == "" {
+		common.FailWithMsg(c, "用户ID和角色ID不能同时为空")
+		return
+	}
+	if req.Path == "" && req.Action == "" {
+		common.FailWithMsg(c, "请求路径和操作权限不能同时为空")
+		return
+	}
+	if req.Path != "" && req.Method == "" {
+		common.FailWithMsg(c, "请求方法不能为空")
+		return
+	}
+
+	var chain []utils.PermissionStep
+	authorityId := req.AuthorityId
+	if req.UserId != 0 {
+		var user system.SysUser
+		err = global.JY_DB.WithContext(c).Where("id = ?", req.UserId).First(&user).Error
+		if err != nil {
+			common.FailWithMsg(c, "用户不存在")
+			return
+		}
+		authorityId = user.AuthorityId
+		chain = append(chain, utils.PermissionStep{
+			Rule:   "user",
+			Result: utils.PermissionInfo,
+			Detail: fmt.Sprintf("用户 %s(ID:%d) 的角色为 %s", user.Username, user.ID, user.AuthorityId),
+		})
+	}
+
+	var authority system.SysAuthority
+	err = global.JY_DB.WithContext(c).Where("authority_id = ?", authorityId).Preload("SysBaseMenus").Preload("DataAuthority").Preload("DataScopeDepts").First(&authority).Error
+	if err != nil {
+		// 与 RBACAuth 中间件一致：角色不存在直接拒绝
+		decision := utils.PermissionDecision{AuthorityId: authorityId, Method: req.Method, Path: req.Path, Action: req.Action, Chain: chain}
+		decision.AddStep("role", utils.PermissionDeny, fmt.Sprintf("角色 %s 不存在", authorityId))
+		common.OkWithDetailed(c, decision, "获取成功")
+		return
+	}
+
+	var decision utils.PermissionDecision
+	if req.Path != "" {
+		decision = utils.ExplainUserPermission(req.UserId, &authority, req.Path, req.Method)
+	}
+	if req.Action != "" {
+		action := utils.ExplainUserAction(req.UserId, &authority, req.Action)
+		action.Allowed = action.Allowed && (req.Path == "" || decision.Allowed)
+		action.Method, action.Path = decision.Method, decision.Path
+		action.Chain = append(decision.Chain, action.Chain...)
+		decision = action
+	}
+	decision.Chain = append(chain, decision.Chain...)
+	// 数据范围不影响是否放行，附在最后方便核对用户实际能看到哪些数据
+	decision.Chain = append(decision.Chain, utils.ExplainDataScope(req.UserId, &authority))
+	common.OkWithDetailed(c, decision, "获取成功")
+}
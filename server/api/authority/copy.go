@@ -0,0 +1,110 @@
+package authority
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+type CopyAuthorityRequest struct {
+	OldAuthorityId string              `json:"oldAuthorityId" binding:"required"` // 被复制的角色ID
+	Authority      system.SysAuthority `json:"authority"`                         // 新角色ID, 角色名, 父角色ID
+}
+
+// CopyAuthority 复制角色
+// @Summary      复制角色
+// @Description  以已有角色为模板创建新角色，同时复制其菜单权限、数据权限、数据范围和操作权限，仅超级管理员可以操作
+// @Security     ApiKeyAuth
+// @Tags         Authority
+// @Accept       json
+// @Produce      json
+// @Param        data  body      CopyAuthorityRequest  true  "被复制的角色ID, 新角色信息"
+// @Success      200   {object}  common.Response{data=system.SysAuthority,msg=string}  "复制成功"
+// @Router       /authority/copy [post]
+func (a *Api) CopyAuthority(c *gin.Context) {
+	if !utils.CheckSuperAdmin(c) {
+		return
+	}
+
+	var req CopyAuthorityRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+	if req.Authority.AuthorityId == "" || req.Authority.AuthorityName == "" {
+		common.FailWithMsg(c, "新角色ID和角色名不能为空")
+		return
+	}
+
+	var newAuthority system.SysAuthority
+	err = global.JY_DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var oldAuthority system.SysAuthority
+		if err := tx.Where("authority_id = ?", req.OldAuthorityId).Preload("SysBaseMenus").Preload("DataAuthority").Preload("DataScopeDepts").First(&oldAuthority).Error; err != nil {
+			return errors.New("被复制的角色不存在")
+		}
+
+		var count int64
+		tx.Model(&system.SysAuthority{}).Where("authority_id = ?", req.Authority.AuthorityId).Count(&count)
+		if count > 0 {
+			return errors.New("角色ID已存在")
+		}
+
+		newAuthority = system.SysAuthority{
+			AuthorityId:   req.Authority.AuthorityId,
+			AuthorityName: req.Authority.AuthorityName,
+			ParentId:      req.Authority.ParentId,
+			DefaultRouter: oldAuthority.DefaultRouter,
+			DataScope:     oldAuthority.DataScope,
+			Permissions:   oldAuthority.Permissions,
+			Enable:        true,
+		}
+		if newAuthority.ParentId == "" {
+			newAuthority.ParentId = oldAuthority.ParentId
+		}
+		if err := tx.Create(&newAuthority).Error; err != nil {
+			return errors.New("创建角色失败")
+		}
+
+		// 复制菜单权限
+		if len(oldAuthority.SysBaseMenus) > 0 {
+			if err := tx.Model(&newAuthority).Association("SysBaseMenus").Append(oldAuthority.SysBaseMenus); err != nil {
+				return errors.New("复制菜单权限失败")
+			}
+		}
+
+		// 复制数据权限，原角色对自身数据的权限转换为新角色对自身数据的权限
+		if len(oldAuthority.DataAuthority) > 0 {
+			dataAuthority := make([]system.SysAuthority, 0, len(oldAuthority.DataAuthority))
+			for _, data := range oldAuthority.DataAuthority {
+				if data.AuthorityId == oldAuthority.AuthorityId {
+					data = newAuthority
+				}
+				dataAuthority = append(dataAuthority, data)
+			}
+			if err := tx.Model(&newAuthority).Association("DataAuthority").Append(dataAuthority); err != nil {
+				return errors.New("复制数据权限失败")
+			}
+		}
+
+		// 复制自定义数据范围的部门
+		if len(oldAuthority.DataScopeDepts) > 0 {
+			if err := tx.Model(&newAuthority).Association("DataScopeDepts").Append(oldAuthority.DataScopeDepts); err != nil {
+				return errors.New("复制数据范围失败")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		common.FailWithMsg(c, err.Error())
+		return
+	}
+
+	global.JY_DB.WithContext(c).Where("authority_id = ?", newAuthority.AuthorityId).Preload("SysBaseMenus").Preload("DataAuthority").Preload("DataScopeDepts").First(&newAuthority)
+	common.OkWithDetailed(c, newAuthority, "复制成功")
+}
@@ -5,6 +5,7 @@ import (
 	"jiangyi.com/global"
 	"jiangyi.com/model/common"
 	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
 )
 
 // CreateAuthority 创建角色
@@ -24,7 +25,11 @@ func (a *Api) CreateAuthority(c *gin.Context) {
 		common.FailWithMsg(c, "参数绑定失败")
 		return
 	}
-	err = global.JY_DB.Create(&auth).Error
+	if auth.Permissions, err = utils.NormalizePermissions(auth.Permissions); err != nil {
+		common.FailWithMsg(c, err.Error())
+		return
+	}
+	err = global.JY_DB.WithContext(c).Create(&auth).Error
 	if err != nil {
 		common.FailWithMsg(c, "创建角色失败")
 		return
@@ -0,0 +1,99 @@
+package authority
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+type CreateGrantRequest struct {
+	UserId      uint      `json:"userId" binding:"required"`      // 被授权用户ID
+	AuthorityId string    `json:"authorityId" binding:"required"` // 临时授予的角色ID
+	StartAt     time.Time `json:"startAt"`                        // 生效时间，为空时立即生效
+	EndAt       time.Time `json:"endAt" binding:"required"`       // 失效时间
+	Reason      string    `json:"reason" binding:"required"`      // 授权原因
+}
+
+// CreateGrant 创建临时角色授权
+// @Summary      创建临时角色授权
+// @Description  在指定时间段内为用户追加一个角色，到期后自动收回，审批人为当前登录用户，仅超级管理员可以操作，不能为自己授权
+// @Security     ApiKeyAuth
+// @Tags         Authority
+// @Accept       json
+// @Produce      json
+// @Param        data  body      CreateGrantRequest  true  "用户ID, 角色ID, 生效时间, 失效时间, 授权原因"
+// @Success      200   {object}  common.Response{data=system.SysAuthorityGrant,msg=string}  "授权成功"
+// @Router       /authority/grant [post]
+func (a *Api) CreateGrant(c *gin.Context) {
+	if !utils.CheckSuperAdmin(c) {
+		return
+	}
+
+	var req CreateGrantRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		common.FailWithMsg(c, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+	// 审批人不能是被授权用户本人
+	if req.UserId == waitClaims.ID {
+		common.FailWithMsg(c, "不能为自己授权")
+		return
+	}
+
+	if req.StartAt.IsZero() {
+		req.StartAt = time.Now()
+	}
+	if !req.EndAt.After(req.StartAt) || !req.EndAt.After(time.Now()) {
+		common.FailWithMsg(c, "失效时间必须晚于生效时间和当前时间")
+		return
+	}
+
+	var user system.SysUser
+	err = global.JY_DB.WithContext(c).Where("id = ?", req.UserId).First(&user).Error
+	if err != nil {
+		common.FailWithMsg(c, "用户不存在")
+		return
+	}
+	if user.AuthorityId == req.AuthorityId {
+		common.FailWithMsg(c, "用户已拥有该角色")
+		return
+	}
+
+	var count int64
+	global.JY_DB.WithContext(c).Model(&system.SysAuthority{}).Where("authority_id = ?", req.AuthorityId).Count(&count)
+	if count == 0 {
+		common.FailWithMsg(c, "角色不存在")
+		return
+	}
+
+	grant := system.SysAuthorityGrant{
+		UserId:      req.UserId,
+		AuthorityId: req.AuthorityId,
+		StartAt:     req.StartAt,
+		EndAt:       req.EndAt,
+		Reason:      req.Reason,
+		ApproverId:  waitClaims.ID,
+		Status:      system.GrantStatusActive,
+	}
+	err = global.JY_DB.WithContext(c).Create(&grant).Error
+	if err != nil {
+		common.FailWithMsg(c, "创建授权失败")
+		return
+	}
+
+	utils.NotifyGrant(grant, utils.GrantEventCreated)
+
+	common.OkWithDetailed(c, grant, "授权成功")
+}
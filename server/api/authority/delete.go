@@ -27,7 +27,7 @@ func (a *Api) DeleteAuthority(c *gin.Context) {
 
 	// 检查是否有用户使用该角色
 	var userCount int64
-	global.JY_DB.Model(&system.SysUser{}).Where("authority_id = ?", auth.AuthorityId).Count(&userCount)
+	global.JY_DB.WithContext(c).Model(&system.SysUser{}).Where("authority_id = ?", auth.AuthorityId).Count(&userCount)
 	if userCount > 0 {
 		common.FailWithMsg(c, "该角色已被用户使用，无法删除")
 		return
@@ -35,14 +35,14 @@ func (a *Api) DeleteAuthority(c *gin.Context) {
 
 	// 检查是否有子角色
 	var childCount int64
-	global.JY_DB.Model(&system.SysAuthority{}).Where("parent_id = ?", auth.AuthorityId).Count(&childCount)
+	global.JY_DB.WithContext(c).Model(&system.SysAuthority{}).Where("parent_id = ?", auth.AuthorityId).Count(&childCount)
 	if childCount > 0 {
 		common.FailWithMsg(c, "该角色下存在子角色，无法删除")
 		return
 	}
 
 	// 删除角色
-	err = global.JY_DB.Where("authority_id = ?", auth.AuthorityId).Delete(&system.SysAuthority{}).Error
+	err = global.JY_DB.WithContext(c).Where("authority_id = ?", auth.AuthorityId).Delete(&system.SysAuthority{}).Error
 	if err != nil {
 		common.FailWithMsg(c, "删除角色失败")
 		return
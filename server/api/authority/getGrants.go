@@ -0,0 +1,66 @@
+package authority
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+)
+
+type SearchGrant struct {
+	Page        int    `json:"page" form:"page"`
+	PageSize    int    `json:"pageSize" form:"pageSize"`
+	UserId      uint   `json:"userId" form:"userId"`           // 被授权用户ID
+	AuthorityId string `json:"authorityId" form:"authorityId"` // 角色ID
+	Status      string `json:"status" form:"status"`           // 状态 active, expired, revoked
+}
+
+// GetGrantList 获取临时角色授权列表
+// @Summary      获取临时角色授权列表
+// @Description  分页获取临时角色授权列表，可按用户、角色、状态筛选
+// @Security     ApiKeyAuth
+// @Tags         Authority
+// @Produce      json
+// @Param        data  query     SearchGrant  true  "页码, 每页大小, 用户ID, 角色ID, 状态"
+// @Success      200   {object}  common.Response{data=common.PageResult,msg=string}  "获取成功"
+// @Router       /authority/grant/list [get]
+func (a *Api) GetGrantList(c *gin.Context) {
+	var search SearchGrant
+	_ = c.ShouldBindQuery(&search)
+	if search.Page == 0 {
+		search.Page = 1
+	}
+	if search.PageSize == 0 {
+		search.PageSize = 10
+	}
+
+	db := global.JY_DB.WithContext(c).Model(&system.SysAuthorityGrant{})
+	if search.UserId != 0 {
+		db = db.Where("user_id = ?", search.UserId)
+	}
+	if search.AuthorityId != "" {
+		db = db.Where("authority_id = ?", search.AuthorityId)
+	}
+	if search.Status != "" {
+		db = db.Where("status = ?", search.Status)
+	}
+
+	var grants []system.SysAuthorityGrant
+	var total int64
+	err := db.Count(&total).Error
+	if err != nil {
+		common.FailWithMsg(c, "统计失败")
+		return
+	}
+	err = db.Order("id DESC").Limit(search.PageSize).Offset((search.Page - 1) * search.PageSize).Find(&grants).Error
+	if err != nil {
+		common.FailWithMsg(c, "获取列表失败")
+		return
+	}
+	common.OkWithData(c, common.PageResult{
+		List:     grants,
+		Total:    total,
+		Page:     search.Page,
+		PageSize: search.PageSize,
+	})
+}
@@ -42,12 +42,27 @@ func (a *Api) GetAuthorityMenus(c *gin.Context) {
 	}
 
 	// 获取菜单权限（树状结构），如果角色被禁用则返回空菜单
-	treeMenus := a.getMenusByAuthorityId(authorityId, true)
+	// 菜单标题优先使用用户偏好设置中的语言，未设置时按请求头 Accept-Language 返回对应语言
+	locale := utils.UserLocale(waitClaims.ID)
+	if locale == "" {
+		locale = utils.RequestLocale(c)
+	}
+	treeMenus := a.getMenusByAuthorityId(authorityId, true, locale)
 	if treeMenus == nil {
 		common.FailWithMsg(c, "获取菜单权限失败")
 		return
 	}
 
+	// 合并临时授权角色的菜单
+	grants := utils.ActiveGrants(waitClaims.ID)
+	if len(grants) > 0 {
+		authorityIds := []string{authorityId}
+		for _, grant := range grants {
+			authorityIds = append(authorityIds, grant.AuthorityId)
+		}
+		treeMenus = a.getMenusByAuthorityIds(authorityIds, true, locale)
+	}
+
 	common.OkWithData(c, treeMenus)
 }
 
@@ -68,7 +83,7 @@ func (a *Api) GetAuthorityMenusByRole(c *gin.Context) {
 	}
 
 	// 获取菜单权限（树状结构），不判断角色是否禁用
-	treeMenus := a.getMenusByAuthorityId(authorityId, false)
+	treeMenus := a.getMenusByAuthorityId(authorityId, false, "")
 	if treeMenus == nil {
 		common.FailWithMsg(c, "获取菜单权限失败")
 		return
@@ -79,24 +94,44 @@ func (a *Api) GetAuthorityMenusByRole(c *gin.Context) {
 
 // getMenusByAuthorityId 根据角色ID获取菜单权限（内部方法）
 // checkRoleEnable: true-检查角色状态（如果角色被禁用返回空菜单），false-不检查角色状态（用于角色管理页面）
-func (a *Api) getMenusByAuthorityId(authorityId string, checkRoleEnable bool) []MenuTreeItem {
-	// 查找角色
-	var authority system.SysAuthority
-	err := global.JY_DB.Where("authority_id = ?", authorityId).First(&authority).Error
-	if err != nil {
-		return nil
-	}
-
-	// 如果需要检查角色状态，且角色被禁用，返回空菜单
-	if checkRoleEnable && !authority.Enable {
-		return []MenuTreeItem{}
-	}
+// locale: 菜单标题的语言，为空时返回默认标题
+func (a *Api) getMenusByAuthorityId(authorityId string, checkRoleEnable bool, locale string) []MenuTreeItem {
+	return a.getMenusByAuthorityIds([]string{authorityId}, checkRoleEnable, locale)
+}
 
-	// 获取角色的菜单
+// getMenusByAuthorityIds 获取多个角色菜单权限的并集（内部方法）
+// 第一个角色不存在时返回nil，其余角色（如临时授权角色）不存在或被禁用时忽略
+func (a *Api) getMenusByAuthorityIds(authorityIds []string, checkRoleEnable bool, locale string) []MenuTreeItem {
 	var menus []system.SysBaseMenu
-	err = global.JY_DB.Model(&authority).Association("SysBaseMenus").Find(&menus)
-	if err != nil {
-		return nil
+	menuIds := make(map[uint]bool)
+	for i, authorityId := range authorityIds {
+		// 查找角色
+		var authority system.SysAuthority
+		err := global.JY_DB.Where("authority_id = ?", authorityId).First(&authority).Error
+		if err != nil {
+			if i == 0 {
+				return nil
+			}
+			continue
+		}
+
+		// 如果需要检查角色状态，且角色被禁用，跳过该角色的菜单
+		if checkRoleEnable && !authority.Enable {
+			continue
+		}
+
+		// 获取角色的菜单
+		var authorityMenus []system.SysBaseMenu
+		err = global.JY_DB.Model(&authority).Association("SysBaseMenus").Find(&authorityMenus)
+		if err != nil {
+			return nil
+		}
+		for _, menu := range authorityMenus {
+			if !menuIds[menu.ID] {
+				menuIds[menu.ID] = true
+				menus = append(menus, menu)
+			}
+		}
 	}
 
 	// 如果没有菜单数据，返回空数组（不是nil）
@@ -117,22 +152,36 @@ func (a *Api) getMenusByAuthorityId(authorityId string, checkRoleEnable bool) []
 		return []MenuTreeItem{}
 	}
 
+	// 加载多语言标题和路由参数，并按语言替换标题
+	enabledIds := make([]uint, 0, len(enabledMenus))
+	for _, menu := range enabledMenus {
+		enabledIds = append(enabledIds, menu.ID)
+	}
+	if err := global.JY_DB.Preload("I18n").Preload("Parameters").Where("id IN ?", enabledIds).Find(&enabledMenus).Error; err != nil {
+		return nil
+	}
+	for i := range enabledMenus {
+		enabledMenus[i].Title = utils.LocalizeMenuTitle(enabledMenus[i], locale)
+	}
+
 	// 按排序字段排序（使用更高效的排序方式）
 	sort.Slice(enabledMenus, func(i, j int) bool {
 		return enabledMenus[i].Sort < enabledMenus[j].Sort
 	})
 
 	// 构建树形结构
-	return buildMenuTree(enabledMenus, "0")
+	return buildMenuTree(enabledMenus, "0", map[uint]bool{})
 }
 
 // buildMenuTree 构建菜单树（只包含启用的菜单）
-func buildMenuTree(menus []system.SysBaseMenu, parentId string) []MenuTreeItem {
+// visited 记录已加入树中的菜单，防止脏数据中的循环引用导致无限递归
+func buildMenuTree(menus []system.SysBaseMenu, parentId string, visited map[uint]bool) []MenuTreeItem {
 	var tree []MenuTreeItem
 	for _, menu := range menus {
 		// 只处理启用的菜单
-		if menu.ParentId == parentId && menu.Enable {
-			children := buildMenuTree(menus, fmt.Sprintf("%d", menu.ID))
+		if menu.ParentId == parentId && menu.Enable && !visited[menu.ID] {
+			visited[menu.ID] = true
+			children := buildMenuTree(menus, fmt.Sprintf("%d", menu.ID), visited)
 			tree = append(tree, MenuTreeItem{
 				SysBaseMenu: menu,
 				Children:    children,
@@ -0,0 +1,19 @@
+package authority
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+)
+
+// GetPermissions 获取所有操作权限
+// @Summary      获取所有操作权限
+// @Description  获取可以授予角色的所有操作权限，用于角色的操作权限设置
+// @Security     ApiKeyAuth
+// @Tags         Authority
+// @Produce      json
+// @Success      200  {object}  common.Response{data=[]system.PermissionDefinition,msg=string}  "获取成功"
+// @Router       /authority/permissions [get]
+func (a *Api) GetPermissions(c *gin.Context) {
+	common.OkWithDetailed(c, system.PermissionDefinitions, "获取成功")
+}
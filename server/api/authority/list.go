@@ -17,7 +17,7 @@ import (
 // @Router       /authority/list [get]
 func (a *Api) GetAuthorityList(c *gin.Context) {
 	var auths []system.SysAuthority
-	err := global.JY_DB.Find(&auths).Error
+	err := global.JY_DB.WithContext(c).Find(&auths).Error
 	if err != nil {
 		common.FailWithMsg(c, "获取列表失败")
 		return
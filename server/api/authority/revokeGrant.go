@@ -0,0 +1,72 @@
+package authority
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+type RevokeGrantRequest struct {
+	ID uint `json:"id" binding:"required"` // 授权ID
+}
+
+// RevokeGrant 撤销临时角色授权
+// @Summary      撤销临时角色授权
+// @Description  提前收回尚未到期的临时角色授权，仅超级管理员可以操作
+// @Security     ApiKeyAuth
+// @Tags         Authority
+// @Accept       json
+// @Produce      json
+// @Param        data  body      RevokeGrantRequest  true  "授权ID"
+// @Success      200   {object}  common.Response{msg=string}  "撤销成功"
+// @Router       /authority/grant/revoke [post]
+func (a *Api) RevokeGrant(c *gin.Context) {
+	if !utils.CheckSuperAdmin(c) {
+		return
+	}
+
+	var req RevokeGrantRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		common.FailWithMsg(c, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	var grant system.SysAuthorityGrant
+	err = global.JY_DB.WithContext(c).Where("id = ?", req.ID).First(&grant).Error
+	if err != nil {
+		common.FailWithMsg(c, "授权不存在")
+		return
+	}
+	if grant.Status != system.GrantStatusActive {
+		common.FailWithMsg(c, "该授权已失效")
+		return
+	}
+
+	now := time.Now()
+	err = global.JY_DB.WithContext(c).Model(&grant).Updates(map[string]interface{}{
+		"status":     system.GrantStatusRevoked,
+		"revoked_at": now,
+		"revoked_by": waitClaims.ID,
+	}).Error
+	if err != nil {
+		common.FailWithMsg(c, "撤销授权失败")
+		return
+	}
+
+	grant.Status, grant.RevokedAt, grant.RevokedBy = system.GrantStatusRevoked, &now, waitClaims.ID
+	utils.NotifyGrant(grant, utils.GrantEventRevoked)
+
+	common.OkWithMsg(c, "撤销成功")
+}
@@ -0,0 +1,97 @@
+package authority
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+type SetDataScopeRequest struct {
+	AuthorityId string `json:"authorityId" binding:"required"` // 角色ID
+	DataScope   string `json:"dataScope" binding:"required"`   // 数据范围 all, dept, deptAndChild, self, custom
+	DeptIds     []uint `json:"deptIds"`                        // 自定义数据范围的部门ID，仅 custom 时生效，必须都属于当前租户
+}
+
+// SetDataScope 设置角色的数据范围
+// @Summary      设置角色的数据范围
+// @Description  设置角色的数据范围：全部、本部门、本部门及以下、仅本人、自定义部门，仅超级管理员可以操作
+// @Security     ApiKeyAuth
+// @Tags         Authority
+// @Accept       json
+// @Produce      json
+// @Param        data  body      SetDataScopeRequest  true  "角色ID, 数据范围, 部门ID列表"
+// @Success      200   {object}  common.Response{msg=string}  "设置成功"
+// @Router       /authority/setDataScope [post]
+func (a *Api) SetDataScope(c *gin.Context) {
+	if !utils.CheckSuperAdmin(c) {
+		return
+	}
+
+	var req SetDataScopeRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+
+	switch req.DataScope {
+	case system.DataScopeAll, system.DataScopeDept, system.DataScopeDeptAndChild, system.DataScopeSelf, system.DataScopeCustom:
+	default:
+		common.FailWithMsg(c, "不支持的数据范围")
+		return
+	}
+
+	var authority system.SysAuthority
+	err = global.JY_DB.WithContext(c).Where("authority_id = ?", req.AuthorityId).First(&authority).Error
+	if err != nil {
+		common.FailWithMsg(c, "角色不存在")
+		return
+	}
+
+	var depts []system.SysDept
+	if req.DataScope == system.DataScopeCustom && len(req.DeptIds) > 0 {
+		err = global.JY_DB.WithContext(c).Where("id IN ?", req.DeptIds).Find(&depts).Error
+		if err != nil {
+			common.FailWithMsg(c, "查找部门失败")
+			return
+		}
+		// 不存在或不属于当前租户的部门不会被查出，直接报错而不是静默丢弃
+		deptIds := slices.Clone(req.DeptIds)
+		slices.Sort(deptIds)
+		deptIds = slices.Compact(deptIds)
+		if len(depts) < len(deptIds) {
+			found := make(map[uint]bool, len(depts))
+			for _, dept := range depts {
+				found[dept.ID] = true
+			}
+			var missing []string
+			for _, id := range deptIds {
+				if !found[id] {
+					missing = append(missing, strconv.FormatUint(uint64(id), 10))
+				}
+			}
+			common.FailWithMsg(c, "部门不存在: "+strings.Join(missing, ", "))
+			return
+		}
+	}
+
+	// 数据范围和自定义部门同时修改，避免只修改了其中一项
+	err = global.JY_DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&system.SysAuthority{}).Where("authority_id = ?", req.AuthorityId).Update("data_scope", req.DataScope).Error; err != nil {
+			return err
+		}
+		return tx.Model(&authority).Association("DataScopeDepts").Replace(depts)
+	})
+	if err != nil {
+		common.FailWithMsg(c, "设置数据范围失败")
+		return
+	}
+	common.OkWithMsg(c, "设置成功")
+}
@@ -32,7 +32,7 @@ func (a *Api) SetAuthorityMenus(c *gin.Context) {
 
 	// 查找角色
 	var authority system.SysAuthority
-	err = global.JY_DB.Where("authority_id = ?", req.AuthorityId).First(&authority).Error
+	err = global.JY_DB.WithContext(c).Where("authority_id = ?", req.AuthorityId).First(&authority).Error
 	if err != nil {
 		common.FailWithMsg(c, "角色不存在")
 		return
@@ -41,7 +41,7 @@ func (a *Api) SetAuthorityMenus(c *gin.Context) {
 	// 查找菜单
 	var menus []system.SysBaseMenu
 	if len(req.MenuIds) > 0 {
-		err = global.JY_DB.Where("id IN ?", req.MenuIds).Find(&menus).Error
+		err = global.JY_DB.WithContext(c).Where("id IN ?", req.MenuIds).Find(&menus).Error
 		if err != nil {
 			common.FailWithMsg(c, "查找菜单失败")
 			return
@@ -49,7 +49,7 @@ func (a *Api) SetAuthorityMenus(c *gin.Context) {
 	}
 
 	// 替换角色的菜单关联
-	err = global.JY_DB.Model(&authority).Association("SysBaseMenus").Replace(menus)
+	err = global.JY_DB.WithContext(c).Model(&authority).Association("SysBaseMenus").Replace(menus)
 	if err != nil {
 		common.FailWithMsg(c, "设置菜单权限失败")
 		return
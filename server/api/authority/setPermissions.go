@@ -0,0 +1,61 @@
+package authority
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+type SetPermissionsRequest struct {
+	AuthorityId string   `json:"authorityId" binding:"required"` // 角色ID
+	Permissions []string `json:"permissions"`                    // 操作权限标识，整体替换，见操作权限列表接口
+}
+
+// SetPermissions 设置角色的操作权限
+// @Summary      设置角色的操作权限
+// @Description  设置角色的操作权限（如查看客户完整电话），整体替换角色原有的操作权限。超级管理员拥有所有操作权限，临时授权的角色的操作权限同样生效。仅超级管理员可以操作，不能修改自己所属角色的操作权限
+// @Security     ApiKeyAuth
+// @Tags         Authority
+// @Accept       json
+// @Produce      json
+// @Param        data  body      SetPermissionsRequest  true  "角色ID, 操作权限标识"
+// @Success      200   {object}  common.Response{msg=string}  "设置成功"
+// @Router       /authority/setPermissions [post]
+func (a *Api) SetPermissions(c *gin.Context) {
+	if !utils.CheckSuperAdmin(c) {
+		return
+	}
+
+	var req SetPermissionsRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+	claims, _ := c.Get("claims")
+	if req.AuthorityId == claims.(*utils.CustomClaims).AuthorityId {
+		common.FailWithMsg(c, "不能修改自己所属角色的操作权限")
+		return
+	}
+	permissions, err := utils.NormalizePermissions(req.Permissions)
+	if err != nil {
+		common.FailWithMsg(c, err.Error())
+		return
+	}
+
+	var authority system.SysAuthority
+	err = global.JY_DB.WithContext(c).Where("authority_id = ?", req.AuthorityId).First(&authority).Error
+	if err != nil {
+		common.FailWithMsg(c, "角色不存在")
+		return
+	}
+	err = global.JY_DB.WithContext(c).Model(&system.SysAuthority{}).Where("authority_id = ?", req.AuthorityId).
+		Select("permissions").Updates(system.SysAuthority{Permissions: permissions}).Error
+	if err != nil {
+		common.FailWithMsg(c, "设置操作权限失败")
+		return
+	}
+	common.OkWithMsg(c, "设置成功")
+}
@@ -31,7 +31,7 @@ func (a *Api) UpdateAuthority(c *gin.Context) {
 		"default_router": auth.DefaultRouter,
 		"enable":         auth.Enable,
 	}
-	err = global.JY_DB.Model(&system.SysAuthority{}).Where("authority_id = ?", auth.AuthorityId).Updates(updateData).Error
+	err = global.JY_DB.WithContext(c).Model(&system.SysAuthority{}).Where("authority_id = ?", auth.AuthorityId).Updates(updateData).Error
 	if err != nil {
 		common.FailWithMsg(c, "更新角色失败")
 		return
@@ -0,0 +1,122 @@
+package customer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+type CreateActivityRequest struct {
+	CustomerID uint       `json:"customerId" binding:"required"` // 客户ID
+	Type       string     `json:"type" binding:"required"`       // 类型 call, visit, email, note
+	Content    string     `json:"content" binding:"required"`    // 内容
+	OccurredAt *time.Time `json:"occurredAt"`                    // 发生时间，不填时为当前时间
+	FileIds    []uint     `json:"fileIds"`                       // 附件ID，需先通过上传接口上传
+}
+
+// CreateActivity 添加客户跟进记录
+// @Summary      添加客户跟进记录
+// @Description  为客户添加电话、拜访、邮件、备注类型的跟进记录，可关联已上传的文件作为附件。状态变更等记录由系统自动添加，不能手动创建
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Accept       json
+// @Produce      json
+// @Param        data  body      CreateActivityRequest  true  "客户ID, 类型, 内容, 发生时间, 附件ID"
+// @Success      200   {object}  common.Response{data=business.CustomerActivity,msg=string}  "添加成功"
+// @Router       /customer/activity [post]
+func (c *Api) CreateActivity(ctx *gin.Context) {
+	var req CreateActivityRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	if !utils.ManualActivityTypes[req.Type] {
+		common.FailWithMsg(ctx, "跟进类型只能是 call, visit, email, note")
+		return
+	}
+	req.Content = strings.TrimSpace(req.Content)
+	if req.Content == "" {
+		common.FailWithMsg(ctx, "跟进内容不能为空")
+		return
+	}
+	occurredAt := time.Now()
+	if req.OccurredAt != nil {
+		if req.OccurredAt.After(occurredAt) {
+			common.FailWithMsg(ctx, "发生时间不能晚于当前时间")
+			return
+		}
+		occurredAt = *req.OccurredAt
+	}
+
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	// 只能为数据范围内的客户添加跟进记录
+	var customer business.Customer
+	err := global.JY_DB.WithContext(ctx).Scopes(utils.DataScope(ctx, "owner_id")).Where("id = ?", req.CustomerID).First(&customer).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "客户不存在")
+		return
+	}
+
+	// 附件只能使用数据范围内的文件
+	var files []system.ExaFileUploadAndDownload
+	if len(req.FileIds) > 0 {
+		err = global.JY_DB.WithContext(ctx).Scopes(utils.DataScope(ctx, "created_by")).Where("id IN ?", req.FileIds).Find(&files).Error
+		if err != nil {
+			common.FailWithMsg(ctx, "查询附件失败")
+			return
+		}
+		found := make(map[uint]bool, len(files))
+		for _, file := range files {
+			found[file.ID] = true
+		}
+		for _, id := range req.FileIds {
+			if !found[id] {
+				common.FailWithMsg(ctx, fmt.Sprintf("附件 %d 不存在", id))
+				return
+			}
+		}
+	}
+
+	activity := business.CustomerActivity{
+		TenantID:   customer.TenantID,
+		CustomerID: customer.ID,
+		Type:       req.Type,
+		Content:    req.Content,
+		OccurredAt: occurredAt,
+		CreatedBy:  waitClaims.ID,
+		Files:      files,
+	}
+	// 附件已存在，只写入关联关系
+	err = global.JY_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Files.*").Create(&activity).Error; err != nil {
+			return err
+		}
+		// 补录较早的跟进记录时不回退最近跟进时间
+		return tx.Model(&business.Customer{}).
+			Where("id = ? AND (last_followed_at IS NULL OR last_followed_at < ?)", customer.ID, occurredAt).
+			Update("last_followed_at", occurredAt).Error
+	})
+	if err != nil {
+		common.FailWithMsg(ctx, "添加失败")
+		return
+	}
+
+	activities := []business.CustomerActivity{activity}
+	utils.FillActivityCreators(global.JY_DB, activities)
+	utils.Mask(ctx, activities)
+	common.OkWithDetailed(ctx, activities[0], "添加成功")
+}
@@ -0,0 +1,84 @@
+package customer
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type ActivityListRequest struct {
+	Page       int        `json:"page" form:"page"`
+	PageSize   int        `json:"pageSize" form:"pageSize"`
+	CustomerID uint       `json:"customerId" form:"customerId"`                                 // 客户ID
+	Type       string     `json:"type" form:"type"`                                             // 类型
+	CreatedBy  uint       `json:"createdBy" form:"createdBy"`                                   // 记录人ID
+	StartTime  *time.Time `json:"startTime" form:"startTime" time_format:"2006-01-02 15:04:05"` // 发生时间起
+	EndTime    *time.Time `json:"endTime" form:"endTime" time_format:"2006-01-02 15:04:05"`     // 发生时间止
+}
+
+// GetActivityList 获取客户跟进记录列表
+// @Summary      分页获取客户跟进记录
+// @Description  分页获取跟进记录，按发生时间倒序，只返回当前角色数据范围内客户的记录。可按客户、类型、记录人、发生时间筛选
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        data  query     ActivityListRequest  true  "页码, 每页大小, 客户ID, 类型, 记录人ID, 发生时间范围"
+// @Success      200   {object}  common.Response{data=common.PageResult{list=[]business.CustomerActivity},msg=string}  "查询成功"
+// @Router       /customer/activity/list [get]
+func (c *Api) GetActivityList(ctx *gin.Context) {
+	var params ActivityListRequest
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PageSize <= 0 {
+		params.PageSize = 10
+	}
+
+	customerIds := global.JY_DB.WithContext(ctx).Model(&business.Customer{}).Select("id").Scopes(utils.DataScope(ctx, "owner_id"))
+	query := global.JY_DB.WithContext(ctx).Model(&business.CustomerActivity{}).Where("customer_id IN (?)", customerIds)
+	if params.CustomerID != 0 {
+		query = query.Where("customer_id = ?", params.CustomerID)
+	}
+	if params.Type != "" {
+		query = query.Where(&business.CustomerActivity{Type: params.Type})
+	}
+	if params.CreatedBy != 0 {
+		query = query.Where("created_by = ?", params.CreatedBy)
+	}
+	if params.StartTime != nil {
+		query = query.Where("occurred_at >= ?", *params.StartTime)
+	}
+	if params.EndTime != nil {
+		query = query.Where("occurred_at <= ?", *params.EndTime)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+	var activities []business.CustomerActivity
+	err := query.Preload("Files").Order("occurred_at DESC, id DESC").
+		Limit(params.PageSize).Offset((params.Page - 1) * params.PageSize).Find(&activities).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+	utils.FillActivityCreators(global.JY_DB, activities)
+	utils.Mask(ctx, activities)
+
+	common.OkWithDetailed(ctx, common.PageResult{
+		List:     activities,
+		Total:    count,
+		Page:     params.Page,
+		PageSize: params.PageSize,
+	}, "查询成功")
+}
@@ -0,0 +1,48 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type ChangeOwnerRequest struct {
+	CustomerIds []uint `json:"customerIds" binding:"required,min=1,max=500"` // 客户ID
+	OwnerID     uint   `json:"ownerId" binding:"required"`                   // 新负责人ID
+	Reason      string `json:"reason"`                                       // 原因，记录到客户时间线
+}
+
+// AssignCustomer 分配公海客户
+// @Summary      分配公海客户
+// @Description  将公海中的客户分配给指定负责人，支持批量。每个客户单独处理，返回成功数和失败的客户；负责人负责的客户数受公海规则限制
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Accept       json
+// @Produce      json
+// @Param        data  body      ChangeOwnerRequest  true  "客户ID, 负责人ID, 原因"
+// @Success      200   {object}  common.Response{data=utils.CustomerBatchResult,msg=string}  "分配完成"
+// @Router       /customer/assign [post]
+func (c *Api) AssignCustomer(ctx *gin.Context) {
+	var req ChangeOwnerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	var customers []business.Customer
+	err := global.JY_DB.WithContext(ctx).Where("id IN ? AND owner_id = 0", req.CustomerIds).Find(&customers).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "查询客户失败")
+		return
+	}
+	result := utils.ChangeCustomerOwners(global.JY_DB.WithContext(ctx), customers, req.CustomerIds, req.OwnerID, req.Reason, waitClaims.ID, "客户不在公海中")
+	common.OkWithDetailed(ctx, result, "分配完成")
+}
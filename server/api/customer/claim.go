@@ -0,0 +1,58 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type ClaimCustomerRequest struct {
+	CustomerIds []uint `json:"customerIds" binding:"required,min=1,max=500"` // 客户ID
+}
+
+// ClaimCustomer 领取公海客户
+// @Summary      领取公海客户
+// @Description  从公海领取客户，由自己负责，支持批量。每天领取数和负责的客户数受租户公海规则限制，超过每天领取上限时整批拒绝；
+// @Description  其余情况每个客户单独处理，返回成功数和失败的客户（如已被他人领取）
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Accept       json
+// @Produce      json
+// @Param        data  body      ClaimCustomerRequest  true  "客户ID"
+// @Success      200   {object}  common.Response{data=utils.CustomerBatchResult,msg=string}  "领取完成"
+// @Router       /customer/claim [post]
+func (c *Api) ClaimCustomer(ctx *gin.Context) {
+	var req ClaimCustomerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	setting, err := utils.GetCustomerPoolSetting(global.JY_DB, waitClaims.TenantId)
+	if err != nil {
+		common.FailWithMsg(ctx, "获取公海规则失败")
+		return
+	}
+	if err = utils.CheckDailyClaimLimit(global.JY_DB, setting, waitClaims.ID, len(req.CustomerIds)); err != nil {
+		common.FailWithMsg(ctx, err.Error())
+		return
+	}
+
+	// 只能领取本租户公海中的客户，超级管理员也不跨租户领取
+	var customers []business.Customer
+	err = global.JY_DB.WithContext(ctx).Where("id IN ? AND owner_id = 0 AND tenant_id = ?", req.CustomerIds, waitClaims.TenantId).Find(&customers).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "查询客户失败")
+		return
+	}
+	result := utils.ChangeCustomerOwners(global.JY_DB.WithContext(ctx), customers, req.CustomerIds, waitClaims.ID, "", waitClaims.ID, "客户不在公海中")
+	common.OkWithDetailed(ctx, result, "领取完成")
+}
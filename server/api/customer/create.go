@@ -1,26 +1,40 @@
 package customer
 
 import (
+	"encoding/json"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"jiangyi.com/global"
 	"jiangyi.com/model/business"
 	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
 )
 
 type CreateCustomerRequest struct {
 	CustomerName   string `json:"customerName" binding:"required"`
 	CustomerPhone  string `json:"customerPhone" binding:"required"`
-	CustomerStatus string `json:"customerStatus"`
+	CustomerStatus string `json:"customerStatus"` // 可不填，新建客户总是处于流程的初始状态
+
+	CustomFields map[string]json.RawMessage `json:"customFields"` // 自定义字段值，字段标识 -> 值
+	Contacts     []business.CustomerContact `json:"contacts"`     // 联系人，未标记主联系人时第一个为主联系人
+	Addresses    []business.CustomerAddress `json:"addresses"`    // 地址，未标记默认地址时第一个为默认地址
+	Tags         []string                   `json:"tags"`         // 标签
+
+	IgnoreDuplicates bool `json:"ignoreDuplicates"` // 确认不是重复客户，存在疑似重复的客户时仍然创建
 }
 
 // CreateCustomer 创建客户
 // @Summary      创建客户
-// @Description  创建客户
+// @Description  创建客户，可同时创建联系人、地址和标签，自定义字段按管理员定义的类型、必填、唯一和校验规则校验。电话统一保存为 E.164 格式。
+// @Description  租户内存在名称相同或电话相同（包括联系人电话）的客户时返回失败，data 为疑似重复的客户，确认不是重复客户时传 ignoreDuplicates 重新提交
 // @Security     ApiKeyAuth
 // @Tags         Customer
 // @Accept       json
 // @Produce      json
-// @Param        data  body      CreateCustomerRequest  true  "客户姓名, 客户电话"
+// @Param        data  body      CreateCustomerRequest  true  "客户姓名, 客户电话, 自定义字段, 联系人, 地址, 标签, 忽略疑似重复"
 // @Success      200   {object}  common.Response{data=business.Customer,msg=string}  "创建成功"
 // @Router       /customer [post]
 func (c *Api) CreateCustomer(ctx *gin.Context) {
@@ -30,16 +44,90 @@ func (c *Api) CreateCustomer(ctx *gin.Context) {
 		return
 	}
 
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	phone, err := utils.NormalizePhone(req.CustomerPhone)
+	if err != nil {
+		common.FailWithMsg(ctx, "客户"+err.Error())
+		return
+	}
+	req.CustomerPhone = phone
+	tags, err := utils.NormalizeCustomerTags(req.Tags)
+	if err != nil {
+		common.FailWithMsg(ctx, err.Error())
+		return
+	}
+	if !req.IgnoreDuplicates {
+		duplicates, err := utils.FindCustomerDuplicates(global.JY_DB.WithContext(ctx), waitClaims.TenantId, 0, req.CustomerName, utils.CustomerPhones(req.CustomerPhone, req.Contacts))
+		if err != nil {
+			common.FailWithMsg(ctx, "查重失败")
+			return
+		}
+		if len(duplicates) > 0 {
+			utils.Mask(ctx, duplicates)
+			common.FailWithDetailed(ctx, duplicates, "存在疑似重复的客户")
+			return
+		}
+	}
+
+	// 新建客户进入流程的初始状态
+	workflow, err := utils.GetCustomerWorkflow(global.JY_DB, waitClaims.TenantId)
+	if err != nil {
+		common.FailWithMsg(ctx, "获取客户状态流程失败")
+		return
+	}
+	initial := utils.WorkflowInitialStatus(workflow)
+	if req.CustomerStatus != "" && req.CustomerStatus != initial.Code {
+		common.FailWithMsg(ctx, "新建客户的状态只能是"+initial.Name)
+		return
+	}
+
+	// 新建的客户由创建人负责
+	now := time.Now()
 	customer := business.Customer{
-		CustomerName:   req.CustomerName,
+		CustomerName:   strings.TrimSpace(req.CustomerName),
 		CustomerPhone:  req.CustomerPhone,
-		CustomerStatus: req.CustomerStatus,
+		CustomerStatus: initial.Code,
+		CreatedBy:      waitClaims.ID,
+		OwnerID:        waitClaims.ID,
+		OwnedAt:        &now,
+		Tags:           tags,
 	}
 
-	if err := global.JY_DB.Create(&customer).Error; err != nil {
+	// saveErr 为自定义字段、联系人、地址的校验错误，直接返回给前端
+	var saveErr error
+	err = global.JY_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&customer).Error; err != nil {
+			return err
+		}
+		if err := utils.StartCustomerStatusHistory(tx, customer, waitClaims.ID); err != nil {
+			return err
+		}
+		if saveErr = utils.SaveCustomerFieldValues(tx, customer, req.CustomFields, true); saveErr != nil {
+			return saveErr
+		}
+		if saveErr = utils.SaveCustomerContacts(tx, customer, req.Contacts); saveErr != nil {
+			return saveErr
+		}
+		saveErr = utils.SaveCustomerAddresses(tx, customer, req.Addresses)
+		return saveErr
+	})
+	if saveErr != nil {
+		common.FailWithMsg(ctx, saveErr.Error())
+		return
+	}
+	if err != nil {
 		common.FailWithMsg(ctx, "创建失败")
 		return
 	}
 
+	customer, _ = loadCustomerDetail(global.JY_DB.WithContext(ctx), customer.ID)
+
+	utils.Mask(ctx, &customer)
 	common.OkWithDetailed(ctx, customer, "创建成功")
 }
@@ -6,6 +6,7 @@ import (
 	"jiangyi.com/global"
 	"jiangyi.com/model/business"
 	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
 )
 
 type DeleteCustomerRequest struct {
@@ -30,8 +31,8 @@ func (c *Api) DeleteCustomer(ctx *gin.Context) {
 	}
 
 	var customer business.Customer
-	// 先查询客户是否存在
-	if err := global.JY_DB.First(&customer, req.ID).Error; err != nil {
+	// 先查询客户是否存在，只能操作数据权限范围内的客户
+	if err := global.JY_DB.WithContext(ctx).Scopes(utils.DataScope(ctx, "owner_id")).Where("id = ?", req.ID).First(&customer).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			common.FailWithMsg(ctx, "客户不存在，删除失败")
 			return
@@ -41,7 +42,7 @@ func (c *Api) DeleteCustomer(ctx *gin.Context) {
 	}
 
 	// 删除客户（软删除）
-	if err := global.JY_DB.Delete(&customer).Error; err != nil {
+	if err := global.JY_DB.WithContext(ctx).Delete(&customer).Error; err != nil {
 		common.FailWithMsg(ctx, "删除失败")
 		return
 	}
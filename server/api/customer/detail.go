@@ -0,0 +1,59 @@
+package customer
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// GetCustomerDetail 获取客户详情
+// @Summary      获取客户详情
+// @Description  获取客户详情，包含自定义字段、联系人和地址，按当前角色的数据范围过滤。没有查看完整电话权限时客户电话和联系人电话脱敏显示，完整电话通过查看完整电话接口获取
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        id   path      int  true  "客户ID"
+// @Success      200  {object}  common.Response{data=business.Customer,msg=string}  "查询成功"
+// @Router       /customer/{id} [get]
+func (c *Api) GetCustomerDetail(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	if id <= 0 {
+		common.FailWithMsg(ctx, "客户ID不能为空")
+		return
+	}
+
+	query := global.JY_DB.WithContext(ctx).Scopes(utils.DataScope(ctx, "owner_id"))
+	customer, err := loadCustomerDetail(query, uint(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			common.FailWithMsg(ctx, "客户不存在")
+			return
+		}
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+	utils.Mask(ctx, &customer)
+	common.OkWithDetailed(ctx, customer, "查询成功")
+}
+
+// loadCustomerDetail 查询客户及其自定义字段、联系人（主联系人在前）和地址（默认地址在前）
+func loadCustomerDetail(query *gorm.DB, id uint) (business.Customer, error) {
+	var customer business.Customer
+	err := query.
+		Preload("Contacts", func(db *gorm.DB) *gorm.DB { return db.Order("is_primary DESC, id ASC") }).
+		Preload("Addresses", func(db *gorm.DB) *gorm.DB { return db.Order("is_primary DESC, id ASC") }).
+		Where("id = ?", id).First(&customer).Error
+	if err != nil {
+		return customer, err
+	}
+	customers := []business.Customer{customer}
+	if err = utils.FillCustomerFields(global.JY_DB, customers); err != nil {
+		return customer, err
+	}
+	return customers[0], nil
+}
@@ -0,0 +1,67 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type DuplicateListRequest struct {
+	Type     string `json:"type" form:"type" binding:"omitempty,oneof=phone name"` // 重复类型 phone-电话相同 name-名称相同，不填时都查
+	Page     int    `json:"page" form:"page"`                                      // 页码
+	PageSize int    `json:"pageSize" form:"pageSize"`                              // 每页组数
+}
+
+// GetDuplicateList 获取重复客户报表
+// @Summary      获取重复客户报表
+// @Description  在当前角色数据范围内按电话、名称查找重复的客户，每组为电话或名称相同的多个客户，按客户数倒序分页。可选其中一个客户保留，调用合并接口合并其余客户
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        data  query     DuplicateListRequest  false  "重复类型, 页码, 每页组数"
+// @Success      200   {object}  common.Response{data=common.PageResult{list=[]utils.CustomerDuplicateGroup},msg=string}  "查询成功"
+// @Router       /customer/duplicate/list [get]
+func (c *Api) GetDuplicateList(ctx *gin.Context) {
+	var params DuplicateListRequest
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PageSize <= 0 {
+		params.PageSize = 10
+	}
+
+	query := global.JY_DB.WithContext(ctx).Model(&business.Customer{}).Scopes(utils.DataScope(ctx, "owner_id"))
+	groups, err := utils.FindCustomerDuplicateGroups(query, params.Type)
+	if err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+	total := len(groups)
+	start := (params.Page - 1) * params.PageSize
+	if start > total {
+		start = total
+	}
+	end := start + params.PageSize
+	if end > total {
+		end = total
+	}
+	groups = groups[start:end]
+	if err = utils.FillCustomerDuplicateGroups(query, groups); err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+
+	utils.Mask(ctx, groups)
+	common.OkWithDetailed(ctx, common.PageResult{
+		List:     groups,
+		Total:    int64(total),
+		Page:     params.Page,
+		PageSize: params.PageSize,
+	}, "查询成功")
+}
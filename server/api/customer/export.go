@@ -0,0 +1,127 @@
+package customer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// ExportCustomers 导出客户
+// @Summary      导出客户
+// @Description  按客户列表的筛选条件（含数据范围、自定义字段筛选 cf[字段标识] 和视图 viewId）导出客户，按 ID 排序，支持 CSV 和 XLSX。
+// @Description  columns 为逗号分隔的列标识，决定导出的列和顺序，不传时导出全部列，可导出的列见导入列接口。导出的文件修改后可以直接导入。没有查看完整电话权限时导出脱敏后的电话
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      octet-stream
+// @Param        format   query     string               false  "导出格式: csv(默认), xlsx"
+// @Param        columns  query     string               false  "导出的列标识, 逗号分隔"
+// @Param        data     query     CustomerListRequest  false  "关键字, 负责人ID, 视图ID"
+// @Success      200      {file}    file                 "客户文件"
+// @Router       /customer/export [get]
+func (c *Api) ExportCustomers(ctx *gin.Context) {
+	var params CustomerListRequest
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	format := ctx.DefaultQuery("format", utils.SheetCSV)
+	if format != utils.SheetCSV && format != utils.SheetXLSX {
+		common.FailWithMsg(ctx, "不支持的格式: "+format)
+		return
+	}
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+
+	columns, _, err := utils.CustomerSheetColumns(global.JY_DB, claims.(*utils.CustomClaims).TenantId)
+	if err != nil {
+		common.FailWithMsg(ctx, "获取导出列失败")
+		return
+	}
+	var keys, header []string
+	if raw := ctx.Query("columns"); raw != "" {
+		for _, key := range strings.Split(raw, ",") {
+			column, ok := findSheetColumn(columns, strings.TrimSpace(key))
+			if !ok || !column.Export {
+				common.FailWithMsg(ctx, key+" 不是可以导出的列")
+				return
+			}
+			if utils.ContainsString(keys, column.Key) {
+				continue
+			}
+			keys = append(keys, column.Key)
+			header = append(header, column.Name)
+		}
+	} else {
+		for _, column := range columns {
+			if column.Export {
+				keys = append(keys, column.Key)
+				header = append(header, column.Name)
+			}
+		}
+	}
+
+	query, err := customerListQuery(ctx, params, false)
+	if err != nil {
+		common.FailWithMsg(ctx, err.Error())
+		return
+	}
+
+	fileName := fmt.Sprintf("customers-%s.%s", time.Now().Format("20060102150405"), format)
+	ctx.Header("Content-Disposition", "attachment; filename="+fileName)
+	ctx.Header("Content-Type", utils.SheetContentType(format))
+	writer, err := utils.NewSheetWriter(ctx.Writer, format)
+	if err != nil {
+		common.FailWithMsg(ctx, "导出客户失败")
+		return
+	}
+
+	// 分批读取写出，避免一次性加载全部客户
+	exporter := utils.NewCustomerSheetExporter(global.JY_DB, keys).MaskFor(ctx)
+	err = writer.WriteRow(header)
+	if err == nil {
+		var customers []business.Customer
+		err = query.Order("id ASC").FindInBatches(&customers, 500, func(tx *gorm.DB, batch int) error {
+			if err := utils.FillCustomerFields(global.JY_DB, customers); err != nil {
+				return err
+			}
+			rows, err := exporter.Rows(customers)
+			if err != nil {
+				return err
+			}
+			for _, row := range rows {
+				if err = writer.WriteRow(row); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		// 响应已经开始写出，只能记录日志
+		global.JY_LOG.Error("导出客户失败", zap.Error(err))
+	}
+}
+
+// findSheetColumn 按列标识查找导入导出列
+func findSheetColumn(columns []utils.CustomerSheetColumn, key string) (utils.CustomerSheetColumn, bool) {
+	for _, column := range columns {
+		if column.Key == key {
+			return column, true
+		}
+	}
+	return utils.CustomerSheetColumn{}, false
+}
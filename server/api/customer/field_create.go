@@ -0,0 +1,67 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type CreateFieldRequest struct {
+	TenantID uint                        `json:"tenantId"`                    // 租户ID，不填时为当前租户
+	FieldKey string                      `json:"fieldKey" binding:"required"` // 字段标识
+	Name     string                      `json:"name" binding:"required"`     // 字段名称
+	Type     string                      `json:"type" binding:"required"`     // 字段类型 text, number, date, select, multiSelect, user
+	Options  []string                    `json:"options"`                     // 可选项（单选、多选）
+	Required bool                        `json:"required"`                    // 是否必填
+	Unique   bool                        `json:"unique"`                      // 是否唯一
+	Rules    business.CustomerFieldRules `json:"rules"`                       // 校验规则
+	Remark   string                      `json:"remark"`                      // 备注
+	Sort     int                         `json:"sort"`                        // 排序标记
+}
+
+// CreateField 创建客户自定义字段
+// @Summary      创建客户自定义字段
+// @Description  为租户创建客户自定义字段，支持文本、数字、日期、单选、多选、用户六种类型，可设置必填、唯一和校验规则，仅超级管理员可用。
+// @Description  新增的必填字段只对之后创建的客户生效，已有客户在修改该字段时才会校验
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Accept       json
+// @Produce      json
+// @Param        data  body      CreateFieldRequest  true  "字段标识, 名称, 类型, 可选项, 必填, 唯一, 校验规则"
+// @Success      200   {object}  common.Response{data=business.CustomerField,msg=string}  "创建成功"
+// @Router       /customer/field [post]
+func (c *Api) CreateField(ctx *gin.Context) {
+	if !utils.CheckSuperAdmin(ctx) {
+		return
+	}
+	var req CreateFieldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+
+	field := business.CustomerField{
+		TenantID: req.TenantID,
+		FieldKey: req.FieldKey,
+		Name:     req.Name,
+		Type:     req.Type,
+		Options:  req.Options,
+		Required: req.Required,
+		Unique:   req.Unique,
+		Rules:    req.Rules,
+		Remark:   req.Remark,
+		Sort:     req.Sort,
+	}
+	if err := utils.ValidateCustomerField(field); err != nil {
+		common.FailWithMsg(ctx, err.Error())
+		return
+	}
+
+	if err := global.JY_DB.WithContext(ctx).Create(&field).Error; err != nil {
+		common.FailWithMsg(ctx, "字段标识重复")
+		return
+	}
+	common.OkWithDetailed(ctx, field, "创建成功")
+}
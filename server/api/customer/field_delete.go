@@ -0,0 +1,51 @@
+package customer
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// DeleteField 删除客户自定义字段
+// @Summary      删除客户自定义字段
+// @Description  删除客户自定义字段及所有客户保存的该字段值，仅超级管理员可用
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        id   path      int  true  "字段ID"
+// @Success      200  {object}  common.Response{msg=string}  "删除成功"
+// @Router       /customer/field/{id} [delete]
+func (c *Api) DeleteField(ctx *gin.Context) {
+	if !utils.CheckSuperAdmin(ctx) {
+		return
+	}
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	if id == 0 {
+		common.FailWithMsg(ctx, "字段ID不能为空")
+		return
+	}
+
+	var field business.CustomerField
+	if err := global.JY_DB.WithContext(ctx).Where("id = ?", id).First(&field).Error; err != nil {
+		common.FailWithMsg(ctx, "字段不存在")
+		return
+	}
+
+	// 字段标识在租户内唯一，直接物理删除以便之后重新创建同名字段
+	err := global.JY_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(&business.CustomerFieldValue{FieldID: field.ID}).Delete(&business.CustomerFieldValue{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&field).Error
+	})
+	if err != nil {
+		common.FailWithMsg(ctx, "删除失败")
+		return
+	}
+	common.OkWithMsg(ctx, "删除成功")
+}
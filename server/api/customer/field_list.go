@@ -0,0 +1,40 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+)
+
+type FieldListRequest struct {
+	TenantID uint `form:"tenantId"` // 租户ID，仅超级管理员可用于查看指定租户的字段
+}
+
+// GetFieldList 获取客户自定义字段列表
+// @Summary      获取客户自定义字段列表
+// @Description  获取当前租户的客户自定义字段定义，前端据此渲染客户表单和筛选条件
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        data  query     FieldListRequest  false  "租户ID"
+// @Success      200   {object}  common.Response{data=[]business.CustomerField,msg=string}  "获取成功"
+// @Router       /customer/field/list [get]
+func (c *Api) GetFieldList(ctx *gin.Context) {
+	var req FieldListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+
+	var fields []business.CustomerField
+	query := global.JY_DB.WithContext(ctx).Model(&business.CustomerField{})
+	if req.TenantID != 0 {
+		query = query.Where(&business.CustomerField{TenantID: req.TenantID})
+	}
+	if err := query.Order("sort ASC, id ASC").Find(&fields).Error; err != nil {
+		common.FailWithMsg(ctx, "获取列表失败")
+		return
+	}
+	common.OkWithData(ctx, fields)
+}
@@ -0,0 +1,107 @@
+package customer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type UpdateFieldRequest struct {
+	ID       uint                        `json:"ID" binding:"required"`   // 字段ID
+	Name     string                      `json:"name" binding:"required"` // 字段名称
+	Options  []string                    `json:"options"`                 // 可选项（单选、多选）
+	Required bool                        `json:"required"`                // 是否必填
+	Unique   bool                        `json:"unique"`                  // 是否唯一
+	Rules    business.CustomerFieldRules `json:"rules"`                   // 校验规则
+	Remark   string                      `json:"remark"`                  // 备注
+	Sort     int                         `json:"sort"`                    // 排序标记
+}
+
+// UpdateField 更新客户自定义字段
+// @Summary      更新客户自定义字段
+// @Description  更新客户自定义字段的名称、可选项、必填、唯一和校验规则，字段标识和类型不能修改，仅超级管理员可用。
+// @Description  已被客户使用的可选项不能删除，已有重复值时不能设置为唯一；修改校验规则不会重新校验已保存的值
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Accept       json
+// @Produce      json
+// @Param        data  body      UpdateFieldRequest  true  "字段ID, 名称, 可选项, 必填, 唯一, 校验规则"
+// @Success      200   {object}  common.Response{data=business.CustomerField,msg=string}  "更新成功"
+// @Router       /customer/field [put]
+func (c *Api) UpdateField(ctx *gin.Context) {
+	if !utils.CheckSuperAdmin(ctx) {
+		return
+	}
+	var req UpdateFieldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+
+	var field business.CustomerField
+	if err := global.JY_DB.WithContext(ctx).Where("id = ?", req.ID).First(&field).Error; err != nil {
+		common.FailWithMsg(ctx, "字段不存在")
+		return
+	}
+	oldOptions := field.Options
+	wasUnique := field.Unique
+	field.Name = req.Name
+	field.Options = req.Options
+	field.Required = req.Required
+	field.Unique = req.Unique
+	field.Rules = req.Rules
+	field.Remark = req.Remark
+	field.Sort = req.Sort
+	if err := utils.ValidateCustomerField(field); err != nil {
+		common.FailWithMsg(ctx, err.Error())
+		return
+	}
+
+	// 删除的可选项不能已被客户使用
+	for _, option := range oldOptions {
+		if utils.ContainsString(field.Options, option) {
+			continue
+		}
+		var count int64
+		global.JY_DB.Model(&business.CustomerFieldValue{}).Where(&business.CustomerFieldValue{FieldID: field.ID, Value: option}).Count(&count)
+		if count > 0 {
+			common.FailWithMsg(ctx, fmt.Sprintf("可选项 %s 已被%d个客户使用，不能删除", option, count))
+			return
+		}
+	}
+	err := global.JY_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if field.Unique && !wasUnique {
+			// 锁定字段后再检查，检查期间不会有客户保存重复的值
+			if err := utils.LockCustomerField(tx, field.ID); err != nil {
+				return err
+			}
+			duplicate, err := utils.CheckCustomerFieldUnique(tx, field.ID)
+			if err != nil {
+				return errors.New("检查字段唯一性失败")
+			}
+			if duplicate != "" {
+				return fmt.Errorf("已有多个客户的%s为 %s，不能设置为唯一", field.Name, duplicate)
+			}
+		}
+
+		// 使用 Select 保存布尔字段的 false 值
+		err := tx.Model(&field).
+			Select("name", "options", "required", "is_unique", "rules", "remark", "sort").
+			Updates(&field).Error
+		if err != nil {
+			return errors.New("更新失败")
+		}
+		return nil
+	})
+	if err != nil {
+		common.FailWithMsg(ctx, err.Error())
+		return
+	}
+	common.OkWithDetailed(ctx, field, "更新成功")
+}
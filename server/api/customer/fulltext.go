@@ -0,0 +1,87 @@
+package customer
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+	"jiangyi.com/utils/search"
+)
+
+type FulltextRequest struct {
+	Page     int    `json:"page" form:"page"`
+	PageSize int    `json:"pageSize" form:"pageSize"`
+	Keyword  string `json:"keyword" form:"keyword"` // 关键字，多个词用空格分隔，需要全部匹配
+	Type     string `json:"type" form:"type"`       // 文档类型 customer 客户, activity 跟进记录，为空时检索全部
+}
+
+// SearchFulltext 全文检索客户和跟进记录
+// @Summary      全文检索客户和跟进记录
+// @Description  在当前角色数据范围内的客户中检索客户名称、电话、标签、联系人、地址和人工录入的跟进记录内容，按相关度排序。
+// @Description  标题和片段中匹配的词以 <em> 标记，其余内容已做 HTML 转义。中文按连续字检索，SQLite 至少 3 个字、MySQL 至少 2 个字时使用全文索引，更短的词按模糊匹配检索
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        data  query     FulltextRequest  true  "页码, 每页大小, 关键字, 文档类型"
+// @Success      200   {object}  common.Response{data=common.PageResult{list=[]search.Hit},msg=string}  "查询成功"
+// @Router       /customer/fulltext [get]
+func (c *Api) SearchFulltext(ctx *gin.Context) {
+	var params FulltextRequest
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PageSize <= 0 {
+		params.PageSize = 10
+	}
+	params.Keyword = strings.TrimSpace(params.Keyword)
+	if params.Keyword == "" {
+		common.FailWithMsg(ctx, "请输入关键字")
+		return
+	}
+	if params.Type != "" && params.Type != business.SearchDocCustomer && params.Type != business.SearchDocActivity {
+		common.FailWithMsg(ctx, "文档类型不正确")
+		return
+	}
+
+	customerIds := global.JY_DB.WithContext(ctx).Model(&business.Customer{}).Select("id").Scopes(utils.DataScope(ctx, "owner_id"))
+	hits, count, err := search.Search(global.JY_DB, params.Keyword, customerIds, params.Type, params.PageSize, (params.Page-1)*params.PageSize)
+	if err != nil {
+		global.JY_LOG.Error("全文检索失败", zap.Error(err))
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+
+	// 填充客户名称
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.CustomerID)
+	}
+	var customers []business.Customer
+	if err = global.JY_DB.Select("id", "customer_name").Where("id IN ?", ids).Find(&customers).Error; err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+	names := make(map[uint]string, len(customers))
+	for _, customer := range customers {
+		names[customer.ID] = customer.CustomerName
+	}
+	for i := range hits {
+		hits[i].CustomerName = names[hits[i].CustomerID]
+	}
+
+	utils.Mask(ctx, hits)
+	common.OkWithDetailed(ctx, common.PageResult{
+		List:     hits,
+		Total:    count,
+		Page:     params.Page,
+		PageSize: params.PageSize,
+	}, "查询成功")
+}
@@ -0,0 +1,30 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+	"jiangyi.com/utils/search"
+)
+
+// RebuildFulltext 重建全文索引
+// @Summary      重建全文索引
+// @Description  按当前的客户、联系人、地址和跟进记录重新生成全文索引文档，仅超级管理员可用。索引通常随数据写入自动更新，只在直接修改数据库等情况下需要重建
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Success      200   {object}  common.Response{msg=string}  "重建成功"
+// @Router       /customer/fulltext/rebuild [post]
+func (c *Api) RebuildFulltext(ctx *gin.Context) {
+	if !utils.CheckSuperAdmin(ctx) {
+		return
+	}
+	if err := search.Rebuild(global.JY_DB); err != nil {
+		global.JY_LOG.Error("重建全文索引失败", zap.Error(err))
+		common.FailWithMsg(ctx, "重建失败")
+		return
+	}
+	common.OkWithMsg(ctx, "重建成功")
+}
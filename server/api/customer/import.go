@@ -0,0 +1,152 @@
+package customer
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// ImportCustomers 导入客户
+// @Summary      导入客户
+// @Description  从 CSV/XLSX 批量导入客户。mapping 为表头与导入列的对应关系（JSON，如 {"名称":"customerName","手机":"customerPhone","等级":"cf.level"}，值为空表示忽略该列），
+// @Description  不传时按列名或列标识自动匹配，可导入的列见导入列接口。逐行校验必填、电话格式、客户状态、负责人、自定义字段，未开启 ignoreDuplicates 时名称或电话与已有客户或文件中前面的行相同视为错误。
+// @Description  mode=partial（默认）导入校验通过的行，mode=all 全部行通过才导入；dryRun=true 只校验不导入。
+// @Description  超过 500 行时在后台执行，返回的任务状态为 pending，需轮询任务详情接口获取进度；失败的行可通过错误文件接口下载，修改后重新导入
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file              formData  file    true   "客户文件(.csv, .xlsx)"
+// @Param        mapping           formData  string  false  "表头与导入列的对应关系(JSON)"
+// @Param        mode              formData  string  false  "导入模式: partial(默认), all"
+// @Param        dryRun            formData  bool    false  "仅校验不导入"
+// @Param        ignoreDuplicates  formData  bool    false  "忽略疑似重复的客户"
+// @Success      200               {object}  common.Response{data=business.CustomerImportJob,msg=string}  "导入任务已创建"
+// @Router       /customer/import [post]
+func (c *Api) ImportCustomers(ctx *gin.Context) {
+	file, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		common.FailWithMsg(ctx, "未接收到文件")
+		return
+	}
+	defer file.Close()
+	dryRun, _ := strconv.ParseBool(ctx.DefaultPostForm("dryRun", "false"))
+	ignoreDuplicates, _ := strconv.ParseBool(ctx.DefaultPostForm("ignoreDuplicates", "false"))
+	mode := ctx.DefaultPostForm("mode", business.ImportModePartial)
+	if mode != business.ImportModePartial && mode != business.ImportModeAll {
+		common.FailWithMsg(ctx, "不支持的导入模式: "+mode)
+		return
+	}
+
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(header.Filename), "."))
+	rows, err := utils.ReadSheet(file, format)
+	if err != nil {
+		common.FailWithMsg(ctx, "读取文件失败: "+err.Error())
+		return
+	}
+	if len(rows) < 2 {
+		common.FailWithMsg(ctx, "文件中没有数据")
+		return
+	}
+	if len(rows)-1 > utils.CustomerImportMaxRows {
+		common.FailWithMsg(ctx, fmt.Sprintf("单次最多导入%d个客户", utils.CustomerImportMaxRows))
+		return
+	}
+	head := make([]string, len(rows[0]))
+	for i, name := range rows[0] {
+		head[i] = strings.TrimSpace(name)
+	}
+
+	columns, _, err := utils.CustomerSheetColumns(global.JY_DB, waitClaims.TenantId)
+	if err != nil {
+		common.FailWithMsg(ctx, "获取导入列失败")
+		return
+	}
+	mapping := utils.MatchCustomerSheetColumns(head, columns)
+	if raw := ctx.PostForm("mapping"); raw != "" {
+		mapping = make(map[string]string)
+		if err = json.Unmarshal([]byte(raw), &mapping); err != nil {
+			common.FailWithMsg(ctx, "列对应关系格式不正确")
+			return
+		}
+	}
+	if err = checkImportMapping(head, mapping, columns); err != nil {
+		common.FailWithMsg(ctx, err.Error())
+		return
+	}
+
+	job := business.CustomerImportJob{
+		TenantID:         waitClaims.TenantId,
+		CreatedBy:        waitClaims.ID,
+		FileName:         header.Filename,
+		Mode:             mode,
+		DryRun:           dryRun,
+		IgnoreDuplicates: ignoreDuplicates,
+		Header:           head,
+		Mapping:          mapping,
+		Status:           business.ImportStatusPending,
+		Total:            len(rows) - 1,
+	}
+	if err = global.JY_DB.WithContext(ctx).Create(&job).Error; err != nil {
+		common.FailWithMsg(ctx, "创建导入任务失败")
+		return
+	}
+
+	// 导入任务不依赖请求上下文，租户在任务中显式指定
+	if job.Total > utils.CustomerImportSyncRows {
+		go utils.RunCustomerImport(global.JY_DB, job, rows[1:])
+		common.OkWithDetailed(ctx, job, "导入任务已创建")
+		return
+	}
+	utils.RunCustomerImport(global.JY_DB, job, rows[1:])
+	global.JY_DB.First(&job, job.ID)
+	common.OkWithDetailed(ctx, job, job.Message)
+}
+
+// checkImportMapping 检查列对应关系：表头存在、导入列可导入且不重复、必填列都已对应
+func checkImportMapping(header []string, mapping map[string]string, columns []utils.CustomerSheetColumn) error {
+	columnMap := make(map[string]utils.CustomerSheetColumn, len(columns))
+	for _, column := range columns {
+		columnMap[column.Key] = column
+	}
+	used := make(map[string]string)
+	for name, key := range mapping {
+		if key == "" {
+			continue
+		}
+		if !utils.ContainsString(header, name) {
+			return fmt.Errorf("文件中没有 %s 列", name)
+		}
+		column, ok := columnMap[key]
+		if !ok || !column.Import {
+			return fmt.Errorf("%s 不是可以导入的列", key)
+		}
+		if other, ok := used[key]; ok {
+			return fmt.Errorf("%s 和 %s 对应了同一列 %s", other, name, column.Name)
+		}
+		used[key] = name
+	}
+	for _, column := range columns {
+		if column.Import && column.Required {
+			if _, ok := used[column.Key]; !ok {
+				return fmt.Errorf("缺少必填列: %s", column.Name)
+			}
+		}
+	}
+	return nil
+}
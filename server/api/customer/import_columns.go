@@ -0,0 +1,30 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// GetImportColumns 获取客户导入导出列
+// @Summary      获取客户导入导出列
+// @Description  获取当前租户客户导入导出的列，包括自定义字段（列标识为 cf.字段标识），用于导入时设置列对应关系和导出时选择列
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Success      200  {object}  common.Response{data=[]utils.CustomerSheetColumn,msg=string}  "获取成功"
+// @Router       /customer/import/columns [get]
+func (c *Api) GetImportColumns(ctx *gin.Context) {
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	columns, _, err := utils.CustomerSheetColumns(global.JY_DB, claims.(*utils.CustomClaims).TenantId)
+	if err != nil {
+		common.FailWithMsg(ctx, "获取导入列失败")
+		return
+	}
+	common.OkWithDetailed(ctx, columns, "获取成功")
+}
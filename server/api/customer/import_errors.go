@@ -0,0 +1,72 @@
+package customer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// DownloadImportErrors 下载客户导入错误文件
+// @Summary      下载客户导入错误文件
+// @Description  下载导入任务中失败的行，保留原始表头和数据，最后一列为错误原因。修改后删除错误原因列即可重新导入
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      octet-stream
+// @Param        id      path      int     true   "导入任务ID"
+// @Param        format  query     string  false  "文件格式: csv(默认), xlsx"
+// @Success      200     {file}    file    "错误文件"
+// @Router       /customer/import/{id}/errors [get]
+func (c *Api) DownloadImportErrors(ctx *gin.Context) {
+	format := ctx.DefaultQuery("format", utils.SheetCSV)
+	if format != utils.SheetCSV && format != utils.SheetXLSX {
+		common.FailWithMsg(ctx, "不支持的格式: "+format)
+		return
+	}
+	job, ok := findImportJob(ctx)
+	if !ok {
+		return
+	}
+	if job.Status == business.ImportStatusPending || job.Status == business.ImportStatusRunning {
+		common.FailWithMsg(ctx, "导入任务尚未完成")
+		return
+	}
+
+	fileName := fmt.Sprintf("customer-import-%d-errors.%s", job.ID, format)
+	ctx.Header("Content-Disposition", "attachment; filename="+fileName)
+	ctx.Header("Content-Type", utils.SheetContentType(format))
+	writer, err := utils.NewSheetWriter(ctx.Writer, format)
+	if err != nil {
+		common.FailWithMsg(ctx, "下载错误文件失败")
+		return
+	}
+
+	err = writer.WriteRow(append(append([]string{}, job.Header...), "错误原因"))
+	if err == nil {
+		var rows []business.CustomerImportError
+		err = global.JY_DB.Where("job_id = ?", job.ID).Order("id ASC").FindInBatches(&rows, 500, func(tx *gorm.DB, batch int) error {
+			for _, row := range rows {
+				// 补齐缺少的单元格，使错误原因对齐到最后一列
+				cells := make([]string, len(job.Header), len(job.Header)+1)
+				copy(cells, row.Cells)
+				if err := writer.WriteRow(append(cells, strings.Join(row.Errors, "；"))); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		// 响应已经开始写出，只能记录日志
+		global.JY_LOG.Error("下载客户导入错误文件失败", zap.Error(err))
+	}
+}
@@ -0,0 +1,63 @@
+package customer
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// GetImportJob 获取客户导入任务
+// @Summary      获取客户导入任务
+// @Description  获取导入任务的状态和进度，后台执行的任务需轮询本接口直到状态为 succeeded 或 failed
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        id   path      int  true  "导入任务ID"
+// @Success      200  {object}  common.Response{data=business.CustomerImportJob,msg=string}  "获取成功"
+// @Router       /customer/import/{id} [get]
+func (c *Api) GetImportJob(ctx *gin.Context) {
+	job, ok := findImportJob(ctx)
+	if !ok {
+		return
+	}
+	common.OkWithDetailed(ctx, job, "获取成功")
+}
+
+// findImportJob 按路径参数获取导入任务，只能查看自己创建的任务，超级管理员可以查看所有任务
+// 执行中的任务从内存读取进度，不查询数据库；找不到时直接返回失败响应
+func findImportJob(ctx *gin.Context) (business.CustomerImportJob, bool) {
+	var job business.CustomerImportJob
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		common.FailWithMsg(ctx, "导入任务ID不正确")
+		return job, false
+	}
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return job, false
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	if running, ok := utils.RunningCustomerImport(uint(id)); ok {
+		if waitClaims.AuthorityId == "888" || (running.TenantID == waitClaims.TenantId && running.CreatedBy == waitClaims.ID) {
+			return running, true
+		}
+		common.FailWithMsg(ctx, "导入任务不存在")
+		return job, false
+	}
+
+	query := global.JY_DB.WithContext(ctx)
+	if waitClaims.AuthorityId != "888" {
+		query = query.Where("created_by = ?", waitClaims.ID)
+	}
+	if err = query.First(&job, id).Error; err != nil {
+		common.FailWithMsg(ctx, "导入任务不存在")
+		return job, false
+	}
+	return job, true
+}
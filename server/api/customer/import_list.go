@@ -0,0 +1,71 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type ImportListRequest struct {
+	Page     int `json:"page" form:"page"`         // 页码
+	PageSize int `json:"pageSize" form:"pageSize"` // 每页大小
+}
+
+// GetImportList 获取客户导入任务列表
+// @Summary      分页获取客户导入任务列表
+// @Description  分页获取自己创建的导入任务，按创建时间倒序，超级管理员可以查看所有任务
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        data  query     ImportListRequest  false  "页码, 每页大小"
+// @Success      200   {object}  common.Response{data=common.PageResult{list=[]business.CustomerImportJob},msg=string}  "查询成功"
+// @Router       /customer/import/list [get]
+func (c *Api) GetImportList(ctx *gin.Context) {
+	var params ImportListRequest
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PageSize <= 0 {
+		params.PageSize = 10
+	}
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	query := global.JY_DB.WithContext(ctx).Model(&business.CustomerImportJob{})
+	if waitClaims.AuthorityId != "888" {
+		query = query.Where("created_by = ?", waitClaims.ID)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+	var jobs []business.CustomerImportJob
+	if err := query.Order("id DESC").Limit(params.PageSize).Offset((params.Page - 1) * params.PageSize).Find(&jobs).Error; err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+	// 执行中的任务以内存中的进度为准
+	for i := range jobs {
+		if running, ok := utils.RunningCustomerImport(jobs[i].ID); ok {
+			jobs[i] = running
+		}
+	}
+
+	common.OkWithDetailed(ctx, common.PageResult{
+		List:     jobs,
+		Total:    count,
+		Page:     params.Page,
+		PageSize: params.PageSize,
+	}, "查询成功")
+}
@@ -2,25 +2,31 @@ package customer
 
 import (
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"jiangyi.com/global"
 	"jiangyi.com/model/business"
 	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
 )
 
 type CustomerListRequest struct {
 	Page     int    `json:"page"`
 	PageSize int    `json:"page_size"`
 	Keyword  string `json:"keyword"`
+	OwnerID  uint   `json:"ownerId" form:"ownerId"` // 负责人ID
+	ViewID   uint   `json:"viewId" form:"viewId"`   // 保存的视图ID，按视图的条件和排序查询
 }
 
 // GetCustomerList 获取客户列表
 // @Summary      分页获取客户列表
-// @Description  分页获取客户列表
+// @Description  分页获取客户列表，按当前角色的数据范围过滤。自定义字段通过 cf[字段标识]=筛选值 筛选，如 cf[level]=A,B&cf[amount]=100~500：
+// @Description  文本模糊匹配；数字、日期支持精确值或 min~max 范围（可省略一端）；单选、多选、用户支持逗号分隔的多个值，满足其一即可。
+// @Description  传 viewId 时在保存的视图的条件上叠加本次的条件，按视图的排序。更复杂的条件使用客户查询接口。没有查看完整电话权限时电话脱敏显示
 // @Security     ApiKeyAuth
 // @Tags         Customer
 // @Accept       json
 // @Produce      json
-// @Param        data  query     CustomerListRequest    true  "页码, 每页大小, 关键字"
+// @Param        data  query     CustomerListRequest    true  "页码, 每页大小, 关键字, 负责人ID, 视图ID"
 // @Success      200   {object}  common.Response{data=common.PageResult,msg=string}  "查询成功"
 // @Router       /customer/list [get]
 func (c *Api) GetCustomerList(ctx *gin.Context) {
@@ -43,9 +49,10 @@ func (c *Api) GetCustomerList(ctx *gin.Context) {
 	var count int64
 
 	// 构建查询条件
-	query := global.JY_DB.Model(&business.Customer{})
-	if params.Keyword != "" {
-		query = query.Where("customer_name LIKE ? OR customer_phone LIKE ?", "%"+params.Keyword+"%", "%"+params.Keyword+"%")
+	query, err := customerListQuery(ctx, params, true)
+	if err != nil {
+		common.FailWithMsg(ctx, err.Error())
+		return
 	}
 
 	// 统计总数
@@ -60,7 +67,12 @@ func (c *Api) GetCustomerList(ctx *gin.Context) {
 		common.FailWithMsg(ctx, "查询失败")
 		return
 	}
+	if err = utils.FillCustomerFields(global.JY_DB, customers); err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
 
+	utils.Mask(ctx, customers)
 	common.OkWithDetailed(ctx, common.PageResult{
 			List:     customers,
 			Total:    count,
@@ -68,3 +80,37 @@ func (c *Api) GetCustomerList(ctx *gin.Context) {
 			PageSize: params.PageSize,
 	}, "查询成功")
 }
+
+// customerListQuery 按列表的筛选条件构建客户查询，导出复用相同的条件
+func customerListQuery(ctx *gin.Context, params CustomerListRequest, sorted bool) (*gorm.DB, error) {
+	fieldFilter, err := utils.CustomerFieldFilter(ctx, ctx.QueryMap("cf"))
+	if err != nil {
+		return nil, err
+	}
+	query, err := customerQuery(ctx, params.ViewID, business.CustomerQuery{Keyword: params.Keyword}, sorted)
+	if err != nil {
+		return nil, err
+	}
+	query = query.Scopes(fieldFilter)
+	if params.OwnerID != 0 {
+		query = query.Where("owner_id = ?", params.OwnerID)
+	}
+	return query, nil
+}
+
+// customerQuery 在当前角色的数据范围内按结构化条件构建客户查询，viewId 不为 0 时在保存的视图上叠加条件
+func customerQuery(ctx *gin.Context, viewId uint, extra business.CustomerQuery, sorted bool) (*gorm.DB, error) {
+	condition := extra
+	if viewId != 0 {
+		view, err := findCustomerView(ctx, viewId)
+		if err != nil {
+			return nil, err
+		}
+		condition = utils.MergeCustomerQuery(view.Query, extra)
+	}
+	scope, err := utils.CustomerQueryScope(ctx, condition, sorted)
+	if err != nil {
+		return nil, err
+	}
+	return global.JY_DB.WithContext(ctx).Model(&business.Customer{}).Scopes(utils.DataScope(ctx, "owner_id"), scope), nil
+}
@@ -0,0 +1,104 @@
+package customer
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+type MergeCustomerRequest struct {
+	TargetID  uint   `json:"targetId" binding:"required"`        // 保留的客户ID
+	SourceIds []uint `json:"sourceIds" binding:"required,min=1"` // 被合并的客户ID
+}
+
+// MergeCustomer 合并客户
+// @Summary      合并客户
+// @Description  将被合并客户的联系人、地址、跟进记录（含附件）转移到保留的客户，保留客户未填写的自定义字段取被合并客户的值，
+// @Description  被合并客户的电话与保留客户不同时作为联系人保留。被合并的客户进入回收站（从回收站恢复不会带回已转移的数据），并写入审计日志。
+// @Description  所有客户必须在当前角色的数据范围内且属于同一租户，任一客户不满足时整体不合并
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Accept       json
+// @Produce      json
+// @Param        data  body      MergeCustomerRequest  true  "保留的客户ID, 被合并的客户ID"
+// @Success      200   {object}  common.Response{data=business.Customer,msg=string}  "合并成功"
+// @Router       /customer/merge [post]
+func (c *Api) MergeCustomer(ctx *gin.Context) {
+	var req MergeCustomerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	if len(req.SourceIds) > utils.CustomerMaxMergeSources {
+		common.FailWithMsg(ctx, fmt.Sprintf("一次最多合并%d个客户", utils.CustomerMaxMergeSources))
+		return
+	}
+	sourceIds := make([]uint, 0, len(req.SourceIds))
+	for _, id := range req.SourceIds {
+		if id == req.TargetID {
+			common.FailWithMsg(ctx, "被合并的客户不能包含保留的客户")
+			return
+		}
+		if !utils.ContainsUint(sourceIds, id) {
+			sourceIds = append(sourceIds, id)
+		}
+	}
+
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	var target business.Customer
+	err := global.JY_DB.WithContext(ctx).Scopes(utils.DataScope(ctx, "owner_id")).Where("id = ?", req.TargetID).First(&target).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "保留的客户不存在")
+		return
+	}
+	var sources []business.Customer
+	err = global.JY_DB.WithContext(ctx).Scopes(utils.DataScope(ctx, "owner_id")).Where("id IN ?", sourceIds).Order("id ASC").Find(&sources).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "查询客户失败")
+		return
+	}
+	if len(sources) != len(sourceIds) {
+		common.FailWithMsg(ctx, "被合并的客户不存在")
+		return
+	}
+
+	// saveErr 为需要直接返回给前端的合并校验错误
+	var saveErr error
+	err = global.JY_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result, err := utils.MergeCustomers(tx, target, sources, waitClaims.ID)
+		if err != nil {
+			saveErr = err
+			return err
+		}
+		return utils.RecordAudit(tx, ctx, system.SysAuditLog{
+			TenantId:     target.TenantID,
+			Action:       system.AuditCustomerMerge,
+			ResourceType: "customer",
+			ResourceId:   target.ID,
+		}, result)
+	})
+	if saveErr != nil {
+		common.FailWithMsg(ctx, saveErr.Error())
+		return
+	}
+	if err != nil {
+		common.FailWithMsg(ctx, "合并失败")
+		return
+	}
+
+	target, _ = loadCustomerDetail(global.JY_DB.WithContext(ctx), target.ID)
+	utils.Mask(ctx, &target)
+	common.OkWithDetailed(ctx, target, "合并成功")
+}
@@ -0,0 +1,68 @@
+package customer
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+	"jiangyi.com/utils/search"
+)
+
+// GetPoolList 获取公海客户列表
+// @Summary      分页获取公海客户列表
+// @Description  分页获取公海中的客户，按进入公海的时间倒序，不受角色数据范围限制。关键字和自定义字段筛选与客户列表相同
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        data  query     CustomerListRequest  true  "页码, 每页大小, 关键字"
+// @Success      200   {object}  common.Response{data=common.PageResult,msg=string}  "查询成功"
+// @Router       /customer/pool/list [get]
+func (c *Api) GetPoolList(ctx *gin.Context) {
+	var params CustomerListRequest
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PageSize <= 0 {
+		params.PageSize = 10
+	}
+
+	fieldFilter, err := utils.CustomerFieldFilter(ctx, ctx.QueryMap("cf"))
+	if err != nil {
+		common.FailWithMsg(ctx, err.Error())
+		return
+	}
+	query := global.JY_DB.WithContext(ctx).Model(&business.Customer{}).Scopes(fieldFilter).Where("owner_id = 0")
+	if keyword := strings.TrimSpace(params.Keyword); keyword != "" {
+		query = query.Where("id IN (?)", search.CustomerIDs(global.JY_DB, keyword))
+	}
+
+	var count int64
+	if err = query.Count(&count).Error; err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+	var customers []business.Customer
+	if err = query.Order("updated_at DESC").Limit(params.PageSize).Offset((params.Page - 1) * params.PageSize).Find(&customers).Error; err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+	if err = utils.FillCustomerFields(global.JY_DB, customers); err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+
+	utils.Mask(ctx, customers)
+	common.OkWithDetailed(ctx, common.PageResult{
+		List:     customers,
+		Total:    count,
+		Page:     params.Page,
+		PageSize: params.PageSize,
+	}, "查询成功")
+}
@@ -0,0 +1,42 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// GetPoolSetting 获取公海规则
+// @Summary      获取公海规则
+// @Description  获取当前租户的公海规则：每人最多负责的客户数、每天最多领取数、未跟进自动退回公海的天数，0 表示不限制
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        data  query     WorkflowRequest  false  "租户ID"
+// @Success      200   {object}  common.Response{data=business.CustomerPoolSetting,msg=string}  "获取成功"
+// @Router       /customer/pool/setting [get]
+func (c *Api) GetPoolSetting(ctx *gin.Context) {
+	var req WorkflowRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+	tenantId := waitClaims.TenantId
+	if req.TenantID != 0 && waitClaims.AuthorityId == "888" {
+		tenantId = req.TenantID
+	}
+
+	setting, err := utils.GetCustomerPoolSetting(global.JY_DB, tenantId)
+	if err != nil {
+		common.FailWithMsg(ctx, "获取公海规则失败")
+		return
+	}
+	common.OkWithDetailed(ctx, setting, "获取成功")
+}
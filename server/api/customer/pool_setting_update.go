@@ -0,0 +1,55 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type UpdatePoolSettingRequest struct {
+	TenantID        uint `json:"tenantId"`                            // 租户ID，不填时为当前租户
+	MaxOwned        int  `json:"maxOwned" binding:"min=0"`            // 每人最多负责的客户数，0 表示不限制
+	DailyClaimLimit int  `json:"dailyClaimLimit" binding:"min=0"`     // 每人每天最多从公海领取的客户数，0 表示不限制
+	ReturnDays      int  `json:"returnDays" binding:"min=0,max=3650"` // 超过多少天未跟进自动退回公海，0 表示不自动退回
+}
+
+// UpdatePoolSetting 保存公海规则
+// @Summary      保存公海规则
+// @Description  保存租户的公海规则，仅超级管理员可用。降低负责客户数上限不影响已负责的客户，只限制之后的领取和分配；
+// @Description  自动退回按最近跟进时间和分配时间中较晚的一个计算，处于终止状态的客户不退回
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Accept       json
+// @Produce      json
+// @Param        data  body      UpdatePoolSettingRequest  true  "租户ID, 负责客户数上限, 每天领取上限, 自动退回天数"
+// @Success      200   {object}  common.Response{data=business.CustomerPoolSetting,msg=string}  "保存成功"
+// @Router       /customer/pool/setting [put]
+func (c *Api) UpdatePoolSetting(ctx *gin.Context) {
+	if !utils.CheckSuperAdmin(ctx) {
+		return
+	}
+	var req UpdatePoolSettingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	if req.TenantID == 0 {
+		claims, _ := ctx.Get("claims")
+		req.TenantID = claims.(*utils.CustomClaims).TenantId
+	}
+
+	setting, err := utils.GetCustomerPoolSetting(global.JY_DB, req.TenantID)
+	if err != nil {
+		common.FailWithMsg(ctx, "获取公海规则失败")
+		return
+	}
+	setting.MaxOwned = req.MaxOwned
+	setting.DailyClaimLimit = req.DailyClaimLimit
+	setting.ReturnDays = req.ReturnDays
+	if err = global.JY_DB.Save(&setting).Error; err != nil {
+		common.FailWithMsg(ctx, "保存失败")
+		return
+	}
+	common.OkWithDetailed(ctx, setting, "保存成功")
+}
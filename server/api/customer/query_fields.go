@@ -0,0 +1,30 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// GetQueryFields 获取客户查询字段
+// @Summary      获取客户查询字段
+// @Description  获取当前租户客户查询可用的字段、运算符和是否可以排序，包括自定义字段（字段为 cf.字段标识），用于构建查询条件。客户电话只在有查看完整电话权限时返回
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Success      200  {object}  common.Response{data=[]utils.CustomerQueryField,msg=string}  "获取成功"
+// @Router       /customer/query/fields [get]
+func (c *Api) GetQueryFields(ctx *gin.Context) {
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	fields, err := utils.CustomerQueryFields(ctx, global.JY_DB, claims.(*utils.CustomClaims).TenantId)
+	if err != nil {
+		common.FailWithMsg(ctx, "获取查询字段失败")
+		return
+	}
+	common.OkWithDetailed(ctx, fields, "获取成功")
+}
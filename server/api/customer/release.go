@@ -0,0 +1,47 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type ReleaseCustomerRequest struct {
+	CustomerIds []uint `json:"customerIds" binding:"required,min=1,max=500"` // 客户ID
+	Reason      string `json:"reason"`                                       // 原因，记录到客户时间线
+}
+
+// ReleaseCustomer 客户退回公海
+// @Summary      客户退回公海
+// @Description  将当前角色数据范围内的客户退回公海，支持批量。每个客户单独处理，返回成功数和失败的客户
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Accept       json
+// @Produce      json
+// @Param        data  body      ReleaseCustomerRequest  true  "客户ID, 原因"
+// @Success      200   {object}  common.Response{data=utils.CustomerBatchResult,msg=string}  "操作完成"
+// @Router       /customer/release [post]
+func (c *Api) ReleaseCustomer(ctx *gin.Context) {
+	var req ReleaseCustomerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	var customers []business.Customer
+	err := global.JY_DB.WithContext(ctx).Scopes(utils.DataScope(ctx, "owner_id")).Where("id IN ? AND owner_id <> 0", req.CustomerIds).Find(&customers).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "查询客户失败")
+		return
+	}
+	result := utils.ChangeCustomerOwners(global.JY_DB.WithContext(ctx), customers, req.CustomerIds, 0, req.Reason, waitClaims.ID, "客户不存在或已在公海中")
+	common.OkWithDetailed(ctx, result, "操作完成")
+}
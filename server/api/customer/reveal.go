@@ -0,0 +1,77 @@
+package customer
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+// RevealedContact 联系人的完整电话
+type RevealedContact struct {
+	ID     uint     `json:"ID"`
+	Name   string   `json:"name"`   // 姓名
+	Phones []string `json:"phones"` // 完整电话
+}
+
+// RevealedPhones 客户的完整电话
+type RevealedPhones struct {
+	CustomerID    uint              `json:"customerId"`    // 客户ID
+	CustomerPhone string            `json:"customerPhone"` // 完整的客户电话
+	Contacts      []RevealedContact `json:"contacts"`      // 联系人的完整电话
+}
+
+// RevealCustomerPhone 查看客户完整电话
+// @Summary      查看客户完整电话
+// @Description  返回单个客户的完整电话和联系人电话，并记录审计日志。没有查看完整电话权限（customer:view-phone）的用户在列表、详情、导出中看到的是脱敏后的电话，需要联系客户时通过该接口逐个查看。按当前角色的数据范围过滤
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        id   path      int  true  "客户ID"
+// @Success      200  {object}  common.Response{data=RevealedPhones,msg=string}  "查询成功"
+// @Router       /customer/{id}/reveal [post]
+func (c *Api) RevealCustomerPhone(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	if id <= 0 {
+		common.FailWithMsg(ctx, "客户ID不能为空")
+		return
+	}
+
+	var customer business.Customer
+	err := global.JY_DB.WithContext(ctx).Scopes(utils.DataScope(ctx, "owner_id")).
+		Preload("Contacts", func(db *gorm.DB) *gorm.DB { return db.Order("is_primary DESC, id ASC") }).
+		Where("id = ?", id).First(&customer).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			common.FailWithMsg(ctx, "客户不存在")
+			return
+		}
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+
+	revealed := RevealedPhones{CustomerID: customer.ID, CustomerPhone: customer.CustomerPhone, Contacts: make([]RevealedContact, 0, len(customer.Contacts))}
+	contactIds := make([]uint, 0, len(customer.Contacts))
+	for _, contact := range customer.Contacts {
+		revealed.Contacts = append(revealed.Contacts, RevealedContact{ID: contact.ID, Name: contact.Name, Phones: contact.Phones})
+		contactIds = append(contactIds, contact.ID)
+	}
+
+	// 审计日志只记录查看了哪些记录，不记录电话本身
+	err = utils.RecordAudit(global.JY_DB, ctx, system.SysAuditLog{
+		TenantId:     customer.TenantID,
+		Action:       system.AuditCustomerRevealPhone,
+		ResourceType: "customer",
+		ResourceId:   customer.ID,
+	}, map[string]interface{}{"contactIds": contactIds})
+	if err != nil {
+		common.FailWithMsg(ctx, "记录审计日志失败")
+		return
+	}
+	common.OkWithDetailed(ctx, revealed, "查询成功")
+}
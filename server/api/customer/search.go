@@ -0,0 +1,72 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type SearchCustomerRequest struct {
+	Page     int  `json:"page"`     // 页码
+	PageSize int  `json:"pageSize"` // 每页大小
+	ViewID   uint `json:"viewId"`   // 保存的视图ID，不为 0 时在视图的条件上叠加本次的条件
+	business.CustomerQuery
+}
+
+// SearchCustomers 查询客户
+// @Summary      按结构化条件分页查询客户
+// @Description  在当前角色的数据范围内按结构化条件查询客户，各条件之间为“且”的关系，可查询的字段和运算符见查询字段接口。
+// @Description  filters 如 [{"field":"customerStatus","op":"in","value":["new","following"]},{"field":"ownerId","op":"eq","value":"me"},{"field":"createdAt","op":"range","value":{"from":"-7d"}},{"field":"cf.level","op":"isNull","value":false}]：
+// @Description  eq 等于，多选字段和标签为包含；in 等于其中之一；range 范围 {"from","to"}，包含两端，可省略一端；contains 文本包含；isNull 为 true 时未填写，false 时已填写。
+// @Description  用户类字段的值可以用 me 表示当前用户；时间、日期的值可以用 YYYY-MM-DD、today、-7d（7 天前）等，按用户的时区计算。
+// @Description  sorts 如 [{"field":"lastFollowedAt","desc":true}]，最多 3 个，只能按允许排序的固定字段排序。传 viewId 时叠加视图的条件，关键字和排序不为空时替换视图中的
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Accept       json
+// @Produce      json
+// @Param        data  body      SearchCustomerRequest  true  "页码, 每页大小, 视图ID, 关键字, 筛选条件, 排序"
+// @Success      200   {object}  common.Response{data=common.PageResult{list=[]business.Customer},msg=string}  "查询成功"
+// @Router       /customer/search [post]
+func (c *Api) SearchCustomers(ctx *gin.Context) {
+	var req SearchCustomerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	query, err := customerQuery(ctx, req.ViewID, req.CustomerQuery, true)
+	if err != nil {
+		common.FailWithMsg(ctx, err.Error())
+		return
+	}
+	var count int64
+	if err = query.Count(&count).Error; err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+	var customers []business.Customer
+	if err = query.Limit(req.PageSize).Offset((req.Page - 1) * req.PageSize).Find(&customers).Error; err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+	if err = utils.FillCustomerFields(global.JY_DB, customers); err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+
+	utils.Mask(ctx, customers)
+	common.OkWithDetailed(ctx, common.PageResult{
+		List:     customers,
+		Total:    count,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, "查询成功")
+}
@@ -0,0 +1,50 @@
+package customer
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// GetStatusHistory 获取客户状态历史
+// @Summary      获取客户状态历史
+// @Description  获取客户进入过的每个状态及进入、离开时间，按进入时间正序。仍处于的状态 duration 为截至当前的停留时长（秒）
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        id   path      int  true  "客户ID"
+// @Success      200  {object}  common.Response{data=[]business.CustomerStatusHistory,msg=string}  "查询成功"
+// @Router       /customer/{id}/status/history [get]
+func (c *Api) GetStatusHistory(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	if id <= 0 {
+		common.FailWithMsg(ctx, "客户ID不能为空")
+		return
+	}
+
+	var customer business.Customer
+	err := global.JY_DB.WithContext(ctx).Scopes(utils.DataScope(ctx, "owner_id")).Where("id = ?", id).First(&customer).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "客户不存在")
+		return
+	}
+
+	var histories []business.CustomerStatusHistory
+	err = global.JY_DB.WithContext(ctx).Where(&business.CustomerStatusHistory{CustomerID: customer.ID}).Order("entered_at ASC, id ASC").Find(&histories).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+	now := time.Now()
+	for i := range histories {
+		if histories[i].LeftAt == nil {
+			histories[i].Duration = int64(now.Sub(histories[i].EnteredAt).Seconds())
+		}
+	}
+	common.OkWithDetailed(ctx, histories, "查询成功")
+}
@@ -0,0 +1,105 @@
+package customer
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type StatusStatsRequest struct {
+	StartTime *time.Time `json:"startTime" form:"startTime" time_format:"2006-01-02 15:04:05"` // 离开状态的时间起
+	EndTime   *time.Time `json:"endTime" form:"endTime" time_format:"2006-01-02 15:04:05"`     // 离开状态的时间止
+}
+
+// StatusStat 单个状态的停留时长统计
+type StatusStat struct {
+	Status      string  `json:"status"`      // 状态编码
+	Name        string  `json:"name"`        // 状态名称
+	Current     int64   `json:"current"`     // 当前处于该状态的客户数
+	Completed   int64   `json:"completed"`   // 已离开该状态的次数
+	AvgDuration float64 `json:"avgDuration"` // 平均停留时长（秒），按已离开的记录统计
+	MaxDuration int64   `json:"maxDuration"` // 最长停留时长（秒）
+}
+
+// GetStatusStats 获取客户各状态的停留时长统计
+// @Summary      获取客户各状态的停留时长统计
+// @Description  统计当前角色数据范围内客户在各状态的停留时长（按已离开该状态的记录计算）和当前处于各状态的客户数，可按离开时间筛选
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        data  query     StatusStatsRequest  false  "离开时间范围"
+// @Success      200   {object}  common.Response{data=[]StatusStat,msg=string}  "查询成功"
+// @Router       /customer/status/stats [get]
+func (c *Api) GetStatusStats(ctx *gin.Context) {
+	var params StatusStatsRequest
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	workflow, err := utils.GetCustomerWorkflow(global.JY_DB, claims.(*utils.CustomClaims).TenantId)
+	if err != nil {
+		common.FailWithMsg(ctx, "获取客户状态流程失败")
+		return
+	}
+
+	customerIds := global.JY_DB.WithContext(ctx).Model(&business.Customer{}).Select("id").Scopes(utils.DataScope(ctx, "owner_id"))
+	var durations []struct {
+		Status      string
+		Completed   int64
+		AvgDuration float64
+		MaxDuration int64
+	}
+	query := global.JY_DB.WithContext(ctx).Model(&business.CustomerStatusHistory{}).
+		Select("status, COUNT(*) AS completed, AVG(duration) AS avg_duration, MAX(duration) AS max_duration").
+		Where("left_at IS NOT NULL AND customer_id IN (?)", customerIds)
+	if params.StartTime != nil {
+		query = query.Where("left_at >= ?", *params.StartTime)
+	}
+	if params.EndTime != nil {
+		query = query.Where("left_at <= ?", *params.EndTime)
+	}
+	if err = query.Group("status").Scan(&durations).Error; err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+
+	var currents []struct {
+		CustomerStatus string
+		Count          int64
+	}
+	err = global.JY_DB.WithContext(ctx).Model(&business.Customer{}).Scopes(utils.DataScope(ctx, "owner_id")).
+		Select("customer_status, COUNT(*) AS count").Group("customer_status").Scan(&currents).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+
+	// 按流程中的状态顺序输出，不在流程中的旧状态不统计
+	stats := make([]StatusStat, 0, len(workflow.Statuses))
+	for _, status := range workflow.Statuses {
+		stat := StatusStat{Status: status.Code, Name: status.Name}
+		for _, duration := range durations {
+			if duration.Status == status.Code {
+				stat.Completed = duration.Completed
+				stat.AvgDuration = duration.AvgDuration
+				stat.MaxDuration = duration.MaxDuration
+			}
+		}
+		for _, current := range currents {
+			if current.CustomerStatus == status.Code {
+				stat.Current = current.Count
+			}
+		}
+		stats = append(stats, stat)
+	}
+	common.OkWithDetailed(ctx, stats, "查询成功")
+}
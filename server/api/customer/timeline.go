@@ -0,0 +1,142 @@
+package customer
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type TimelineRequest struct {
+	Page     int    `json:"page" form:"page"`
+	PageSize int    `json:"pageSize" form:"pageSize"`
+	Type     string `json:"type" form:"type"` // 只看指定类型的记录
+}
+
+// TimelineDay 时间线中的一天
+type TimelineDay struct {
+	Date  string                      `json:"date"`  // 日期，YYYY-MM-DD，按用户设置的时区
+	Items []business.CustomerActivity `json:"items"` // 当天的记录，按发生时间倒序
+}
+
+// CustomerTimeline 客户时间线
+type CustomerTimeline struct {
+	Customer       business.Customer `json:"customer"`       // 客户
+	Stats          map[string]int64  `json:"stats"`          // 各类型记录数
+	LastActivityAt *time.Time        `json:"lastActivityAt"` // 最近一次跟进时间（不含系统记录），没有跟进时为空
+	Total          int64             `json:"total"`          // 记录总数（按类型筛选后）
+	Page           int               `json:"page"`
+	PageSize       int               `json:"pageSize"`
+	Days           []TimelineDay     `json:"days"` // 按天分组的记录
+}
+
+// GetCustomerTimeline 获取客户时间线
+// @Summary      获取客户时间线
+// @Description  汇总客户的跟进记录和系统记录的变更，返回各类型记录数、最近一次跟进时间，以及按天分组、按发生时间倒序的分页记录
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        id    path      int              true  "客户ID"
+// @Param        data  query     TimelineRequest  true  "页码, 每页大小, 类型"
+// @Success      200   {object}  common.Response{data=CustomerTimeline,msg=string}  "查询成功"
+// @Router       /customer/{id}/timeline [get]
+func (c *Api) GetCustomerTimeline(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	if id <= 0 {
+		common.FailWithMsg(ctx, "客户ID不能为空")
+		return
+	}
+	var params TimelineRequest
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PageSize <= 0 {
+		params.PageSize = 50
+	}
+
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	var customer business.Customer
+	err := global.JY_DB.WithContext(ctx).Scopes(utils.DataScope(ctx, "owner_id")).Where("id = ?", id).First(&customer).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "客户不存在")
+		return
+	}
+	timeline := CustomerTimeline{Customer: customer, Stats: map[string]int64{}, Page: params.Page, PageSize: params.PageSize, Days: []TimelineDay{}}
+
+	// 各类型记录数
+	var stats []struct {
+		Type  string
+		Count int64
+	}
+	err = global.JY_DB.WithContext(ctx).Model(&business.CustomerActivity{}).Select("type, COUNT(*) AS count").
+		Where("customer_id = ?", customer.ID).Group("type").Scan(&stats).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+	for _, stat := range stats {
+		timeline.Stats[stat.Type] = stat.Count
+	}
+
+	// 最近一次人工跟进
+	var last business.CustomerActivity
+	err = global.JY_DB.WithContext(ctx).Where("customer_id = ? AND type IN ?", customer.ID, manualActivityTypes()).
+		Order("occurred_at DESC").Limit(1).Find(&last).Error
+	if err == nil && last.ID != 0 {
+		timeline.LastActivityAt = &last.OccurredAt
+	}
+
+	query := global.JY_DB.WithContext(ctx).Model(&business.CustomerActivity{}).Where("customer_id = ?", customer.ID)
+	if params.Type != "" {
+		query = query.Where(&business.CustomerActivity{Type: params.Type})
+	}
+	if err = query.Count(&timeline.Total).Error; err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+	var activities []business.CustomerActivity
+	err = query.Preload("Files").Order("occurred_at DESC, id DESC").
+		Limit(params.PageSize).Offset((params.Page - 1) * params.PageSize).Find(&activities).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "查询失败")
+		return
+	}
+	utils.FillActivityCreators(global.JY_DB, activities)
+
+	// 按天分组，日期按当前用户设置的时区计算
+	location := utils.UserLocation(waitClaims.ID)
+	for _, activity := range activities {
+		date := activity.OccurredAt.In(location).Format("2006-01-02")
+		if n := len(timeline.Days); n > 0 && timeline.Days[n-1].Date == date {
+			timeline.Days[n-1].Items = append(timeline.Days[n-1].Items, activity)
+			continue
+		}
+		timeline.Days = append(timeline.Days, TimelineDay{Date: date, Items: []business.CustomerActivity{activity}})
+	}
+
+	utils.Mask(ctx, &timeline)
+	common.OkWithDetailed(ctx, timeline, "查询成功")
+}
+
+// manualActivityTypes 人工录入的跟进类型
+func manualActivityTypes() []string {
+	types := make([]string, 0, len(utils.ManualActivityTypes))
+	for activityType := range utils.ManualActivityTypes {
+		types = append(types, activityType)
+	}
+	return types
+}
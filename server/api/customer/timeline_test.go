@@ -0,0 +1,97 @@
+package customer
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+// TestMergedCustomerTimelineMasksPhones 合并客户后，没有查看完整电话权限的用户在时间线中看不到被合并客户的完整电话
+func TestMergedCustomerTimelineMasksPhones(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(&system.SysUser{}, &system.SysAuthority{}, &system.SysDept{}, &system.SysAuthorityGrant{},
+		&system.ExaFileUploadAndDownload{}, &system.SysPreferenceDefinition{}, &system.SysUserPreference{},
+		&business.Customer{}, &business.CustomerContact{}, &business.CustomerAddress{}, &business.CustomerActivity{},
+		&business.CustomerFieldValue{}, &business.CustomerStatusHistory{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := global.JY_DB
+	global.JY_DB = db
+	t.Cleanup(func() { global.JY_DB = previous })
+
+	if err = db.Create(&[]system.SysAuthority{
+		{AuthorityId: "100", AuthorityName: "销售", Enable: true, DataScope: system.DataScopeAll},
+		{AuthorityId: "200", AuthorityName: "经理", Enable: true, DataScope: system.DataScopeAll, Permissions: []string{system.PermissionCustomerViewPhone}},
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+	target := business.Customer{CustomerName: "上海贸易", CustomerPhone: "+8613800138000", TenantID: 1}
+	source := business.Customer{CustomerName: "上海贸易公司", CustomerPhone: "+8613900139000", TenantID: 1}
+	if err = db.Create(&target).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Create(&source).Error; err != nil {
+		t.Fatal(err)
+	}
+	// 旧版本的合并记录中包含被合并客户的电话
+	if err = utils.RecordCustomerChange(db, target, business.ActivityMerge, "0", "1", "合并客户: 旧客户（+8613700137000）合并到本客户", 1); err != nil {
+		t.Fatal(err)
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		_, err := utils.MergeCustomers(tx, target, []business.Customer{source}, 1)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("MergeCustomers() error = %v", err)
+	}
+
+	timeline := func(authorityId string) string {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/customer/1/timeline", nil)
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		c.Set("claims", &utils.CustomClaims{ID: 1, AuthorityId: authorityId, TenantId: 1})
+		(&Api{}).GetCustomerTimeline(c)
+		var resp struct {
+			Code int              `json:"code"`
+			Data CustomerTimeline `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || len(resp.Data.Days) == 0 {
+			t.Fatalf("获取时间线失败: %s", w.Body.String())
+		}
+		return w.Body.String()
+	}
+
+	body := timeline("100")
+	for _, phone := range []string{"13800138000", "13900139000", "13700137000"} {
+		if strings.Contains(body, phone) {
+			t.Errorf("没有权限的用户在时间线中看到了完整电话 %s: %s", phone, body)
+		}
+	}
+	if !strings.Contains(body, "上海贸易公司（ID:2）合并到本客户") {
+		t.Errorf("时间线中没有合并记录: %s", body)
+	}
+	if !strings.Contains(body, "137****7000") {
+		t.Errorf("旧的合并记录中的电话没有脱敏: %s", body)
+	}
+
+	body = timeline("200")
+	if !strings.Contains(body, "+8613800138000") || !strings.Contains(body, "+8613700137000") {
+		t.Errorf("有权限的用户应看到完整电话: %s", body)
+	}
+}
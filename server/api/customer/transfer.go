@@ -0,0 +1,42 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// TransferCustomer 转移客户
+// @Summary      转移客户
+// @Description  将当前角色数据范围内已有负责人的客户转给其他负责人，支持批量。每个客户单独处理，返回成功数和失败的客户；负责人负责的客户数受公海规则限制
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Accept       json
+// @Produce      json
+// @Param        data  body      ChangeOwnerRequest  true  "客户ID, 负责人ID, 原因"
+// @Success      200   {object}  common.Response{data=utils.CustomerBatchResult,msg=string}  "转移完成"
+// @Router       /customer/transfer [post]
+func (c *Api) TransferCustomer(ctx *gin.Context) {
+	var req ChangeOwnerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	var customers []business.Customer
+	err := global.JY_DB.WithContext(ctx).Scopes(utils.DataScope(ctx, "owner_id")).Where("id IN ? AND owner_id <> 0", req.CustomerIds).Find(&customers).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "查询客户失败")
+		return
+	}
+	result := utils.ChangeCustomerOwners(global.JY_DB.WithContext(ctx), customers, req.CustomerIds, req.OwnerID, req.Reason, waitClaims.ID, "客户不存在或在公海中")
+	common.OkWithDetailed(ctx, result, "转移完成")
+}
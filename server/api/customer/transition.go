@@ -0,0 +1,105 @@
+package customer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type TransitionRequest struct {
+	CustomerID   uint                       `json:"customerId" binding:"required"` // 客户ID
+	ToStatus     string                     `json:"toStatus" binding:"required"`   // 目标状态编码
+	Reason       string                     `json:"reason"`                        // 原因，流转规则要求时必填
+	CustomFields map[string]json.RawMessage `json:"customFields"`                  // 同时修改的自定义字段，用于填写进入目标状态必填的字段
+}
+
+// TransitionCustomer 客户状态流转
+// @Summary      客户状态流转
+// @Description  按租户配置的流程修改客户状态：只能执行流程中存在的流转，并检查执行角色、原因和进入目标状态必填的自定义字段。
+// @Description  流转会记录状态历史（用于统计各状态停留时长）并写入客户时间线
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Accept       json
+// @Produce      json
+// @Param        data  body      TransitionRequest  true  "客户ID, 目标状态, 原因, 自定义字段"
+// @Success      200   {object}  common.Response{data=business.Customer,msg=string}  "操作成功"
+// @Router       /customer/transition [post]
+func (c *Api) TransitionCustomer(ctx *gin.Context) {
+	var req TransitionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	var customer business.Customer
+	err := global.JY_DB.WithContext(ctx).Scopes(utils.DataScope(ctx, "owner_id")).Where("id = ?", req.CustomerID).First(&customer).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "客户不存在")
+		return
+	}
+
+	workflow, err := utils.GetCustomerWorkflow(global.JY_DB, customer.TenantID)
+	if err != nil {
+		common.FailWithMsg(ctx, "获取客户状态流程失败")
+		return
+	}
+	if !utils.WorkflowHasStatus(workflow, req.ToStatus) {
+		common.FailWithMsg(ctx, fmt.Sprintf("状态 %s 不存在", req.ToStatus))
+		return
+	}
+	transition, ok := utils.FindWorkflowTransition(workflow, customer.CustomerStatus, req.ToStatus)
+	if !ok {
+		common.FailWithMsg(ctx, fmt.Sprintf("不能从%s流转到%s", utils.WorkflowStatusName(workflow, customer.CustomerStatus), utils.WorkflowStatusName(workflow, req.ToStatus)))
+		return
+	}
+	if !utils.CanPerformTransition(waitClaims, transition) {
+		common.FailWithMsg(ctx, "当前角色不能执行该操作")
+		return
+	}
+	if transition.RequireReason && req.Reason == "" {
+		common.FailWithMsg(ctx, "请填写原因")
+		return
+	}
+
+	// saveErr 为自定义字段校验、必填检查等需要直接返回给前端的错误
+	var saveErr error
+	err = global.JY_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(req.CustomFields) > 0 {
+			if saveErr = utils.SaveCustomerFieldValues(tx, customer, req.CustomFields, false); saveErr != nil {
+				return saveErr
+			}
+		}
+		if saveErr = utils.CheckRequiredCustomerFields(tx, customer, transition.RequiredFields); saveErr != nil {
+			return saveErr
+		}
+		saveErr = utils.ChangeCustomerStatus(tx, customer, workflow, req.ToStatus, req.Reason, waitClaims.ID)
+		return saveErr
+	})
+	if saveErr != nil {
+		common.FailWithMsg(ctx, saveErr.Error())
+		return
+	}
+	if err != nil {
+		common.FailWithMsg(ctx, "操作失败")
+		return
+	}
+
+	customer, _ = loadCustomerDetail(global.JY_DB.WithContext(ctx), customer.ID)
+	utils.Mask(ctx, &customer)
+	common.OkWithDetailed(ctx, customer, "操作成功")
+}
@@ -1,28 +1,40 @@
 package customer
 
 import (
+	"encoding/json"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"jiangyi.com/global"
 	"jiangyi.com/model/business"
 	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
 )
 
 type UpdateCustomerRequest struct {
 	ID             uint   `json:"id" binding:"required"`
 	CustomerName   string `json:"customerName"`
 	CustomerPhone  string `json:"customerPhone"`
-	CustomerStatus string `json:"customerStatus"`
+	CustomerStatus string `json:"customerStatus"` // 仅兼容旧版前端，与当前状态不同时拒绝修改
+
+	CustomFields map[string]json.RawMessage  `json:"customFields"` // 需要修改的自定义字段值，值为 null 时清空
+	Contacts     *[]business.CustomerContact `json:"contacts"`     // 联系人，不传时不修改，传入时整体替换（带 ID 的更新，不带 ID 的新建，未传入的删除）
+	Addresses    *[]business.CustomerAddress `json:"addresses"`    // 地址，规则同联系人
+	Tags         *[]string                   `json:"tags"`         // 标签，不传时不修改，传入时整体替换
+
+	IgnoreDuplicates bool `json:"ignoreDuplicates"` // 确认不是重复客户，存在疑似重复的客户时仍然修改
 }
 
 // UpdateCustomer 更新客户
 // @Summary      更新客户
-// @Description  更新客户
+// @Description  更新客户，自定义字段只修改请求中包含的字段，值为 null 时清空；联系人、地址、标签传入时按列表整体替换。任一项不合法时整体不修改。
+// @Description  客户状态不能在这里修改，需通过状态流转接口按流程修改。修改名称、电话或联系人时与新建客户一样检查疑似重复的客户
 // @Security     ApiKeyAuth
 // @Tags         Customer
 // @Accept       json
 // @Produce      json
-// @Param        data  body      UpdateCustomerRequest  true  "客户ID, 客户姓名, 客户电话"
+// @Param        data  body      UpdateCustomerRequest  true  "客户ID, 客户姓名, 客户电话, 自定义字段, 联系人, 地址, 标签, 忽略疑似重复"
 // @Success      200   {object}  common.Response{data=business.Customer,msg=string}  "更新成功"
 // @Router       /customer [put]
 func (c *Api) UpdateCustomer(ctx *gin.Context) {
@@ -33,8 +45,8 @@ func (c *Api) UpdateCustomer(ctx *gin.Context) {
 	}
 
 	var customer business.Customer
-	// 先查询客户是否存在
-	if err := global.JY_DB.First(&customer, req.ID).Error; err != nil {
+	// 先查询客户是否存在，只能操作数据权限范围内的客户
+	if err := global.JY_DB.WithContext(ctx).Scopes(utils.DataScope(ctx, "owner_id")).Where("id = ?", req.ID).First(&customer).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			common.FailWithMsg(ctx, "客户不存在")
 			return
@@ -43,25 +55,105 @@ func (c *Api) UpdateCustomer(ctx *gin.Context) {
 		return
 	}
 
+	// 状态只能按流程流转，不能直接修改
+	if req.CustomerStatus != "" && req.CustomerStatus != customer.CustomerStatus {
+		common.FailWithMsg(ctx, "客户状态请通过状态流转接口修改")
+		return
+	}
+
 	// 更新字段
 	updateData := make(map[string]interface{})
+	name, phone := customer.CustomerName, customer.CustomerPhone
 	if req.CustomerName != "" {
-		updateData["customer_name"] = req.CustomerName
+		name = strings.TrimSpace(req.CustomerName)
+		updateData["customer_name"] = name
 	}
 	if req.CustomerPhone != "" {
-		updateData["customer_phone"] = req.CustomerPhone
+		// 没有查看完整电话权限的用户原样提交脱敏显示的电话时，不修改电话
+		normalized, err := utils.NormalizePhone(utils.RestoreMasked(utils.MaskPhone, req.CustomerPhone, customer.CustomerPhone))
+		if err != nil {
+			common.FailWithMsg(ctx, "客户"+err.Error())
+			return
+		}
+		phone = normalized
+		updateData["customer_phone"] = phone
 	}
-	if req.CustomerStatus != "" {
-		updateData["customer_status"] = req.CustomerStatus
+
+	// 联系人的电话同样处理
+	if req.Contacts != nil {
+		if err := utils.RestoreMaskedContactPhones(global.JY_DB, customer.ID, *req.Contacts); err != nil {
+			common.FailWithMsg(ctx, "查询失败")
+			return
+		}
+	}
+
+	var tags []string
+	if req.Tags != nil {
+		var err error
+		if tags, err = utils.NormalizeCustomerTags(*req.Tags); err != nil {
+			common.FailWithMsg(ctx, err.Error())
+			return
+		}
 	}
 
-	if err := global.JY_DB.Model(&customer).Updates(updateData).Error; err != nil {
+	// 只在名称、电话、联系人有变化时查重，避免修改其他信息时被已存在的重复客户拦住
+	if !req.IgnoreDuplicates && (name != customer.CustomerName || phone != customer.CustomerPhone || req.Contacts != nil) {
+		var contacts []business.CustomerContact
+		if req.Contacts != nil {
+			contacts = *req.Contacts
+		}
+		duplicates, err := utils.FindCustomerDuplicates(global.JY_DB.WithContext(ctx), customer.TenantID, customer.ID, name, utils.CustomerPhones(phone, contacts))
+		if err != nil {
+			common.FailWithMsg(ctx, "查重失败")
+			return
+		}
+		if len(duplicates) > 0 {
+			utils.Mask(ctx, duplicates)
+			common.FailWithDetailed(ctx, duplicates, "存在疑似重复的客户")
+			return
+		}
+	}
+
+	// saveErr 为自定义字段、联系人、地址的校验错误，直接返回给前端
+	var saveErr error
+	err := global.JY_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(updateData) > 0 {
+			if err := tx.Model(&customer).Updates(updateData).Error; err != nil {
+				return err
+			}
+		}
+		if req.Tags != nil {
+			if err := tx.Model(&customer).Select("tags").Updates(business.Customer{Tags: tags}).Error; err != nil {
+				return err
+			}
+		}
+		if len(req.CustomFields) > 0 {
+			if saveErr = utils.SaveCustomerFieldValues(tx, customer, req.CustomFields, false); saveErr != nil {
+				return saveErr
+			}
+		}
+		if req.Contacts != nil {
+			if saveErr = utils.SaveCustomerContacts(tx, customer, *req.Contacts); saveErr != nil {
+				return saveErr
+			}
+		}
+		if req.Addresses != nil {
+			saveErr = utils.SaveCustomerAddresses(tx, customer, *req.Addresses)
+		}
+		return saveErr
+	})
+	if saveErr != nil {
+		common.FailWithMsg(ctx, saveErr.Error())
+		return
+	}
+	if err != nil {
 		common.FailWithMsg(ctx, "更新失败")
 		return
 	}
 
 	// 重新查询更新后的数据
-	global.JY_DB.First(&customer, req.ID)
+	customer, _ = loadCustomerDetail(global.JY_DB.WithContext(ctx), req.ID)
 
+	utils.Mask(ctx, &customer)
 	common.OkWithDetailed(ctx, customer, "更新成功")
 }
@@ -0,0 +1,89 @@
+package customer
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+type SaveViewRequest struct {
+	Name        string                 `json:"name" binding:"required"` // 视图名称
+	AuthorityId string                 `json:"authorityId"`             // 共享的角色ID，为空时仅自己可见
+	Query       business.CustomerQuery `json:"query"`                   // 查询条件，格式同客户查询接口
+	Sort        int                    `json:"sort"`                    // 排序标记
+}
+
+// CreateView 保存客户查询视图
+// @Summary      保存客户查询视图
+// @Description  保存常用的查询条件和排序，之后在客户列表、客户查询、导出客户时传 viewId 使用。共享给角色后该角色的用户都可以使用，条件中的 me 对每个用户表示其本人
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Accept       json
+// @Produce      json
+// @Param        data  body      SaveViewRequest  true  "视图名称, 共享的角色ID, 查询条件, 排序标记"
+// @Success      200   {object}  common.Response{data=business.CustomerView,msg=string}  "保存成功"
+// @Router       /customer/view [post]
+func (c *Api) CreateView(ctx *gin.Context) {
+	var req SaveViewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+	if err := checkViewRequest(ctx, &req); err != nil {
+		common.FailWithMsg(ctx, err.Error())
+		return
+	}
+
+	var count int64
+	if err := global.JY_DB.WithContext(ctx).Model(&business.CustomerView{}).Where("created_by = ?", waitClaims.ID).Count(&count).Error; err != nil {
+		common.FailWithMsg(ctx, "保存失败")
+		return
+	}
+	if count >= utils.CustomerMaxViews {
+		common.FailWithMsg(ctx, fmt.Sprintf("每个用户最多保存%d个视图", utils.CustomerMaxViews))
+		return
+	}
+
+	view := business.CustomerView{
+		TenantID:    waitClaims.TenantId,
+		CreatedBy:   waitClaims.ID,
+		Name:        req.Name,
+		AuthorityId: req.AuthorityId,
+		Query:       req.Query,
+		Sort:        req.Sort,
+	}
+	if err := global.JY_DB.WithContext(ctx).Create(&view).Error; err != nil {
+		common.FailWithMsg(ctx, "保存失败")
+		return
+	}
+	common.OkWithDetailed(ctx, view, "保存成功")
+}
+
+// checkViewRequest 检查视图名称、共享的角色和查询条件
+func checkViewRequest(ctx *gin.Context, req *SaveViewRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || utf8.RuneCountInString(req.Name) > 64 {
+		return fmt.Errorf("视图名称不能为空且不能超过64个字")
+	}
+	if req.AuthorityId != "" {
+		var total int64
+		if err := global.JY_DB.WithContext(ctx).Model(&system.SysAuthority{}).Where("authority_id = ?", req.AuthorityId).Count(&total).Error; err != nil || total == 0 {
+			return fmt.Errorf("角色 %s 不存在", req.AuthorityId)
+		}
+	}
+	_, err := utils.CustomerQueryScope(ctx, req.Query, true)
+	return err
+}
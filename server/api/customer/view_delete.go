@@ -0,0 +1,44 @@
+package customer
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// DeleteView 删除客户查询视图
+// @Summary      删除客户查询视图
+// @Description  删除视图，只有创建人可以删除，共享给角色的视图删除后该角色的用户也无法再使用
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        id   path      int  true  "视图ID"
+// @Success      200  {object}  common.Response{msg=string}  "删除成功"
+// @Router       /customer/view/{id} [delete]
+func (c *Api) DeleteView(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	if id == 0 {
+		common.FailWithMsg(ctx, "视图ID不能为空")
+		return
+	}
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+
+	result := global.JY_DB.WithContext(ctx).Where("id = ? AND created_by = ?", id, claims.(*utils.CustomClaims).ID).Delete(&business.CustomerView{})
+	if result.Error != nil {
+		common.FailWithMsg(ctx, "删除失败")
+		return
+	}
+	if result.RowsAffected == 0 {
+		common.FailWithMsg(ctx, "视图不存在或不是自己创建的")
+		return
+	}
+	common.OkWithMsg(ctx, "删除成功")
+}
@@ -0,0 +1,55 @@
+package customer
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// GetViewList 获取客户查询视图列表
+// @Summary      获取客户查询视图列表
+// @Description  获取自己保存的视图和共享给当前角色的视图，按排序标记排序。createdBy 为当前用户的视图可以修改和删除
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Success      200  {object}  common.Response{data=[]business.CustomerView,msg=string}  "获取成功"
+// @Router       /customer/view/list [get]
+func (c *Api) GetViewList(ctx *gin.Context) {
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	var views []business.CustomerView
+	err := global.JY_DB.WithContext(ctx).
+		Where("created_by = ? OR (authority_id <> '' AND authority_id = ?)", waitClaims.ID, waitClaims.AuthorityId).
+		Order("sort ASC, id ASC").Find(&views).Error
+	if err != nil {
+		common.FailWithMsg(ctx, "获取视图失败")
+		return
+	}
+	common.OkWithDetailed(ctx, views, "获取成功")
+}
+
+// findCustomerView 获取当前用户可以使用的视图：自己保存的或共享给当前角色的
+func findCustomerView(ctx *gin.Context, id uint) (business.CustomerView, error) {
+	var view business.CustomerView
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		return view, errors.New("获取用户信息失败")
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+	err := global.JY_DB.WithContext(ctx).
+		Where("created_by = ? OR (authority_id <> '' AND authority_id = ?)", waitClaims.ID, waitClaims.AuthorityId).
+		First(&view, id).Error
+	if err != nil {
+		return view, errors.New("视图不存在")
+	}
+	return view, nil
+}
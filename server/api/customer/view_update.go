@@ -0,0 +1,57 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type UpdateViewRequest struct {
+	ID uint `json:"id" binding:"required"` // 视图ID
+	SaveViewRequest
+}
+
+// UpdateView 修改客户查询视图
+// @Summary      修改客户查询视图
+// @Description  修改视图的名称、共享的角色、查询条件和排序标记，只有创建人可以修改
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Accept       json
+// @Produce      json
+// @Param        data  body      UpdateViewRequest  true  "视图ID, 视图名称, 共享的角色ID, 查询条件, 排序标记"
+// @Success      200   {object}  common.Response{data=business.CustomerView,msg=string}  "修改成功"
+// @Router       /customer/view [put]
+func (c *Api) UpdateView(ctx *gin.Context) {
+	var req UpdateViewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+
+	var view business.CustomerView
+	if err := global.JY_DB.WithContext(ctx).Where("created_by = ?", claims.(*utils.CustomClaims).ID).First(&view, req.ID).Error; err != nil {
+		common.FailWithMsg(ctx, "视图不存在或不是自己创建的")
+		return
+	}
+	if err := checkViewRequest(ctx, &req.SaveViewRequest); err != nil {
+		common.FailWithMsg(ctx, err.Error())
+		return
+	}
+
+	view.Name = req.Name
+	view.AuthorityId = req.AuthorityId
+	view.Query = req.Query
+	view.Sort = req.Sort
+	if err := global.JY_DB.WithContext(ctx).Save(&view).Error; err != nil {
+		common.FailWithMsg(ctx, "修改失败")
+		return
+	}
+	common.OkWithDetailed(ctx, view, "修改成功")
+}
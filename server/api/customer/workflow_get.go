@@ -0,0 +1,46 @@
+package customer
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type WorkflowRequest struct {
+	TenantID uint `json:"tenantId" form:"tenantId"` // 租户ID，仅超级管理员可用于查看指定租户的流程
+}
+
+// GetWorkflow 获取客户状态流程
+// @Summary      获取客户状态流程
+// @Description  获取当前租户的客户状态和流转规则，租户未配置时返回内置的默认流程
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Produce      json
+// @Param        data  query     WorkflowRequest  false  "租户ID"
+// @Success      200   {object}  common.Response{data=business.CustomerWorkflow,msg=string}  "获取成功"
+// @Router       /customer/workflow [get]
+func (c *Api) GetWorkflow(ctx *gin.Context) {
+	var req WorkflowRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		common.FailWithMsg(ctx, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+	tenantId := waitClaims.TenantId
+	if req.TenantID != 0 && waitClaims.AuthorityId == "888" {
+		tenantId = req.TenantID
+	}
+
+	workflow, err := utils.GetCustomerWorkflow(global.JY_DB, tenantId)
+	if err != nil {
+		common.FailWithMsg(ctx, "获取客户状态流程失败")
+		return
+	}
+	common.OkWithDetailed(ctx, workflow, "获取成功")
+}
@@ -0,0 +1,78 @@
+package customer
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type UpdateWorkflowRequest struct {
+	TenantID    uint                          `json:"tenantId"`                    // 租户ID，不填时为当前租户
+	Statuses    []business.WorkflowStatus     `json:"statuses" binding:"required"` // 状态
+	Transitions []business.WorkflowTransition `json:"transitions"`                 // 流转规则
+}
+
+// UpdateWorkflow 保存客户状态流程
+// @Summary      保存客户状态流程
+// @Description  整体保存租户的客户状态和流转规则，仅超级管理员可用。流转规则可限制执行的角色、要求填写原因，以及要求进入目标状态前填写指定的自定义字段。
+// @Description  仍有客户处于某个状态时不能删除该状态；修改初始状态只影响之后新建的客户
+// @Security     ApiKeyAuth
+// @Tags         Customer
+// @Accept       json
+// @Produce      json
+// @Param        data  body      UpdateWorkflowRequest  true  "租户ID, 状态, 流转规则"
+// @Success      200   {object}  common.Response{data=business.CustomerWorkflow,msg=string}  "保存成功"
+// @Router       /customer/workflow [put]
+func (c *Api) UpdateWorkflow(ctx *gin.Context) {
+	if !utils.CheckSuperAdmin(ctx) {
+		return
+	}
+	var req UpdateWorkflowRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(ctx, "绑定失败")
+		return
+	}
+	if req.TenantID == 0 {
+		claims, _ := ctx.Get("claims")
+		req.TenantID = claims.(*utils.CustomClaims).TenantId
+	}
+
+	workflow, err := utils.GetCustomerWorkflow(global.JY_DB, req.TenantID)
+	if err != nil {
+		common.FailWithMsg(ctx, "获取客户状态流程失败")
+		return
+	}
+	oldStatuses := workflow.Statuses
+	workflow.Statuses = req.Statuses
+	workflow.Transitions = req.Transitions
+	if workflow.Transitions == nil {
+		workflow.Transitions = []business.WorkflowTransition{}
+	}
+	if err = utils.ValidateCustomerWorkflow(global.JY_DB, workflow); err != nil {
+		common.FailWithMsg(ctx, err.Error())
+		return
+	}
+
+	// 删除的状态不能仍有客户使用
+	for _, old := range oldStatuses {
+		if utils.WorkflowHasStatus(workflow, old.Code) {
+			continue
+		}
+		var count int64
+		global.JY_DB.Model(&business.Customer{}).Where(&business.Customer{TenantID: req.TenantID, CustomerStatus: old.Code}).Count(&count)
+		if count > 0 {
+			common.FailWithMsg(ctx, fmt.Sprintf("还有%d个客户处于%s状态，不能删除该状态", count, old.Name))
+			return
+		}
+	}
+
+	if err = global.JY_DB.Save(&workflow).Error; err != nil {
+		common.FailWithMsg(ctx, "保存失败")
+		return
+	}
+	common.OkWithDetailed(ctx, workflow, "保存成功")
+}
@@ -0,0 +1,48 @@
+package dept
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+)
+
+// CreateDept 创建部门
+// @Summary      创建部门
+// @Description  创建部门
+// @Security     ApiKeyAuth
+// @Tags         Dept
+// @Accept       json
+// @Produce      json
+// @Param        data  body      system.SysDept  true  "父部门ID, 部门名称, 负责人ID, 排序"
+// @Success      200   {object}  common.Response{data=system.SysDept,msg=string}  "创建成功"
+// @Router       /dept [post]
+func (a *Api) CreateDept(c *gin.Context) {
+	var dept system.SysDept
+	err := c.ShouldBindJSON(&dept)
+	if err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+	if dept.Name == "" {
+		common.FailWithMsg(c, "部门名称不能为空")
+		return
+	}
+
+	// 检查父部门是否存在
+	if dept.ParentId != 0 {
+		var parentCount int64
+		global.JY_DB.WithContext(c).Model(&system.SysDept{}).Where("id = ?", dept.ParentId).Count(&parentCount)
+		if parentCount == 0 {
+			common.FailWithMsg(c, "父部门不存在")
+			return
+		}
+	}
+
+	err = global.JY_DB.WithContext(c).Create(&dept).Error
+	if err != nil {
+		common.FailWithMsg(c, "创建部门失败")
+		return
+	}
+	common.OkWithDetailed(c, dept, "创建成功")
+}
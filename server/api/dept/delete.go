@@ -0,0 +1,48 @@
+package dept
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+)
+
+// DeleteDept 删除部门
+// @Summary      删除部门
+// @Description  删除部门（会检查是否有下级部门和部门成员）
+// @Security     ApiKeyAuth
+// @Tags         Dept
+// @Produce      json
+// @Param        id   path      int  true  "部门ID"
+// @Success      200  {object}  common.Response{msg=string}  "删除成功"
+// @Router       /dept/{id} [delete]
+func (a *Api) DeleteDept(c *gin.Context) {
+	deptId := c.Param("id")
+	if deptId == "" {
+		common.FailWithMsg(c, "部门ID不能为空")
+		return
+	}
+
+	// 检查是否有下级部门
+	var childCount int64
+	global.JY_DB.WithContext(c).Model(&system.SysDept{}).Where("parent_id = ?", deptId).Count(&childCount)
+	if childCount > 0 {
+		common.FailWithMsg(c, "该部门下存在下级部门，无法删除")
+		return
+	}
+
+	// 检查是否有用户属于该部门
+	var userCount int64
+	global.JY_DB.WithContext(c).Model(&system.SysUser{}).Where("dept_id = ?", deptId).Count(&userCount)
+	if userCount > 0 {
+		common.FailWithMsg(c, "该部门下存在用户，无法删除")
+		return
+	}
+
+	err := global.JY_DB.WithContext(c).Where("id = ?", deptId).Delete(&system.SysDept{}).Error
+	if err != nil {
+		common.FailWithMsg(c, "删除部门失败")
+		return
+	}
+	common.OkWithMsg(c, "删除成功")
+}
@@ -0,0 +1,4 @@
+package dept
+
+type Api struct {
+}
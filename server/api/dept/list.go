@@ -0,0 +1,57 @@
+package dept
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+)
+
+// GetDeptList 获取部门列表
+// @Summary      获取部门列表
+// @Description  获取当前租户的全部部门（平铺结构）
+// @Security     ApiKeyAuth
+// @Tags         Dept
+// @Produce      json
+// @Success      200  {object}  common.Response{data=[]system.SysDept,msg=string}  "获取成功"
+// @Router       /dept/list [get]
+func (a *Api) GetDeptList(c *gin.Context) {
+	var depts []system.SysDept
+	err := global.JY_DB.WithContext(c).Order("sort ASC").Find(&depts).Error
+	if err != nil {
+		common.FailWithMsg(c, "获取部门列表失败")
+		return
+	}
+	common.OkWithData(c, depts)
+}
+
+// GetDeptTree 获取部门树
+// @Summary      获取部门树
+// @Description  获取当前租户的部门树
+// @Security     ApiKeyAuth
+// @Tags         Dept
+// @Produce      json
+// @Success      200  {object}  common.Response{data=[]system.SysDept,msg=string}  "获取成功"
+// @Router       /dept/tree [get]
+func (a *Api) GetDeptTree(c *gin.Context) {
+	var depts []system.SysDept
+	err := global.JY_DB.WithContext(c).Order("sort ASC").Find(&depts).Error
+	if err != nil {
+		common.FailWithMsg(c, "获取部门树失败")
+		return
+	}
+	common.OkWithData(c, buildDeptTree(depts, 0, map[uint]bool{}))
+}
+
+// buildDeptTree 构建部门树
+func buildDeptTree(depts []system.SysDept, parentId uint, visited map[uint]bool) []system.SysDept {
+	tree := []system.SysDept{}
+	for _, dept := range depts {
+		if dept.ParentId == parentId && !visited[dept.ID] {
+			visited[dept.ID] = true
+			dept.Children = buildDeptTree(depts, dept.ID, visited)
+			tree = append(tree, dept)
+		}
+	}
+	return tree
+}
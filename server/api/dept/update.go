@@ -0,0 +1,63 @@
+package dept
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+// UpdateDept 更新部门
+// @Summary      更新部门
+// @Description  更新部门（不允许把部门移动到自身或下级部门下）
+// @Security     ApiKeyAuth
+// @Tags         Dept
+// @Accept       json
+// @Produce      json
+// @Param        data  body      system.SysDept  true  "部门ID, 父部门ID, 部门名称, 负责人ID, 排序, 状态"
+// @Success      200   {object}  common.Response{msg=string}  "更新成功"
+// @Router       /dept [put]
+func (a *Api) UpdateDept(c *gin.Context) {
+	var dept system.SysDept
+	err := c.ShouldBindJSON(&dept)
+	if err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+	if dept.ID == 0 {
+		common.FailWithMsg(c, "部门ID不能为空")
+		return
+	}
+
+	if dept.ParentId != 0 {
+		var parentCount int64
+		global.JY_DB.WithContext(c).Model(&system.SysDept{}).Where("id = ?", dept.ParentId).Count(&parentCount)
+		if parentCount == 0 {
+			common.FailWithMsg(c, "父部门不存在")
+			return
+		}
+		// 父部门不能是自身或下级部门，否则部门树会形成环
+		for _, id := range utils.DeptAndChildIds(dept.ID) {
+			if id == dept.ParentId {
+				common.FailWithMsg(c, "父部门不能是自身或下级部门")
+				return
+			}
+		}
+	}
+
+	// 使用 map 更新，确保 enable 字段（即使是 false）也能正确更新
+	updateData := map[string]interface{}{
+		"parent_id": dept.ParentId,
+		"name":      dept.Name,
+		"leader_id": dept.LeaderId,
+		"sort":      dept.Sort,
+		"enable":    dept.Enable,
+	}
+	err = global.JY_DB.WithContext(c).Model(&system.SysDept{}).Where("id = ?", dept.ID).Updates(updateData).Error
+	if err != nil {
+		common.FailWithMsg(c, "更新部门失败")
+		return
+	}
+	common.OkWithMsg(c, "更新成功")
+}
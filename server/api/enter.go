@@ -2,10 +2,16 @@ package api
 
 import (
 	"jiangyi.com/api/ai"
+	"jiangyi.com/api/audit"
 	"jiangyi.com/api/authority"
 	"jiangyi.com/api/customer"
+	"jiangyi.com/api/dept"
 	"jiangyi.com/api/login"
 	"jiangyi.com/api/menu"
+	"jiangyi.com/api/preference"
+	"jiangyi.com/api/setup"
+	"jiangyi.com/api/tenant"
+	"jiangyi.com/api/trash"
 	"jiangyi.com/api/upload"
 	"jiangyi.com/api/user"
 )
@@ -13,11 +19,17 @@ import (
 var ApiGroup = new(Api)
 
 type Api struct {
-	CustomerApi  customer.Api
-	UserApi      user.Api
-	UploadApi    upload.Api
-	LoginApi     login.Api
-	AuthorityApi authority.Api
-	MenuApi      menu.Api
-	AIApi        ai.Api
+	CustomerApi   customer.Api
+	UserApi       user.Api
+	UploadApi     upload.Api
+	LoginApi      login.Api
+	AuthorityApi  authority.Api
+	MenuApi       menu.Api
+	AIApi         ai.Api
+	TenantApi     tenant.Api
+	DeptApi       dept.Api
+	TrashApi      trash.Api
+	PreferenceApi preference.Api
+	SetupApi      setup.Api
+	AuditApi      audit.Api
 }
@@ -115,6 +115,18 @@ func (l *Api) Login(ctx *gin.Context) {
 		return
 	}
 
+	// 检查租户状态，租户被禁用时该租户下的用户都不允许登录
+	var tenant system.SysTenant
+	if err = global.JY_DB.Where("id = ?", user.TenantId).First(&tenant).Error; err == nil && !tenant.Enable {
+		global.JY_LOG.Warn("登录失败：租户已被禁用",
+			zap.String("username", params.Username),
+			zap.String("ip", key),
+			zap.Uint("tenant_id", user.TenantId),
+		)
+		common.FailWithMsg(ctx, "所属租户已被禁用，无法登录")
+		return
+	}
+
 	// 注意：角色禁用不影响登录，只影响菜单权限
 	// 角色禁用时，用户仍可登录，但获取菜单时会返回空菜单（在 getMenusByAuthorityId 中处理）
 
@@ -125,6 +137,9 @@ func (l *Api) Login(ctx *gin.Context) {
 		Username:    user.Username,
 		NickName:    user.NickName,
 		AuthorityId: user.AuthorityId,
+		TenantId:    user.TenantId,
+
+		MustChangePassword: user.MustChangePassword,
 	})
 	token, err := j.CreateToken(claims)
 	if err != nil {
@@ -147,9 +162,11 @@ func (l *Api) Login(ctx *gin.Context) {
 		zap.String("nick_name", user.NickName),
 	)
 
+	// 需要修改密码时返回的 token 只能用于修改密码和登出，前端应跳转到修改密码页面
 	common.OkWithDetailed(ctx, gin.H{
-		"user":      user,
-		"token":     token,
-		"expiresAt": claims.RegisteredClaims.ExpiresAt.Unix() * 1000,
+		"user":               user,
+		"token":              token,
+		"expiresAt":          claims.RegisteredClaims.ExpiresAt.Unix() * 1000,
+		"mustChangePassword": user.MustChangePassword,
 	}, "登录成功")
 }
@@ -5,6 +5,7 @@ import (
 	"jiangyi.com/global"
 	"jiangyi.com/model/common"
 	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
 )
 
 // CreateMenu 创建菜单
@@ -30,6 +31,22 @@ func (a *Api) CreateMenu(c *gin.Context) {
 		menu.ParentId = "0"
 	}
 
+	if err := utils.ValidateMenuParameters(menu.Parameters); err != nil {
+		common.FailWithMsg(c, err.Error())
+		return
+	}
+
+	// 校验父菜单和层级
+	var menus []system.SysBaseMenu
+	global.JY_DB.Find(&menus)
+	levels, err := utils.ValidateMenuTree(append(menus, menu))
+	if err != nil {
+		common.FailWithMsg(c, err.Error())
+		return
+	}
+	menu.MenuLevel = levels[menu.ID]
+
+	// 多语言标题和路由参数随菜单一起创建
 	err = global.JY_DB.Create(&menu).Error
 	if err != nil {
 		common.FailWithMsg(c, "创建菜单失败")
@@ -2,6 +2,7 @@ package menu
 
 import (
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"jiangyi.com/global"
 	"jiangyi.com/model/common"
 	"jiangyi.com/model/system"
@@ -40,7 +41,15 @@ func (a *Api) DeleteMenu(c *gin.Context) {
 		return
 	}
 
-	err := global.JY_DB.Delete(&system.SysBaseMenu{}, menuId).Error
+	err := global.JY_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("sys_base_menu_id = ?", menuId).Delete(&system.SysBaseMenuI18n{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("sys_base_menu_id = ?", menuId).Delete(&system.SysBaseMenuParameter{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&system.SysBaseMenu{}, menuId).Error
+	})
 	if err != nil {
 		common.FailWithMsg(c, "删除菜单失败")
 		return
@@ -0,0 +1,49 @@
+package menu
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// ExportMenus 导出菜单
+// @Summary      导出菜单
+// @Description  导出完整菜单树和角色菜单关联，父子关系使用路径表示，可导入到其他环境
+// @Security     ApiKeyAuth
+// @Tags         Menu
+// @Produce      octet-stream
+// @Param        format  query     string  false  "导出格式: json(默认), yaml"
+// @Success      200     {file}    file    "菜单文件"
+// @Router       /menu/export [get]
+func (a *Api) ExportMenus(c *gin.Context) {
+	if !utils.CheckSuperAdmin(c) {
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format == "yml" {
+		format = "yaml"
+	}
+
+	data, err := utils.ExportMenus(global.JY_DB.WithContext(c))
+	if err != nil {
+		common.FailWithMsg(c, "导出菜单失败")
+		return
+	}
+	content, err := utils.MarshalMenuExport(data, format)
+	if err != nil {
+		common.FailWithMsg(c, err.Error())
+		return
+	}
+
+	contentType := "application/json"
+	if format == "yaml" {
+		contentType = "application/x-yaml"
+	}
+	fileName := fmt.Sprintf("menus-%s.%s", time.Now().Format("20060102150405"), format)
+	c.Header("Content-Disposition", "attachment; filename="+fileName)
+	c.Data(200, contentType, content)
+}
@@ -0,0 +1,55 @@
+package menu
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// ImportMenus 导入菜单
+// @Summary      导入菜单
+// @Description  导入由导出接口生成的 json/yaml 菜单文件，按菜单路径匹配，已存在则更新，不存在则创建，可重复导入
+// @Security     ApiKeyAuth
+// @Tags         Menu
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file  true  "菜单文件(.json, .yaml, .yml)"
+// @Success      200   {object}  common.Response{data=utils.MenuImportResult,msg=string}  "导入成功"
+// @Router       /menu/import [post]
+func (a *Api) ImportMenus(c *gin.Context) {
+	if !utils.CheckSuperAdmin(c) {
+		return
+	}
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		common.FailWithMsg(c, "未接收到文件")
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		common.FailWithMsg(c, "读取文件失败")
+		return
+	}
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(header.Filename), "."))
+	data, err := utils.UnmarshalMenuExport(content, format)
+	if err != nil {
+		common.FailWithMsg(c, "解析菜单文件失败: "+err.Error())
+		return
+	}
+
+	result, err := utils.ImportMenus(global.JY_DB.WithContext(c), data)
+	if err != nil {
+		global.JY_LOG.Error("导入菜单失败", zap.Error(err))
+		common.FailWithMsg(c, "导入菜单失败: "+err.Error())
+		return
+	}
+	common.OkWithDetailed(c, result, "导入成功")
+}
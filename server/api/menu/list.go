@@ -25,23 +25,25 @@ type MenuTreeItem struct {
 // @Router       /menu/list [get]
 func (a *Api) GetMenuList(c *gin.Context) {
 	var menus []system.SysBaseMenu
-	err := global.JY_DB.Order("sort ASC").Find(&menus).Error
+	err := global.JY_DB.Preload("I18n").Preload("Parameters").Order("sort ASC").Find(&menus).Error
 	if err != nil {
 		common.FailWithMsg(c, "获取菜单列表失败")
 		return
 	}
 
 	// 构建树形结构
-	treeMenus := buildMenuTree(menus, "0")
+	treeMenus := buildMenuTree(menus, "0", map[uint]bool{})
 	common.OkWithData(c, treeMenus)
 }
 
 // buildMenuTree 构建菜单树
-func buildMenuTree(menus []system.SysBaseMenu, parentId string) []MenuTreeItem {
+// visited 记录已加入树中的菜单，防止脏数据中的循环引用导致无限递归
+func buildMenuTree(menus []system.SysBaseMenu, parentId string, visited map[uint]bool) []MenuTreeItem {
 	var tree []MenuTreeItem
 	for _, menu := range menus {
-		if menu.ParentId == parentId {
-			children := buildMenuTree(menus, fmt.Sprintf("%d", menu.ID))
+		if menu.ParentId == parentId && !visited[menu.ID] {
+			visited[menu.ID] = true
+			children := buildMenuTree(menus, fmt.Sprintf("%d", menu.ID), visited)
 			tree = append(tree, MenuTreeItem{
 				SysBaseMenu: menu,
 				Children:    children,
@@ -2,4 +2,3 @@ package menu
 
 type Api struct {
 }
-
@@ -0,0 +1,51 @@
+package menu
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+type MoveMenusRequest struct {
+	Menus []utils.MenuMove `json:"menus" binding:"required,dive"` // 菜单移动项
+}
+
+// MoveMenus 批量移动菜单
+// @Summary      批量移动菜单
+// @Description  批量调整菜单的父菜单和排序，整体校验（父菜单存在、无循环、不超过最大层级）通过后一次性生效，并重新计算菜单层级
+// @Security     ApiKeyAuth
+// @Tags         Menu
+// @Accept       json
+// @Produce      json
+// @Param        data  body      MoveMenusRequest  true  "菜单ID, 父菜单ID, 排序"
+// @Success      200   {object}  common.Response{data=[]MenuTreeItem,msg=string}  "移动成功"
+// @Router       /menu/move [post]
+func (a *Api) MoveMenus(c *gin.Context) {
+	var req MoveMenusRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+	if len(req.Menus) == 0 {
+		common.FailWithMsg(c, "移动的菜单不能为空")
+		return
+	}
+
+	_, err = utils.MoveMenus(global.JY_DB, req.Menus)
+	if err != nil {
+		common.FailWithMsg(c, err.Error())
+		return
+	}
+
+	// 返回移动后的菜单树，方便前端直接刷新
+	var menus []system.SysBaseMenu
+	err = global.JY_DB.Preload("I18n").Preload("Parameters").Order("sort ASC").Find(&menus).Error
+	if err != nil {
+		common.FailWithMsg(c, "获取菜单列表失败")
+		return
+	}
+	common.OkWithDetailed(c, buildMenuTree(menus, "0", map[uint]bool{}), "移动成功")
+}
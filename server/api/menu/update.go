@@ -1,10 +1,14 @@
 package menu
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"jiangyi.com/global"
 	"jiangyi.com/model/common"
 	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
 )
 
 // UpdateMenu 更新菜单
@@ -30,15 +34,22 @@ func (a *Api) UpdateMenu(c *gin.Context) {
 		return
 	}
 
+	if err := utils.ValidateMenuParameters(menu.Parameters); err != nil {
+		common.FailWithMsg(c, err.Error())
+		return
+	}
+	if menu.ParentId == "" {
+		menu.ParentId = "0"
+	}
+
 	// 使用 map 更新，确保 enable 字段（即使是 false）也能正确更新
 	// 注意：meta 字段是 embedded，字段名直接作为列名（snake_case）
+	// 父菜单和排序通过 MoveMenus 更新，其中会校验循环引用并重新计算层级
 	updateData := map[string]interface{}{
-		"parent_id":    menu.ParentId,
 		"path":         menu.Path,
 		"name":         menu.Name,
 		"hidden":       menu.Hidden,
 		"component":    menu.Component,
-		"sort":         menu.Sort,
 		"enable":       menu.Enable,
 		"title":        menu.Title,
 		"icon":         menu.Icon,
@@ -46,9 +57,28 @@ func (a *Api) UpdateMenu(c *gin.Context) {
 		"keep_alive":   menu.KeepAlive,
 		"default_menu": menu.DefaultMenu,
 	}
-	err = global.JY_DB.Model(&system.SysBaseMenu{}).Where("id = ?", menu.ID).Updates(updateData).Error
+	err = global.JY_DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := utils.MoveMenus(tx, []utils.MenuMove{{ID: menu.ID, ParentId: menu.ParentId, Sort: menu.Sort}}); err != nil {
+			return err
+		}
+		if err := tx.Model(&system.SysBaseMenu{}).Where("id = ?", menu.ID).Updates(updateData).Error; err != nil {
+			return errors.New("更新菜单失败")
+		}
+		// 未传 i18n、parameters 字段时保持不变，传空数组时清空
+		if menu.I18n != nil {
+			if err := utils.ReplaceMenuI18n(tx, menu.ID, menu.I18n); err != nil {
+				return errors.New("更新菜单多语言标题失败")
+			}
+		}
+		if menu.Parameters != nil {
+			if err := utils.ReplaceMenuParameters(tx, menu.ID, menu.Parameters); err != nil {
+				return errors.New("更新菜单路由参数失败")
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		common.FailWithMsg(c, "更新菜单失败")
+		common.FailWithMsg(c, err.Error())
 		return
 	}
 	common.OkWithDetailed(c, menu, "更新成功")
@@ -0,0 +1,60 @@
+package preference
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+type CreateDefinitionRequest struct {
+	Key          string          `json:"key" binding:"required"`          // 设置项标识
+	Name         string          `json:"name" binding:"required"`         // 设置项名称
+	Schema       json.RawMessage `json:"schema" binding:"required"`       // 取值规则（JSON Schema）
+	DefaultValue json.RawMessage `json:"defaultValue" binding:"required"` // 默认值
+	Remark       string          `json:"remark"`                          // 备注
+	Sort         int             `json:"sort"`                            // 排序标记
+}
+
+// CreateDefinition 创建偏好设置项
+// @Summary      创建偏好设置项
+// @Description  创建偏好设置项，默认值必须符合取值规则，仅超级管理员可用
+// @Security     ApiKeyAuth
+// @Tags         Preference
+// @Accept       json
+// @Produce      json
+// @Param        data  body      CreateDefinitionRequest  true  "设置项标识, 名称, 取值规则, 默认值, 备注, 排序"
+// @Success      200   {object}  common.Response{data=system.SysPreferenceDefinition,msg=string}  "创建成功"
+// @Router       /preference/definition [post]
+func (a *Api) CreateDefinition(c *gin.Context) {
+	if !utils.CheckSuperAdmin(c) {
+		return
+	}
+	var req CreateDefinitionRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+	definition := system.SysPreferenceDefinition{
+		Key:          req.Key,
+		Name:         req.Name,
+		Schema:       req.Schema,
+		DefaultValue: req.DefaultValue,
+		Remark:       req.Remark,
+		Sort:         req.Sort,
+	}
+	if err = utils.ValidatePreferenceDefinition(definition); err != nil {
+		common.FailWithMsg(c, err.Error())
+		return
+	}
+	err = global.JY_DB.WithContext(c).Create(&definition).Error
+	if err != nil {
+		common.FailWithMsg(c, "设置项标识重复")
+		return
+	}
+	common.OkWithDetailed(c, definition, "创建成功")
+}
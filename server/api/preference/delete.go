@@ -0,0 +1,52 @@
+package preference
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+// DeleteDefinition 删除偏好设置项
+// @Summary      删除偏好设置项
+// @Description  删除偏好设置项及所有用户保存的该项设置值，仅超级管理员可用
+// @Security     ApiKeyAuth
+// @Tags         Preference
+// @Produce      json
+// @Param        id   path      int  true  "设置项ID"
+// @Success      200  {object}  common.Response{msg=string}  "删除成功"
+// @Router       /preference/definition/{id} [delete]
+func (a *Api) DeleteDefinition(c *gin.Context) {
+	if !utils.CheckSuperAdmin(c) {
+		return
+	}
+	id, _ := strconv.Atoi(c.Param("id"))
+	if id == 0 {
+		common.FailWithMsg(c, "设置项ID不能为空")
+		return
+	}
+
+	var definition system.SysPreferenceDefinition
+	err := global.JY_DB.WithContext(c).Where("id = ?", id).First(&definition).Error
+	if err != nil {
+		common.FailWithMsg(c, "设置项不存在")
+		return
+	}
+
+	// 设置项标识唯一，直接物理删除以便之后重新创建同名设置项
+	err = global.JY_DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(&system.SysUserPreference{Key: definition.Key}).Delete(&system.SysUserPreference{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&definition).Error
+	})
+	if err != nil {
+		common.FailWithMsg(c, "删除设置项失败")
+		return
+	}
+	common.OkWithMsg(c, "删除成功")
+}
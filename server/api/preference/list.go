@@ -0,0 +1,26 @@
+package preference
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+)
+
+// GetDefinitionList 获取偏好设置项列表
+// @Summary      获取偏好设置项列表
+// @Description  获取全部偏好设置项的定义（名称、取值规则、默认值），前端据此渲染个人设置页面
+// @Security     ApiKeyAuth
+// @Tags         Preference
+// @Produce      json
+// @Success      200  {object}  common.Response{data=[]system.SysPreferenceDefinition,msg=string}  "获取成功"
+// @Router       /preference/definition/list [get]
+func (a *Api) GetDefinitionList(c *gin.Context) {
+	var definitions []system.SysPreferenceDefinition
+	err := global.JY_DB.WithContext(c).Order("sort ASC, id ASC").Find(&definitions).Error
+	if err != nil {
+		common.FailWithMsg(c, "获取列表失败")
+		return
+	}
+	common.OkWithData(c, definitions)
+}
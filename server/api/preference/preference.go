@@ -0,0 +1,4 @@
+package preference
+
+type Api struct {
+}
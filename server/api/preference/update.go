@@ -0,0 +1,72 @@
+package preference
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+type UpdateDefinitionRequest struct {
+	ID           uint            `json:"ID" binding:"required"`           // 设置项ID
+	Name         string          `json:"name" binding:"required"`         // 设置项名称
+	Schema       json.RawMessage `json:"schema" binding:"required"`       // 取值规则（JSON Schema）
+	DefaultValue json.RawMessage `json:"defaultValue" binding:"required"` // 默认值
+	Remark       string          `json:"remark"`                          // 备注
+	Sort         int             `json:"sort"`                            // 排序标记
+}
+
+// UpdateDefinition 更新偏好设置项
+// @Summary      更新偏好设置项
+// @Description  更新偏好设置项的名称、取值规则和默认值，设置项标识不能修改，仅超级管理员可用。用户已保存的值不再符合新规则时按默认值返回
+// @Security     ApiKeyAuth
+// @Tags         Preference
+// @Accept       json
+// @Produce      json
+// @Param        data  body      UpdateDefinitionRequest  true  "设置项ID, 名称, 取值规则, 默认值, 备注, 排序"
+// @Success      200   {object}  common.Response{msg=string}  "更新成功"
+// @Router       /preference/definition [put]
+func (a *Api) UpdateDefinition(c *gin.Context) {
+	if !utils.CheckSuperAdmin(c) {
+		return
+	}
+	var req UpdateDefinitionRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+
+	var definition system.SysPreferenceDefinition
+	err = global.JY_DB.WithContext(c).Where("id = ?", req.ID).First(&definition).Error
+	if err != nil {
+		common.FailWithMsg(c, "设置项不存在")
+		return
+	}
+	definition.Name = req.Name
+	definition.Schema = req.Schema
+	definition.DefaultValue = req.DefaultValue
+	definition.Remark = req.Remark
+	definition.Sort = req.Sort
+	if err = utils.ValidatePreferenceDefinition(definition); err != nil {
+		common.FailWithMsg(c, err.Error())
+		return
+	}
+
+	updateData := map[string]interface{}{
+		"name":          definition.Name,
+		"schema":        definition.Schema,
+		"default_value": definition.DefaultValue,
+		"remark":        definition.Remark,
+		"sort":          definition.Sort,
+	}
+	err = global.JY_DB.WithContext(c).Model(&system.SysPreferenceDefinition{}).Where("id = ?", definition.ID).Updates(updateData).Error
+	if err != nil {
+		common.FailWithMsg(c, "更新设置项失败")
+		return
+	}
+	common.OkWithMsg(c, "更新成功")
+}
@@ -0,0 +1,58 @@
+package setup
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type InitRequest struct {
+	SetupToken string   `json:"setupToken"`                  // 初始化令牌，启动时输出到日志，本机访问时可不填
+	Username   string   `json:"username" binding:"required"` // 管理员用户名
+	Password   string   `json:"password" binding:"required"` // 管理员密码
+	NickName   string   `json:"nickName"`                    // 管理员昵称，默认为“超级管理员”
+	Seeds      []string `json:"seeds"`                       // 需要写入的默认数据：roles-默认角色，menus-内置菜单
+}
+
+// InitSystem 初始化系统
+// @Summary      初始化系统
+// @Description  首次启动时创建超级管理员角色和管理员账号，并按选择写入默认数据（roles-默认角色，menus-内置菜单）。
+// @Description  只能从本机访问或携带启动日志中输出的一次性初始化令牌，初始化完成后该接口永久关闭
+// @Tags         Setup
+// @Accept       json
+// @Produce      json
+// @Param        data  body      InitRequest  true  "初始化令牌, 管理员用户名, 密码, 昵称, 默认数据"
+// @Success      200   {object}  common.Response{data=utils.SetupResult,msg=string}  "初始化成功"
+// @Router       /setup [post]
+func (a *Api) InitSystem(c *gin.Context) {
+	if !utils.SetupRequired() {
+		common.FailWithMsg(c, "系统已初始化")
+		return
+	}
+	var req InitRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+	if !utils.CheckSetupAccess(c.RemoteIP(), req.SetupToken) {
+		global.JY_LOG.Warn("初始化令牌错误", zap.String("ip", c.RemoteIP()))
+		common.FailWithMsg(c, "初始化令牌错误，非本机访问需要提供启动日志中的初始化令牌")
+		return
+	}
+
+	result, err := utils.RunSetup(global.JY_DB, utils.SetupOptions{
+		Username: req.Username,
+		Password: req.Password,
+		NickName: req.NickName,
+		Seeds:    req.Seeds,
+	})
+	if err != nil {
+		common.FailWithMsg(c, err.Error())
+		return
+	}
+	global.JY_LOG.Info("系统初始化完成", zap.String("admin", result.Admin.Username), zap.Strings("seeds", req.Seeds))
+	common.OkWithDetailed(c, result, "初始化成功")
+}
@@ -0,0 +1,4 @@
+package setup
+
+type Api struct {
+}
@@ -0,0 +1,31 @@
+package setup
+
+import (
+	"net"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// SetupStatus 系统初始化状态
+type SetupStatus struct {
+	Initialized bool `json:"initialized"` // 是否已初始化
+	NeedToken   bool `json:"needToken"`   // 初始化是否需要令牌（非本机访问时需要）
+}
+
+// GetSetupStatus 获取系统初始化状态
+// @Summary      获取系统初始化状态
+// @Description  获取系统是否已完成初始化，前端据此决定是否展示初始化页面
+// @Tags         Setup
+// @Produce      json
+// @Success      200  {object}  common.Response{data=SetupStatus,msg=string}  "获取成功"
+// @Router       /setup/status [get]
+func (a *Api) GetSetupStatus(c *gin.Context) {
+	initialized := !utils.SetupRequired()
+	ip := net.ParseIP(c.RemoteIP())
+	common.OkWithData(c, SetupStatus{
+		Initialized: initialized,
+		NeedToken:   !initialized && (ip == nil || !ip.IsLoopback()),
+	})
+}
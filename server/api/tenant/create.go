@@ -0,0 +1,49 @@
+package tenant
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+type CreateTenantRequest struct {
+	Name   string `json:"name" binding:"required"` // 租户名称
+	Code   string `json:"code" binding:"required"` // 租户编码
+	Remark string `json:"remark"`                  // 备注
+}
+
+// CreateTenant 创建租户
+// @Summary      创建租户
+// @Description  创建租户，仅超级管理员可用
+// @Security     ApiKeyAuth
+// @Tags         Tenant
+// @Accept       json
+// @Produce      json
+// @Param        data  body      CreateTenantRequest  true  "租户名称, 租户编码, 备注"
+// @Success      200   {object}  common.Response{data=system.SysTenant,msg=string}  "创建成功"
+// @Router       /tenant [post]
+func (a *Api) CreateTenant(c *gin.Context) {
+	if !utils.CheckSuperAdmin(c) {
+		return
+	}
+	var req CreateTenantRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+	tenant := system.SysTenant{
+		Name:   req.Name,
+		Code:   req.Code,
+		Remark: req.Remark,
+		Enable: true,
+	}
+	err = global.JY_DB.Create(&tenant).Error
+	if err != nil {
+		common.FailWithMsg(c, "租户名称或编码重复")
+		return
+	}
+	common.OkWithDetailed(c, tenant, "创建成功")
+}
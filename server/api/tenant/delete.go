@@ -0,0 +1,50 @@
+package tenant
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+// DeleteTenant 删除租户
+// @Summary      删除租户
+// @Description  删除租户（会检查租户下是否还有用户），仅超级管理员可用
+// @Security     ApiKeyAuth
+// @Tags         Tenant
+// @Produce      json
+// @Param        id   path      int  true  "租户ID"
+// @Success      200  {object}  common.Response{msg=string}  "删除成功"
+// @Router       /tenant/{id} [delete]
+func (a *Api) DeleteTenant(c *gin.Context) {
+	if !utils.CheckSuperAdmin(c) {
+		return
+	}
+	id, _ := strconv.Atoi(c.Param("id"))
+	if id == 0 {
+		common.FailWithMsg(c, "租户ID不能为空")
+		return
+	}
+	if uint(id) == system.DefaultTenantId {
+		common.FailWithMsg(c, "默认租户不能删除")
+		return
+	}
+
+	// 检查租户下是否还有用户
+	var userCount int64
+	global.JY_DB.Model(&system.SysUser{}).Where("tenant_id = ?", id).Count(&userCount)
+	if userCount > 0 {
+		common.FailWithMsg(c, "该租户下存在用户，无法删除")
+		return
+	}
+
+	err := global.JY_DB.Delete(&system.SysTenant{}, id).Error
+	if err != nil {
+		common.FailWithMsg(c, "删除租户失败")
+		return
+	}
+	common.OkWithMsg(c, "删除成功")
+}
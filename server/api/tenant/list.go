@@ -0,0 +1,30 @@
+package tenant
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+// GetTenantList 获取租户列表
+// @Summary      获取租户列表
+// @Description  获取租户列表，仅超级管理员可用
+// @Security     ApiKeyAuth
+// @Tags         Tenant
+// @Produce      json
+// @Success      200  {object}  common.Response{data=[]system.SysTenant,msg=string}  "获取成功"
+// @Router       /tenant/list [get]
+func (a *Api) GetTenantList(c *gin.Context) {
+	if !utils.CheckSuperAdmin(c) {
+		return
+	}
+	var tenants []system.SysTenant
+	err := global.JY_DB.Order("id ASC").Find(&tenants).Error
+	if err != nil {
+		common.FailWithMsg(c, "获取列表失败")
+		return
+	}
+	common.OkWithData(c, tenants)
+}
@@ -0,0 +1,4 @@
+package tenant
+
+type Api struct {
+}
@@ -0,0 +1,51 @@
+package tenant
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+// UpdateTenant 更新租户
+// @Summary      更新租户
+// @Description  更新租户名称、备注和状态，仅超级管理员可用。禁用后该租户下的用户无法登录
+// @Security     ApiKeyAuth
+// @Tags         Tenant
+// @Accept       json
+// @Produce      json
+// @Param        data  body      system.SysTenant  true  "租户ID, 租户名称, 备注, 状态"
+// @Success      200   {object}  common.Response{msg=string}  "更新成功"
+// @Router       /tenant [put]
+func (a *Api) UpdateTenant(c *gin.Context) {
+	if !utils.CheckSuperAdmin(c) {
+		return
+	}
+	var tenant system.SysTenant
+	err := c.ShouldBindJSON(&tenant)
+	if err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+	if tenant.ID == 0 {
+		common.FailWithMsg(c, "租户ID不能为空")
+		return
+	}
+	if tenant.ID == system.DefaultTenantId && !tenant.Enable {
+		common.FailWithMsg(c, "默认租户不能禁用")
+		return
+	}
+	// 使用 map 更新，确保 enable 字段（即使是 false）也能正确更新
+	updateData := map[string]interface{}{
+		"name":   tenant.Name,
+		"remark": tenant.Remark,
+		"enable": tenant.Enable,
+	}
+	err = global.JY_DB.Model(&system.SysTenant{}).Where("id = ?", tenant.ID).Updates(updateData).Error
+	if err != nil {
+		common.FailWithMsg(c, "更新租户失败")
+		return
+	}
+	common.OkWithMsg(c, "更新成功")
+}
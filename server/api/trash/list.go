@@ -0,0 +1,61 @@
+package trash
+
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type SearchTrash struct {
+	Page     int `json:"page" form:"page"`
+	PageSize int `json:"pageSize" form:"pageSize"`
+}
+
+// GetTrashList 获取回收站列表
+// @Summary      获取回收站列表
+// @Description  分页获取已删除的记录，按删除时间倒序。resource: user（仅超级管理员）, customer, file, aiConversation
+// @Security     ApiKeyAuth
+// @Tags         Trash
+// @Produce      json
+// @Param        resource  path      string       true  "资源类型"
+// @Param        data      query     SearchTrash  true  "页码, 每页大小"
+// @Success      200       {object}  common.Response{data=common.PageResult{list=[]utils.TrashItem},msg=string}  "获取成功"
+// @Router       /trash/{resource}/list [get]
+func (a *Api) GetTrashList(c *gin.Context) {
+	resource, ok := getResource(c)
+	if !ok {
+		return
+	}
+	var search SearchTrash
+	_ = c.ShouldBindQuery(&search)
+	if search.Page == 0 {
+		search.Page = 1
+	}
+	if search.PageSize == 0 {
+		search.PageSize = 10
+	}
+
+	db := global.JY_DB.WithContext(c).Unscoped().Model(resource.Model).Where("deleted_at IS NOT NULL")
+	if scope := ownerScope(c, resource); scope != nil {
+		db = db.Scopes(scope)
+	}
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		common.FailWithMsg(c, "统计失败")
+		return
+	}
+	list := resource.NewList()
+	err := db.Order("deleted_at DESC").Limit(search.PageSize).Offset((search.Page - 1) * search.PageSize).Find(list).Error
+	if err != nil {
+		common.FailWithMsg(c, "获取列表失败")
+		return
+	}
+	utils.Mask(c, list)
+	common.OkWithData(c, common.PageResult{
+		List:     utils.TrashItems(list),
+		Total:    total,
+		Page:     search.Page,
+		PageSize: search.PageSize,
+	})
+}
@@ -0,0 +1,36 @@
+package trash
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// PurgeTrash 彻底删除回收站中的记录
+// @Summary      彻底删除回收站中的记录
+// @Description  彻底删除回收站中的记录，无法恢复。删除文件时会同时删除对象存储中的文件，删除AI会话时会同时删除会话消息
+// @Security     ApiKeyAuth
+// @Tags         Trash
+// @Accept       json
+// @Produce      json
+// @Param        resource  path      string           true  "资源类型"
+// @Param        data      body      TrashIdsRequest  true  "记录ID"
+// @Success      200       {object}  common.Response{data=utils.TrashResult,msg=string}  "删除成功"
+// @Router       /trash/{resource}/purge [post]
+func (a *Api) PurgeTrash(c *gin.Context) {
+	resource, ok := getResource(c)
+	if !ok {
+		return
+	}
+	var req TrashIdsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+
+	result := utils.PurgeTrash(global.JY_DB.WithContext(c), resource, req.Ids, ownerScope(c, resource))
+	common.OkWithDetailed(c, result, fmt.Sprintf("成功删除%d条", result.Success))
+}
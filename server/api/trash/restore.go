@@ -0,0 +1,36 @@
+package trash
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// RestoreTrash 恢复回收站中的记录
+// @Summary      恢复回收站中的记录
+// @Description  恢复已删除的记录，逐条处理并返回失败原因。恢复用户时昵称已被占用会自动重命名，用户名已被占用则无法恢复
+// @Security     ApiKeyAuth
+// @Tags         Trash
+// @Accept       json
+// @Produce      json
+// @Param        resource  path      string           true  "资源类型"
+// @Param        data      body      TrashIdsRequest  true  "记录ID"
+// @Success      200       {object}  common.Response{data=utils.TrashResult,msg=string}  "恢复成功"
+// @Router       /trash/{resource}/restore [post]
+func (a *Api) RestoreTrash(c *gin.Context) {
+	resource, ok := getResource(c)
+	if !ok {
+		return
+	}
+	var req TrashIdsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+
+	result := utils.RestoreTrash(global.JY_DB.WithContext(c), resource, req.Ids, ownerScope(c, resource))
+	common.OkWithDetailed(c, result, fmt.Sprintf("成功恢复%d条", result.Success))
+}
@@ -0,0 +1,47 @@
+package trash
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+type Api struct {
+}
+
+// TrashIdsRequest 恢复、彻底删除的记录ID
+type TrashIdsRequest struct {
+	Ids []uint `json:"ids" binding:"required"` // 记录ID
+}
+
+// getResource 根据路径参数获取回收站资源，不支持或当前用户无权操作时直接返回失败响应
+func getResource(c *gin.Context) (utils.TrashResource, bool) {
+	resource, ok := utils.TrashResources[c.Param("resource")]
+	if !ok {
+		common.FailWithMsg(c, "不支持的资源类型: "+c.Param("resource"))
+		return resource, false
+	}
+	if resource.AdminOnly && !utils.CheckSuperAdmin(c) {
+		return resource, false
+	}
+	return resource, true
+}
+
+// ownerScope 当前用户可操作的回收站记录
+// 只能操作自己记录的资源按用户ID过滤，其余资源按角色的数据范围过滤
+func ownerScope(c *gin.Context, resource utils.TrashResource) func(*gorm.DB) *gorm.DB {
+	if resource.OwnerColumn == "" {
+		return nil
+	}
+	if resource.SelfOnly {
+		return func(db *gorm.DB) *gorm.DB {
+			claims, exists := c.Get("claims")
+			if !exists {
+				return db.Where("1 = 0")
+			}
+			return db.Where(resource.OwnerColumn+" = ?", claims.(*utils.CustomClaims).ID)
+		}
+	}
+	return utils.DataScope(c, resource.OwnerColumn)
+}
@@ -1,19 +1,15 @@
 package upload
 
 import (
-	"fmt"
-
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
 	"jiangyi.com/global"
 	"jiangyi.com/model/common"
 	"jiangyi.com/model/system"
-	"jiangyi.com/utils/upload"
 )
 
 // DeleteFile 删除文件
 // @Summary      删除文件
-// @Description  删除文件
+// @Description  删除文件，文件进入回收站，可通过回收站恢复或彻底删除
 // @Security     ApiKeyAuth
 // @Tags         File
 // @Accept       json
@@ -28,35 +24,13 @@ func (u *Api) DeleteFile(c *gin.Context) {
 		return
 	}
 
-	// 使用全局OSS实例
-	if global.JY_OSS == nil {
-		global.JY_LOG.Error("OSS未初始化")
-		common.FailWithMsg(c, "文件存储服务未初始化")
-		return
-	}
-
-	oss, ok := global.JY_OSS.(upload.OSS)
-	if !ok {
-		global.JY_LOG.Error("OSS类型转换失败", zap.Any("type", fmt.Sprintf("%T", global.JY_OSS)))
-		common.FailWithMsg(c, "文件存储服务类型错误")
-		return
-	}
-
-	if err := global.JY_DB.Where("key = ?", file.Key).First(&file).Error; err != nil {
+	if err := global.JY_DB.WithContext(c).Where("key = ?", file.Key).First(&file).Error; err != nil {
 		common.FailWithMsg(c, "文件不存在，删除失败")
 		return
 	}
-	if err := oss.DeleteFile(file.Key); err != nil {
-		global.JY_LOG.Error("文件删除失败",
-			zap.String("key", file.Key),
-			zap.String("oss_type", global.JY_Config.System.OSSType),
-			zap.Error(err),
-		)
-		common.FailWithError(c, "删除文件失败", err)
-		return
-	}
 
-	if err := global.JY_DB.Delete(&file).Error; err != nil {
+	// 只做软删除，文件进入回收站，对象存储中的文件在回收站彻底删除时再删除
+	if err := global.JY_DB.WithContext(c).Delete(&file).Error; err != nil {
 		common.FailWithMsg(c, "数据库内删除失败")
 		return
 	}
@@ -5,6 +5,7 @@ import (
 	"jiangyi.com/global"
 	"jiangyi.com/model/common"
 	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
 )
 
 type FileListRequest struct {
@@ -15,7 +16,7 @@ type FileListRequest struct {
 
 // GetFileList 获取文件列表
 // @Summary      分页获取文件列表
-// @Description  分页获取文件列表
+// @Description  分页获取文件列表，按当前角色的数据范围过滤
 // @Security     ApiKeyAuth
 // @Tags         File
 // @Accept       json
@@ -39,13 +40,14 @@ func (u *Api) GetFileList(c *gin.Context) {
 	}
 	var files []system.ExaFileUploadAndDownload
 	var count int64
-	err = global.JY_DB.Model(&system.ExaFileUploadAndDownload{}).Where("name LIKE ?", "%"+params.Keyword+"%").Count(&count).Error
+	query := global.JY_DB.WithContext(c).Model(&system.ExaFileUploadAndDownload{}).Scopes(utils.DataScope(c, "created_by")).Where("name LIKE ?", "%"+params.Keyword+"%")
+	err = query.Count(&count).Error
 	if err != nil {
 		common.FailWithMsg(c, "查询失败")
 		return
 	}
 
-	if err = global.JY_DB.Find(&files).Limit(params.PageSize).Offset((params.Page - 1) * params.PageSize).Error; err != nil {
+	if err = query.Limit(params.PageSize).Offset((params.Page - 1) * params.PageSize).Find(&files).Error; err != nil {
 		common.FailWithMsg(c, "查询失败")
 		return
 	}
@@ -12,6 +12,7 @@ import (
 	"jiangyi.com/global"
 	"jiangyi.com/model/common"
 	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
 	"jiangyi.com/utils/upload"
 )
 
@@ -59,21 +60,26 @@ func (u *Api) UploadFile(c *gin.Context) {
 		common.FailWithError(c, "写入oss文件失败", uploadErr)
 		return
 	}
+	var createdBy uint
+	if claims, exists := c.Get("claims"); exists {
+		createdBy = claims.(*utils.CustomClaims).ID
+	}
 	s := strings.Split(header.Filename, ".")
 	file = system.ExaFileUploadAndDownload{
-		Url:     filePath,
-		Name:    header.Filename,
-		ClassId: classId,
-		Tag:     s[len(s)-1],
-		Key:     key,
+		Url:       filePath,
+		Name:      header.Filename,
+		ClassId:   classId,
+		Tag:       s[len(s)-1],
+		Key:       key,
+		CreatedBy: createdBy,
 	}
 
 	// 检查是否已存在相同key的记录
 	var existingFile system.ExaFileUploadAndDownload
-	err = global.JY_DB.Where(&system.ExaFileUploadAndDownload{Key: key}).First(&existingFile).Error
+	err = global.JY_DB.WithContext(c).Where(&system.ExaFileUploadAndDownload{Key: key}).First(&existingFile).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		err = global.JY_DB.Create(&file).Error
+		err = global.JY_DB.WithContext(c).Create(&file).Error
 		if err != nil {
 			common.FailWithMsg(c, "写入数据库失败")
 			return
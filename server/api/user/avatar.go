@@ -0,0 +1,123 @@
+package user
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+	"jiangyi.com/utils/upload"
+)
+
+// UploadAvatar 上传头像
+// @Summary      上传头像
+// @Description  上传当前用户的头像。按文件内容识别真实格式（jpg、png、gif、webp），居中裁剪为正方形并缩放为 256px 头像和 64px 缩略图，
+// @Description  重新编码去除 EXIF 等元数据后保存到文件存储，并删除之前上传的头像文件
+// @Security     ApiKeyAuth
+// @Tags         User
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file  true  "头像图片，不超过5MB"
+// @Success      200   {object}  common.Response{data=system.SysUser,msg=string}  "上传成功"
+// @Router       /user/avatar [post]
+func (a *Api) UploadAvatar(c *gin.Context) {
+	_, header, err := c.Request.FormFile("file")
+	if err != nil {
+		common.FailWithMsg(c, "未接收到文件")
+		return
+	}
+	if header.Size > utils.AvatarMaxSize {
+		common.FailWithMsg(c, fmt.Sprintf("图片大小不能超过%dMB", utils.AvatarMaxSize>>20))
+		return
+	}
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		common.FailWithMsg(c, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	var user system.SysUser
+	err = global.JY_DB.WithContext(c).Where("id = ?", waitClaims.ID).First(&user).Error
+	if err != nil {
+		common.FailWithMsg(c, "用户不存在")
+		return
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		common.FailWithMsg(c, "读取文件失败")
+		return
+	}
+	defer f.Close()
+	images, err := utils.ProcessAvatar(f)
+	if err != nil {
+		common.FailWithMsg(c, err.Error())
+		return
+	}
+
+	oss, ok := global.JY_OSS.(upload.OSS)
+	if !ok {
+		common.FailWithMsg(c, "文件存储服务未初始化")
+		return
+	}
+
+	// 依次上传各个尺寸，任一失败时删除已上传的文件
+	// 文件名使用纳秒时间戳，避免短时间内重复上传时覆盖之前的文件
+	timestamp := time.Now().UnixNano()
+	urls := make([]string, 0, len(images))
+	keys := make([]string, 0, len(images))
+	for _, image := range images {
+		filename := fmt.Sprintf("avatar_%d_%d_%d%s", user.ID, image.Size, timestamp, image.Ext)
+		url, key, uploadErr := oss.UploadReader(filename, bytes.NewReader(image.Data))
+		if uploadErr != nil {
+			global.JY_LOG.Error("头像上传失败", zap.String("filename", filename), zap.Error(uploadErr))
+			deleteAvatarFiles(oss, keys)
+			common.FailWithMsg(c, "头像上传失败")
+			return
+		}
+		urls = append(urls, url)
+		keys = append(keys, key)
+	}
+
+	oldKeys := user.AvatarKey
+	user.HeaderImg = urls[0]
+	user.HeaderThumb = urls[len(urls)-1]
+	user.AvatarKey = strings.Join(keys, ",")
+	err = global.JY_DB.WithContext(c).Model(&user).Updates(map[string]interface{}{
+		"header_img":   user.HeaderImg,
+		"header_thumb": user.HeaderThumb,
+		"avatar_key":   user.AvatarKey,
+	}).Error
+	if err != nil {
+		deleteAvatarFiles(oss, keys)
+		common.FailWithMsg(c, "更新头像失败")
+		return
+	}
+
+	// 删除之前上传的头像文件，失败时只记录日志
+	if oldKeys != "" {
+		deleteAvatarFiles(oss, strings.Split(oldKeys, ","))
+	}
+
+	common.OkWithDetailed(c, user, "上传成功")
+}
+
+// deleteAvatarFiles 删除头像文件，失败时只记录日志
+func deleteAvatarFiles(oss upload.OSS, keys []string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := oss.DeleteFile(key); err != nil {
+			global.JY_LOG.Warn("删除头像文件失败", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
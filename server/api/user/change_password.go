@@ -15,13 +15,13 @@ type ChangePasswordRequest struct {
 
 // ChangePassword 修改密码
 // @Summary      修改用户密码
-// @Description  修改用户密码
+// @Description  修改当前用户的密码并清除必须修改密码的标记。使用必须修改密码时签发的受限 token 调用时，返回新的正常 token
 // @Security     ApiKeyAuth
 // @Tags         User
 // @Accept       json
 // @Produce      json
 // @Param        data  body      ChangePasswordRequest  true  "旧密码, 新密码"
-// @Success      200   {object}  common.Response{msg=string}  "修改成功"
+// @Success      200   {object}  common.Response{data=map[string]interface{},msg=string}  "修改成功"
 // @Router       /user/changePassword [post]
 func (a *Api) ChangePassword(c *gin.Context) {
 	var req ChangePasswordRequest
@@ -40,7 +40,7 @@ func (a *Api) ChangePassword(c *gin.Context) {
 	waitClaims := claims.(*utils.CustomClaims)
 
 	var user system.SysUser
-	err = global.JY_DB.Where("id = ?", waitClaims.ID).First(&user).Error
+	err = global.JY_DB.WithContext(c).Where("id = ?", waitClaims.ID).First(&user).Error
 	if err != nil {
 		common.FailWithMsg(c, "用户不存在")
 		return
@@ -52,13 +52,44 @@ func (a *Api) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	// 更新为新密码
+	if err = utils.ValidatePassword(req.NewPassword); err != nil {
+		common.FailWithMsg(c, err.Error())
+		return
+	}
+	if req.NewPassword == req.OldPassword {
+		common.FailWithMsg(c, "新密码不能与旧密码相同")
+		return
+	}
+
+	// 更新为新密码，并清除必须修改密码的标记
 	user.Password = utils.BcryptHash(req.NewPassword)
-	err = global.JY_DB.Save(&user).Error
+	user.MustChangePassword = false
+	err = global.JY_DB.WithContext(c).Save(&user).Error
 	if err != nil {
 		common.FailWithMsg(c, "修改密码失败")
 		return
 	}
 
+	// 使用受限 token 修改密码后，签发新的正常 token，无需重新登录
+	if waitClaims.MustChangePassword {
+		newClaims := utils.CreateClaims(utils.CustomClaims{
+			ID:          user.ID,
+			Username:    user.Username,
+			NickName:    user.NickName,
+			AuthorityId: user.AuthorityId,
+			TenantId:    user.TenantId,
+		})
+		token, err := utils.NewJWT().CreateToken(newClaims)
+		if err != nil {
+			common.FailWithMsg(c, "修改密码成功，请重新登录")
+			return
+		}
+		common.OkWithDetailed(c, gin.H{
+			"token":     token,
+			"expiresAt": newClaims.RegisteredClaims.ExpiresAt.Unix() * 1000,
+		}, "修改密码成功")
+		return
+	}
+
 	common.OkWithMsg(c, "修改密码成功")
 }
@@ -8,25 +8,46 @@ import (
 	"jiangyi.com/utils"
 )
 
+// CreateUserRequest 创建用户请求，SysUser 的密码字段不参与 JSON 序列化，这里单独接收
+type CreateUserRequest struct {
+	system.SysUser
+	Password string `json:"password" binding:"required"` // 初始密码
+}
+
 // CreateUser 创建用户
 // @Summary      创建用户
-// @Description  创建用户
+// @Description  创建用户，用户首次登录后必须修改密码
 // @Security     ApiKeyAuth
 // @Tags         User
 // @Accept       json
 // @Produce      json
-// @Param        data  body      system.SysUser   true  "用户名, 昵称, 头像, 角色ID"
+// @Param        data  body      CreateUserRequest  true  "用户名, 初始密码, 昵称, 角色ID"
 // @Success      200   {object}  common.Response{msg=string}  "创建成功"
 // @Router       /user [post]
 func (a *Api) CreateUser(c *gin.Context) {
-	var user system.SysUser
-	err := c.ShouldBindJSON(&user)
+	var req CreateUserRequest
+	err := c.ShouldBindJSON(&req)
 	if err != nil {
 		common.FailWithMsg(c, "参数绑定失败")
 		return
 	}
-	user.Password = utils.BcryptHash(user.Password)
-	err = global.JY_DB.Create(&user).Error
+	if err = utils.ValidatePassword(req.Password); err != nil {
+		common.FailWithMsg(c, err.Error())
+		return
+	}
+	user := req.SysUser
+	// 未指定角色时数据库默认为 888
+	if user.AuthorityId == "" {
+		user.AuthorityId = "888"
+	}
+	if !canAssignAuthority(c, user.AuthorityId) {
+		common.FailWithMsg(c, "只有超级管理员可以分配超级管理员角色")
+		return
+	}
+	user.Password = utils.BcryptHash(req.Password)
+	// 管理员设置的是临时密码，用户首次登录后必须修改
+	user.MustChangePassword = true
+	err = global.JY_DB.WithContext(c).Create(&user).Error
 	if err != nil {
 		common.FailWithMsg(c, "用户名重复")
 		return
@@ -1,6 +1,8 @@
 package user
 
 import (
+	"encoding/json"
+
 	"github.com/gin-gonic/gin"
 	"jiangyi.com/global"
 	"jiangyi.com/model/common"
@@ -8,14 +10,20 @@ import (
 	"jiangyi.com/utils"
 )
 
+// CurrentUserInfo 当前用户信息，包含用户的偏好设置
+type CurrentUserInfo struct {
+	system.SysUser
+	Preferences map[string]json.RawMessage `json:"preferences"` // 偏好设置，未设置的项为默认值
+}
+
 // GetCurrentUser 获取当前用户信息
 // @Summary      获取当前用户信息
-// @Description  获取当前登录用户的信息
+// @Description  获取当前登录用户的信息，同时返回用户的偏好设置
 // @Security     ApiKeyAuth
 // @Tags         User
 // @Accept       json
 // @Produce      json
-// @Success      200   {object}  common.Response{data=system.SysUser,msg=string}  "获取成功"
+// @Success      200   {object}  common.Response{data=CurrentUserInfo,msg=string}  "获取成功"
 // @Router       /user/userinfo [get]
 func (a *Api) GetCurrentUser(c *gin.Context) {
 	// 从 JWT claims 中获取当前登录用户的 ID
@@ -27,11 +35,17 @@ func (a *Api) GetCurrentUser(c *gin.Context) {
 	waitClaims := claims.(*utils.CustomClaims)
 
 	var user system.SysUser
-	err := global.JY_DB.Where("id = ?", waitClaims.ID).First(&user).Error
+	err := global.JY_DB.WithContext(c).Where("id = ?", waitClaims.ID).First(&user).Error
 	if err != nil {
 		common.FailWithMsg(c, "用户不存在")
 		return
 	}
 
-	common.OkWithDetailed(c, user, "获取成功")
+	preferences, err := utils.GetUserPreferences(global.JY_DB, user.ID)
+	if err != nil {
+		common.FailWithMsg(c, "获取偏好设置失败")
+		return
+	}
+
+	common.OkWithDetailed(c, CurrentUserInfo{SysUser: user, Preferences: preferences}, "获取成功")
 }
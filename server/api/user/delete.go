@@ -21,7 +21,7 @@ import (
 // @Router       /user/{id} [delete]
 func (a *Api) DeleteUser(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	err := global.JY_DB.Delete(&system.SysUser{}, id).Error
+	err := global.JY_DB.WithContext(c).Delete(&system.SysUser{}, id).Error
 	if err != nil {
 		common.FailWithMsg(c, "删除用户失败")
 		return
@@ -0,0 +1,81 @@
+package user
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// userSheetHeader 用户导出表头，导入时使用相同的表头
+var userSheetHeader = []string{"ID", "username", "nickName", "authorityId", "authorityName", "deptId", "enable", "createdAt"}
+
+// ExportUsers 导出用户
+// @Summary      导出用户
+// @Description  按用户列表的筛选和排序条件导出用户，支持 CSV 和 XLSX
+// @Security     ApiKeyAuth
+// @Tags         User
+// @Produce      octet-stream
+// @Param        format  query     string      false  "导出格式: csv(默认), xlsx"
+// @Param        data    query     SearchUser  false  "筛选条件, 排序"
+// @Success      200     {file}    file        "用户文件"
+// @Router       /user/export [get]
+func (a *Api) ExportUsers(c *gin.Context) {
+	var search SearchUser
+	if err := c.ShouldBindQuery(&search); err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+	format := c.DefaultQuery("format", utils.SheetCSV)
+	if format != utils.SheetCSV && format != utils.SheetXLSX {
+		common.FailWithMsg(c, "不支持的格式: "+format)
+		return
+	}
+
+	rows, err := userListQuery(c, search).Select("sys_users.*, sys_authorities.authority_name").Order(userListOrder(search)).Rows()
+	if err != nil {
+		common.FailWithMsg(c, "导出用户失败")
+		return
+	}
+	defer rows.Close()
+
+	fileName := fmt.Sprintf("users-%s.%s", time.Now().Format("20060102150405"), format)
+	c.Header("Content-Disposition", "attachment; filename="+fileName)
+	c.Header("Content-Type", utils.SheetContentType(format))
+	writer, err := utils.NewSheetWriter(c.Writer, format)
+	if err != nil {
+		common.FailWithMsg(c, "导出用户失败")
+		return
+	}
+
+	// 逐行读取写出，避免一次性加载全部用户
+	err = writer.WriteRow(userSheetHeader)
+	for err == nil && rows.Next() {
+		var user UserListItem
+		if err = global.JY_DB.ScanRows(rows, &user); err != nil {
+			break
+		}
+		err = writer.WriteRow([]string{
+			strconv.Itoa(int(user.ID)),
+			user.Username,
+			user.NickName,
+			user.AuthorityId,
+			user.AuthorityName,
+			strconv.Itoa(int(user.DeptId)),
+			strconv.FormatBool(user.Enable),
+			user.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		// 响应已经开始写出，只能记录日志
+		global.JY_LOG.Error("导出用户失败", zap.Error(err))
+	}
+}
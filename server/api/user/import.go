@@ -0,0 +1,266 @@
+package user
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+// userImportColumns 导入表头与字段的对应关系，同时支持导出的表头和中文表头
+var userImportColumns = map[string]string{
+	"username":    "username",
+	"用户名":         "username",
+	"nickname":    "nickName",
+	"昵称":          "nickName",
+	"password":    "password",
+	"密码":          "password",
+	"authorityid": "authorityId",
+	"角色id":        "authorityId",
+	"deptid":      "deptId",
+	"部门id":        "deptId",
+	"enable":      "enable",
+	"状态":          "enable",
+}
+
+// userImportMaxRows 单次导入的最大行数
+const userImportMaxRows = 1000
+
+// UserImportRow 导入结果中的一行
+type UserImportRow struct {
+	Row      int      `json:"row"`                // 文件中的行号（表头为第1行）
+	Username string   `json:"username"`           // 用户名
+	Password string   `json:"password,omitempty"` // 未填写密码时自动生成的密码，仅返回这一次
+	Errors   []string `json:"errors"`             // 校验错误，为空表示该行有效
+}
+
+// UserImportResult 导入结果
+type UserImportResult struct {
+	DryRun  bool            `json:"dryRun"`  // 是否仅校验
+	Total   int             `json:"total"`   // 数据行数
+	Success int             `json:"success"` // 校验通过的行数
+	Failed  int             `json:"failed"`  // 校验失败的行数
+	Created int             `json:"created"` // 实际创建的用户数
+	Rows    []UserImportRow `json:"rows"`
+}
+
+// ImportUsers 导入用户
+// @Summary      导入用户
+// @Description  从 CSV/XLSX 批量导入用户，逐行校验用户名和昵称唯一、角色有效、密码策略，未填写密码时自动生成。
+// @Description  所有行校验通过才会在一个事务中创建，dryRun=true 时只返回校验结果
+// @Security     ApiKeyAuth
+// @Tags         User
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file    formData  file    true   "用户文件(.csv, .xlsx)，表头: username, nickName, password, authorityId, deptId, enable"
+// @Param        dryRun  formData  bool    false  "仅校验不导入"
+// @Success      200     {object}  common.Response{data=UserImportResult,msg=string}  "导入成功"
+// @Router       /user/import [post]
+func (a *Api) ImportUsers(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		common.FailWithMsg(c, "未接收到文件")
+		return
+	}
+	defer file.Close()
+	dryRun, _ := strconv.ParseBool(c.DefaultPostForm("dryRun", "false"))
+
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(header.Filename), "."))
+	rows, err := utils.ReadSheet(file, format)
+	if err != nil {
+		common.FailWithMsg(c, "读取文件失败: "+err.Error())
+		return
+	}
+	if len(rows) < 2 {
+		common.FailWithMsg(c, "文件中没有数据")
+		return
+	}
+	if len(rows)-1 > userImportMaxRows {
+		common.FailWithMsg(c, fmt.Sprintf("单次最多导入%d个用户", userImportMaxRows))
+		return
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		if field, ok := userImportColumns[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[field] = i
+		}
+	}
+	for _, field := range []string{"username", "nickName", "authorityId"} {
+		if _, ok := columns[field]; !ok {
+			common.FailWithMsg(c, "缺少必需的列: "+field)
+			return
+		}
+	}
+
+	result, users := validateImportUsers(c, rows[1:], columns)
+	result.DryRun = dryRun
+	if dryRun || result.Failed > 0 {
+		// 预览时不返回自动生成的密码，正式导入时会重新生成
+		for i := range result.Rows {
+			result.Rows[i].Password = ""
+		}
+		msg := "校验完成"
+		if result.Failed > 0 {
+			msg = fmt.Sprintf("%d行校验失败，未导入任何用户", result.Failed)
+		}
+		common.OkWithDetailed(c, result, msg)
+		return
+	}
+
+	// 校验全部通过后再计算密码哈希
+	for i := range users {
+		users[i].Password = utils.BcryptHash(users[i].Password)
+	}
+	err = global.JY_DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		for i := range users {
+			// enable 字段有数据库默认值，创建时 false 会被默认值覆盖，需要单独更新
+			enable := users[i].Enable
+			if err := tx.Create(&users[i]).Error; err != nil {
+				return fmt.Errorf("第%d行 %s 创建失败: %v", result.Rows[i].Row, users[i].Username, err)
+			}
+			if !enable {
+				if err := tx.Model(&users[i]).Update("enable", false).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		global.JY_LOG.Error("导入用户失败", zap.Error(err))
+		common.FailWithMsg(c, "导入用户失败: "+err.Error())
+		return
+	}
+	result.Created = len(users)
+	common.OkWithDetailed(c, result, "导入成功")
+}
+
+// validateImportUsers 逐行校验导入数据，返回校验结果和待创建的用户（与结果行一一对应）
+func validateImportUsers(c *gin.Context, rows [][]string, columns map[string]int) (UserImportResult, []system.SysUser) {
+	result := UserImportResult{Total: len(rows), Rows: make([]UserImportRow, 0, len(rows))}
+	users := make([]system.SysUser, 0, len(rows))
+	db := global.JY_DB.WithContext(c)
+
+	cell := func(row []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	authorities := make(map[string]bool)
+	usernames := make(map[string]int)
+	nickNames := make(map[string]int)
+	for i, row := range rows {
+		line := UserImportRow{Row: i + 2, Username: cell(row, "username"), Errors: []string{}}
+		user := system.SysUser{
+			Username:    line.Username,
+			NickName:    cell(row, "nickName"),
+			AuthorityId: cell(row, "authorityId"),
+			Enable:      true,
+
+			MustChangePassword: true, // 导入的密码为临时密码，首次登录后必须修改
+		}
+
+		// 用户名：必填，文件内和数据库内唯一，登录时不区分租户，需要与所有租户和已删除的用户比较
+		if user.Username == "" {
+			line.Errors = append(line.Errors, "用户名不能为空")
+		} else if first, ok := usernames[user.Username]; ok {
+			line.Errors = append(line.Errors, fmt.Sprintf("用户名与第%d行重复", first))
+		} else {
+			usernames[user.Username] = line.Row
+			var count int64
+			global.JY_DB.Unscoped().Model(&system.SysUser{}).Where("username = ?", user.Username).Count(&count)
+			if count > 0 {
+				line.Errors = append(line.Errors, "用户名已存在")
+			}
+		}
+
+		// 昵称：必填，唯一索引包含已删除的用户
+		if user.NickName == "" {
+			line.Errors = append(line.Errors, "昵称不能为空")
+		} else if first, ok := nickNames[user.NickName]; ok {
+			line.Errors = append(line.Errors, fmt.Sprintf("昵称与第%d行重复", first))
+		} else {
+			nickNames[user.NickName] = line.Row
+			var count int64
+			global.JY_DB.Unscoped().Model(&system.SysUser{}).Where("nick_name = ?", user.NickName).Count(&count)
+			if count > 0 {
+				line.Errors = append(line.Errors, "昵称已存在")
+			}
+		}
+
+		// 角色：必须存在于当前租户，且只有超级管理员可以分配超级管理员角色
+		if user.AuthorityId == "" {
+			line.Errors = append(line.Errors, "角色ID不能为空")
+		} else {
+			exists, ok := authorities[user.AuthorityId]
+			if !ok {
+				var count int64
+				db.Model(&system.SysAuthority{}).Where("authority_id = ?", user.AuthorityId).Count(&count)
+				exists = count > 0
+				authorities[user.AuthorityId] = exists
+			}
+			if !exists {
+				line.Errors = append(line.Errors, "角色不存在")
+			} else if !canAssignAuthority(c, user.AuthorityId) {
+				line.Errors = append(line.Errors, "只有超级管理员可以分配超级管理员角色")
+			}
+		}
+
+		// 部门：选填，必须存在
+		if deptId := cell(row, "deptId"); deptId != "" && deptId != "0" {
+			id, err := strconv.Atoi(deptId)
+			var count int64
+			if err == nil {
+				db.Model(&system.SysDept{}).Where("id = ?", id).Count(&count)
+			}
+			if count == 0 {
+				line.Errors = append(line.Errors, "部门不存在")
+			}
+			user.DeptId = uint(id)
+		}
+
+		// 状态：选填，默认启用
+		if enable := cell(row, "enable"); enable != "" {
+			switch strings.ToLower(enable) {
+			case "1", "true", "是", "启用":
+				user.Enable = true
+			case "0", "false", "否", "禁用":
+				user.Enable = false
+			default:
+				line.Errors = append(line.Errors, "状态只能是 true 或 false")
+			}
+		}
+
+		// 密码：填写时校验密码策略，未填写时自动生成
+		password := cell(row, "password")
+		if password == "" {
+			password = utils.RandomPassword(12)
+			line.Password = password
+		} else if err := utils.ValidatePassword(password); err != nil {
+			line.Errors = append(line.Errors, err.Error())
+		}
+
+		if len(line.Errors) > 0 {
+			result.Failed++
+			line.Password = ""
+		} else {
+			result.Success++
+			user.Password = password
+		}
+		result.Rows = append(result.Rows, line)
+		users = append(users, user)
+	}
+	return result, users
+}
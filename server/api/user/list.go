@@ -1,45 +1,113 @@
 package user
 
 import (
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"jiangyi.com/global"
 	"jiangyi.com/model/common"
 	"jiangyi.com/model/system"
 )
 
 type SearchUser struct {
-	Page     int `json:"page" form:"page"`
-	PageSize int `json:"pageSize" form:"pageSize"`
+	Page           int       `json:"page" form:"page"`
+	PageSize       int       `json:"pageSize" form:"pageSize"`
+	Keyword        string    `json:"keyword" form:"keyword"`                                        // 用户名或昵称关键字
+	AuthorityId    string    `json:"authorityId" form:"authorityId"`                                // 角色ID
+	Enable         *bool     `json:"enable" form:"enable"`                                          // 用户状态
+	StartCreatedAt time.Time `json:"startCreatedAt" form:"startCreatedAt" time_format:"2006-01-02"` // 创建时间起（包含）
+	EndCreatedAt   time.Time `json:"endCreatedAt" form:"endCreatedAt" time_format:"2006-01-02"`     // 创建时间止（包含当天）
+	SortField      string    `json:"sortField" form:"sortField"`                                    // 排序字段 ID, username, nickName, authorityId, enable, createdAt, updatedAt
+	SortOrder      string    `json:"sortOrder" form:"sortOrder"`                                    // 排序方向 asc, desc
+}
+
+// UserListItem 用户列表项，附带角色名
+type UserListItem struct {
+	system.SysUser
+	AuthorityName string `json:"authorityName"`
+}
+
+// userSortFields 用户列表允许排序的字段，key 为前端字段名，value 为数据库列
+var userSortFields = map[string]string{
+	"ID":          "sys_users.id",
+	"username":    "sys_users.username",
+	"nickName":    "sys_users.nick_name",
+	"authorityId": "sys_users.authority_id",
+	"enable":      "sys_users.enable",
+	"createdAt":   "sys_users.created_at",
+	"updatedAt":   "sys_users.updated_at",
+}
+
+// userListQuery 根据筛选条件构建用户列表查询，列表和导出共用
+func userListQuery(c *gin.Context, search SearchUser) *gorm.DB {
+	db := global.JY_DB.WithContext(c).Model(&system.SysUser{}).
+		Joins("LEFT JOIN sys_authorities ON sys_authorities.authority_id = sys_users.authority_id AND sys_authorities.deleted_at IS NULL")
+	if keyword := strings.TrimSpace(search.Keyword); keyword != "" {
+		db = db.Where("sys_users.username LIKE ? OR sys_users.nick_name LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+	if search.AuthorityId != "" {
+		db = db.Where("sys_users.authority_id = ?", search.AuthorityId)
+	}
+	if search.Enable != nil {
+		db = db.Where("sys_users.enable = ?", *search.Enable)
+	}
+	if !search.StartCreatedAt.IsZero() {
+		db = db.Where("sys_users.created_at >= ?", search.StartCreatedAt)
+	}
+	if !search.EndCreatedAt.IsZero() {
+		db = db.Where("sys_users.created_at < ?", search.EndCreatedAt.AddDate(0, 0, 1))
+	}
+	return db
+}
+
+// userListOrder 返回白名单内的排序语句，默认按ID升序
+func userListOrder(search SearchUser) string {
+	column, ok := userSortFields[search.SortField]
+	if !ok {
+		return "sys_users.id ASC"
+	}
+	switch strings.ToLower(search.SortOrder) {
+	case "desc", "descend":
+		return column + " DESC"
+	default:
+		return column + " ASC"
+	}
 }
 
 // GetUserList 获取用户列表
 // @Summary      分页获取用户列表
-// @Description  分页获取用户列表
+// @Description  分页获取用户列表，支持按用户名/昵称关键字、角色、状态、创建时间筛选和排序，返回角色名
 // @Security     ApiKeyAuth
 // @Tags         User
 // @Accept       json
 // @Produce      json
-// @Param        data  query     SearchUser       true  "页码, 每页大小"
-// @Success      200   {object}  common.Response{data=common.PageResult,msg=string}  "获取成功"
+// @Param        data  query     SearchUser       true  "页码, 每页大小, 筛选条件, 排序"
+// @Success      200   {object}  common.Response{data=common.PageResult{list=[]UserListItem},msg=string}  "获取成功"
 // @Router       /user/list [get]
 func (a *Api) GetUserList(c *gin.Context) {
 	var search SearchUser
-	_ = c.ShouldBindQuery(&search)
+	if err := c.ShouldBindQuery(&search); err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
 	if search.Page == 0 {
 		search.Page = 1
 	}
 	if search.PageSize == 0 {
 		search.PageSize = 10
 	}
-	var users []system.SysUser
+	var users []UserListItem
 	var total int64
-	db := global.JY_DB.Model(&system.SysUser{})
+	db := userListQuery(c, search)
 	err := db.Count(&total).Error
 	if err != nil {
 		common.FailWithMsg(c, "统计失败")
 		return
 	}
-	err = db.Limit(search.PageSize).Offset((search.Page - 1) * search.PageSize).Find(&users).Error
+	err = db.Select("sys_users.*, sys_authorities.authority_name").Order(userListOrder(search)).
+		Limit(search.PageSize).Offset((search.Page - 1) * search.PageSize).Find(&users).Error
 	if err != nil {
 		common.FailWithMsg(c, "获取列表失败")
 		return
@@ -0,0 +1,76 @@
+package user
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// GetPreferences 获取当前用户的偏好设置
+// @Summary      获取当前用户的偏好设置
+// @Description  获取当前登录用户的全部偏好设置（语言、时区、主题、默认每页条数、通知设置等），未设置的项返回管理员配置的默认值
+// @Security     ApiKeyAuth
+// @Tags         User
+// @Produce      json
+// @Success      200   {object}  common.Response{data=map[string]interface{},msg=string}  "获取成功"
+// @Router       /user/preferences [get]
+func (a *Api) GetPreferences(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		common.FailWithMsg(c, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	preferences, err := utils.GetUserPreferences(global.JY_DB, waitClaims.ID)
+	if err != nil {
+		common.FailWithMsg(c, "获取偏好设置失败")
+		return
+	}
+	common.OkWithDetailed(c, preferences, "获取成功")
+}
+
+// UpdatePreferences 修改当前用户的偏好设置
+// @Summary      修改当前用户的偏好设置
+// @Description  只修改请求中包含的设置项，值为 null 时恢复默认值。设置值需符合管理员配置的取值规则，任一项不合法时整体不修改
+// @Security     ApiKeyAuth
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Param        data  body      map[string]interface{}  true  "设置项标识 -> 设置值，如 {\"theme\":\"dark\",\"pageSize\":20}"
+// @Success      200   {object}  common.Response{data=map[string]interface{},msg=string}  "修改成功"
+// @Router       /user/preferences [patch]
+func (a *Api) UpdatePreferences(c *gin.Context) {
+	var req map[string]json.RawMessage
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		common.FailWithMsg(c, "参数绑定失败")
+		return
+	}
+	if len(req) == 0 {
+		common.FailWithMsg(c, "没有需要修改的设置项")
+		return
+	}
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		common.FailWithMsg(c, "获取用户信息失败")
+		return
+	}
+	waitClaims := claims.(*utils.CustomClaims)
+
+	if err = utils.SetUserPreferences(global.JY_DB, waitClaims.ID, req); err != nil {
+		common.FailWithMsg(c, err.Error())
+		return
+	}
+
+	preferences, err := utils.GetUserPreferences(global.JY_DB, waitClaims.ID)
+	if err != nil {
+		common.FailWithMsg(c, "获取偏好设置失败")
+		return
+	}
+	common.OkWithDetailed(c, preferences, "修改成功")
+}
@@ -9,18 +9,17 @@ import (
 )
 
 type UpdateProfileRequest struct {
-	NickName  string `json:"nickName" binding:"required"`
-	HeaderImg string `json:"headerImg"`
+	NickName string `json:"nickName" binding:"required"`
 }
 
 // UpdateProfile 更新个人资料
 // @Summary      更新个人资料
-// @Description  更新当前登录用户的昵称和头像
+// @Description  更新当前登录用户的昵称，头像通过上传头像接口（/user/avatar）修改
 // @Security     ApiKeyAuth
 // @Tags         User
 // @Accept       json
 // @Produce      json
-// @Param        data  body      UpdateProfileRequest  true  "昵称, 头像"
+// @Param        data  body      UpdateProfileRequest  true  "昵称"
 // @Success      200   {object}  common.Response{data=system.SysUser,msg=string}  "更新成功"
 // @Router       /user/profile [put]
 func (a *Api) UpdateProfile(c *gin.Context) {
@@ -40,7 +39,7 @@ func (a *Api) UpdateProfile(c *gin.Context) {
 	waitClaims := claims.(*utils.CustomClaims)
 
 	var user system.SysUser
-	err = global.JY_DB.Where("id = ?", waitClaims.ID).First(&user).Error
+	err = global.JY_DB.WithContext(c).Where("id = ?", waitClaims.ID).First(&user).Error
 	if err != nil {
 		common.FailWithMsg(c, "用户不存在")
 		return
@@ -49,7 +48,7 @@ func (a *Api) UpdateProfile(c *gin.Context) {
 	// 检查昵称是否已被其他用户使用
 	if req.NickName != user.NickName {
 		var existingUser system.SysUser
-		err = global.JY_DB.Where("nick_name = ? AND id != ?", req.NickName, waitClaims.ID).First(&existingUser).Error
+		err = global.JY_DB.WithContext(c).Where("nick_name = ? AND id != ?", req.NickName, waitClaims.ID).First(&existingUser).Error
 		if err == nil {
 			common.FailWithMsg(c, "昵称已被使用")
 			return
@@ -58,11 +57,8 @@ func (a *Api) UpdateProfile(c *gin.Context) {
 
 	// 更新用户信息
 	user.NickName = req.NickName
-	if req.HeaderImg != "" {
-		user.HeaderImg = req.HeaderImg
-	}
 
-	err = global.JY_DB.Save(&user).Error
+	err = global.JY_DB.WithContext(c).Save(&user).Error
 	if err != nil {
 		common.FailWithMsg(c, "更新失败")
 		return
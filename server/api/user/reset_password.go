@@ -15,7 +15,7 @@ type ResetPasswordRequest struct {
 
 // ResetPassword 管理员重置用户密码
 // @Summary      管理员重置用户密码
-// @Description  管理员重置用户密码（不需要旧密码）
+// @Description  管理员重置用户密码（不需要旧密码），用户下次登录后必须先修改密码
 // @Security     ApiKeyAuth
 // @Tags         User
 // @Accept       json
@@ -39,15 +39,16 @@ func (a *Api) ResetPassword(c *gin.Context) {
 
 	// 查找用户
 	var user system.SysUser
-	err = global.JY_DB.Where("id = ?", req.UserID).First(&user).Error
+	err = global.JY_DB.WithContext(c).Where("id = ?", req.UserID).First(&user).Error
 	if err != nil {
 		common.FailWithMsg(c, "用户不存在")
 		return
 	}
 
-	// 更新密码
+	// 更新密码，用户下次登录后必须先修改密码
 	user.Password = utils.BcryptHash(req.NewPassword)
-	err = global.JY_DB.Save(&user).Error
+	user.MustChangePassword = true
+	err = global.JY_DB.WithContext(c).Save(&user).Error
 	if err != nil {
 		common.FailWithMsg(c, "重置密码失败")
 		return
@@ -14,7 +14,7 @@ import (
 // @Tags         User
 // @Accept       json
 // @Produce      json
-// @Param        data  body      system.SysUser   true  "用户ID, 昵称, 头像, 角色ID"
+// @Param        data  body      system.SysUser   true  "用户ID, 昵称, 角色ID, 部门ID, 状态"
 // @Success      200   {object}  common.Response{msg=string}  "更新成功"
 // @Router       /user [put]
 func (a *Api) UpdateUser(c *gin.Context) {
@@ -24,15 +24,20 @@ func (a *Api) UpdateUser(c *gin.Context) {
 		common.FailWithMsg(c, "参数绑定失败")
 		return
 	}
+	if !canAssignAuthority(c, user.AuthorityId) {
+		common.FailWithMsg(c, "只有超级管理员可以分配超级管理员角色")
+		return
+	}
 	// 不允许通过此接口直接修改密码，如果有密码修改需求应走专门的接口
+	// 头像只能由用户本人通过上传头像接口修改，这里不更新
 	// 使用 map 更新，确保 enable 字段（即使是 false）也能正确更新
 	updateData := map[string]interface{}{
 		"nick_name":    user.NickName,
-		"header_img":   user.HeaderImg,
 		"authority_id": user.AuthorityId,
+		"dept_id":      user.DeptId,
 		"enable":       user.Enable,
 	}
-	err = global.JY_DB.Model(&system.SysUser{}).Where("id = ?", user.ID).Updates(updateData).Error
+	err = global.JY_DB.WithContext(c).Model(&system.SysUser{}).Where("id = ?", user.ID).Updates(updateData).Error
 	if err != nil {
 		common.FailWithMsg(c, "更新用户失败")
 		return
@@ -1,3 +1,21 @@
 package user
 
+import (
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/utils"
+)
+
 type Api struct{}
+
+// canAssignAuthority 判断当前用户能否为用户分配该角色
+// 超级管理员（888）是唯一可以跨租户的角色，只能由超级管理员分配
+func canAssignAuthority(c *gin.Context, authorityId string) bool {
+	if authorityId != "888" {
+		return true
+	}
+	claims, exists := c.Get("claims")
+	if !exists {
+		return false
+	}
+	return claims.(*utils.CustomClaims).AuthorityId == "888"
+}
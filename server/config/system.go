@@ -14,4 +14,6 @@ type System struct {
 	DisableAutoMigrate bool   `mapstructure:"disable-auto-migrate"`
 	ReadTimeout        int    `mapstructure:"read-timeout"`
 	WriteTimeout       int    `mapstructure:"write-timeout"`
+	MenuSeedFile       string `mapstructure:"menu-seed-file"`       // 启动时导入的菜单文件(json/yaml)，只新建还不存在的菜单，为空则不导入
+	TrashRetentionDays int    `mapstructure:"trash-retention-days"` // 回收站保留天数，超过后彻底删除，0 表示不自动清理
 }
@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 
+	"go.uber.org/zap"
 	"jiangyi.com/global"
 	"jiangyi.com/model/system"
 	"jiangyi.com/utils"
@@ -92,3 +93,134 @@ func StartJwtCleanupTask() {
 
 	log.Println("JWT token清理定时任务已启动，每天凌晨执行一次")
 }
+
+// ExpireAuthorityGrants 收回已到期的临时角色授权
+// 权限判定本身只认有效期内的授权，这里负责把到期授权标记为已到期，并通过已注册的通知方式发出通知（见 InitGrantNotifier）
+func ExpireAuthorityGrants() error {
+	if global.JY_DB == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	now := time.Now()
+	var grants []system.SysAuthorityGrant
+	err := global.JY_DB.Where("status = ? AND end_at <= ?", system.GrantStatusActive, now).Find(&grants).Error
+	if err != nil {
+		return fmt.Errorf("查询到期授权失败: %v", err)
+	}
+
+	for _, grant := range grants {
+		err := global.JY_DB.Model(&grant).Updates(map[string]interface{}{
+			"status":     system.GrantStatusExpired,
+			"revoked_at": now,
+		}).Error
+		if err != nil {
+			log.Printf("收回临时授权失败 (ID: %d): %v\n", grant.ID, err)
+			continue
+		}
+		grant.Status, grant.RevokedAt = system.GrantStatusExpired, &now
+		utils.NotifyGrant(grant, utils.GrantEventExpired)
+	}
+
+	if len(grants) > 0 {
+		log.Printf("收回到期临时授权完成，共收回 %d 条\n", len(grants))
+	}
+	return nil
+}
+
+// StartGrantExpireTask 启动临时角色授权到期收回定时任务
+// 每分钟执行一次
+func StartGrantExpireTask() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	log.Println("临时角色授权收回定时任务已启动，每分钟执行一次")
+	for range ticker.C {
+		if err := ExpireAuthorityGrants(); err != nil {
+			log.Printf("临时角色授权收回任务执行失败: %v\n", err)
+		}
+	}
+}
+
+// PurgeExpiredTrash 彻底删除在回收站中超过保留天数的记录
+func PurgeExpiredTrash() error {
+	if global.JY_DB == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+	days := global.JY_Config.System.TrashRetentionDays
+	if days <= 0 {
+		return nil
+	}
+
+	for resource, result := range utils.PurgeExpiredTrash(global.JY_DB, days) {
+		log.Printf("清理回收站 %s 完成，共删除 %d 条\n", resource, result.Success)
+		for _, msg := range result.Messages {
+			global.JY_LOG.Error("清理回收站记录失败",
+				zap.String("resource", resource),
+				zap.Uint("id", msg.ID),
+				zap.String("error", msg.Message),
+			)
+		}
+	}
+	return nil
+}
+
+// StartTrashPurgeTask 启动回收站清理定时任务
+// 启动时执行一次，之后每24小时执行一次
+func StartTrashPurgeTask() {
+	if global.JY_Config.System.TrashRetentionDays <= 0 {
+		return
+	}
+	if err := PurgeExpiredTrash(); err != nil {
+		log.Printf("回收站清理任务执行失败: %v\n", err)
+	}
+
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	log.Printf("回收站清理定时任务已启动，保留 %d 天\n", global.JY_Config.System.TrashRetentionDays)
+	for range ticker.C {
+		if err := PurgeExpiredTrash(); err != nil {
+			log.Printf("回收站清理任务执行失败: %v\n", err)
+		}
+	}
+}
+
+// ReturnIdleCustomers 将超过公海规则天数未跟进的客户退回公海
+func ReturnIdleCustomers() error {
+	if global.JY_DB == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	result, err := utils.ReturnIdleCustomers(global.JY_DB)
+	if err != nil {
+		return fmt.Errorf("查询未跟进客户失败: %v", err)
+	}
+	for _, msg := range result.Messages {
+		global.JY_LOG.Error("客户自动退回公海失败",
+			zap.Uint("customer_id", msg.ID),
+			zap.String("error", msg.Message),
+		)
+	}
+	if result.Success > 0 {
+		log.Printf("未跟进客户退回公海完成，共退回 %d 个\n", result.Success)
+	}
+	return nil
+}
+
+// StartCustomerPoolTask 启动未跟进客户自动退回公海定时任务
+// 启动时执行一次，之后每小时执行一次
+func StartCustomerPoolTask() {
+	if err := ReturnIdleCustomers(); err != nil {
+		log.Printf("客户退回公海任务执行失败: %v\n", err)
+	}
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	log.Println("客户退回公海定时任务已启动，每小时执行一次")
+	for range ticker.C {
+		if err := ReturnIdleCustomers(); err != nil {
+			log.Printf("客户退回公海任务执行失败: %v\n", err)
+		}
+	}
+}
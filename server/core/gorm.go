@@ -11,6 +11,8 @@ import (
 	"jiangyi.com/global"
 	"jiangyi.com/model/business"
 	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+	"jiangyi.com/utils/search"
 )
 
 func InitGorm() *gorm.DB {
@@ -80,6 +82,18 @@ func InitGorm() *gorm.DB {
 		panic(err)
 	}
 
+	// 注册租户隔离回调
+	if err = RegisterTenantCallbacks(db); err != nil {
+		fmt.Printf("注册租户隔离回调失败: %v\n", err)
+		panic(err)
+	}
+
+	// 注册全文索引同步回调
+	if err = search.RegisterCallbacks(db); err != nil {
+		fmt.Printf("注册全文索引回调失败: %v\n", err)
+		panic(err)
+	}
+
 	// 设置连接池
 	sqlDB, _ := db.DB()
 	sqlDB.SetMaxIdleConns(maxIdleConns)
@@ -92,10 +106,53 @@ func InitGorm() *gorm.DB {
 func RegisterTables() {
 
 	db := global.JY_DB
-	err := db.AutoMigrate(
+	if err := migrateTables(db); err != nil {
+		fmt.Printf("初始化数据库数据失败: %v\n", err)
+	}
+
+	// 初始化全文检索
+	InitSearch(db)
+
+	// 检查系统是否已初始化，未初始化时只开放初始化接口
+	if err := utils.InitSetupState(db); err != nil {
+		fmt.Printf("检查系统初始化状态失败: %v\n", err)
+	}
+
+	// 导入菜单种子文件中还不存在的菜单，已有的菜单和角色菜单权限以管理员的修改为准，需要覆盖时使用菜单导入接口
+	if seedFile := global.JY_Config.System.MenuSeedFile; seedFile != "" {
+		result, err := importMenuSeed(db, seedFile)
+		if err != nil {
+			fmt.Printf("导入菜单失败: %v\n", err)
+		} else if result.Created > 0 {
+			fmt.Printf("导入菜单成功: 新建 %d\n", result.Created)
+		}
+	}
+}
+
+// importMenuSeed 导入菜单种子文件中还不存在的菜单
+func importMenuSeed(db *gorm.DB, seedFile string) (utils.MenuImportResult, error) {
+	data, err := utils.ReadMenuFile(seedFile)
+	if err != nil {
+		return utils.MenuImportResult{}, err
+	}
+	return utils.ImportMissingMenus(db, data)
+}
+
+// migrateTables 自动迁移数据表并初始化数据库数据
+func migrateTables(db *gorm.DB) error {
+	// 升级前的客户没有负责人字段，需要在自动迁移添加字段之前判断
+	backfillOwner, err := prepareCustomerOwner(db)
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(
 		system.SysUser{},
 		system.ExaFileUploadAndDownload{},
 		system.JwtBlacklist{},
+		system.SysAuthorityGrant{},
+		system.SysTenant{},
+		system.SysDept{},
 		business.Customer{},
 		business.AIConversation{},
 		business.AIMessage{},
@@ -108,6 +165,10 @@ func RegisterTables() {
 
 	// 初始化数据库数据
 	if err := InitDb(db); err != nil {
-		fmt.Printf("初始化数据库数据失败: %v\n", err)
+		return err
+	}
+	if backfillOwner {
+		return backfillCustomerOwner(db, false)
 	}
+	return nil
 }
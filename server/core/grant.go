@@ -0,0 +1,35 @@
+package core
+
+import (
+	"go.uber.org/zap"
+	"jiangyi.com/global"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+// grantEventMessages 临时角色授权通知事件的说明
+var grantEventMessages = map[string]string{
+	utils.GrantEventCreated: "临时角色授权",
+	utils.GrantEventRevoked: "临时角色授权已撤销",
+	utils.GrantEventExpired: "临时角色授权已到期收回",
+}
+
+// InitGrantNotifier 注册临时角色授权的通知方式，目前写入系统日志，接入站内信、邮件等渠道后在这里追加注册
+func InitGrantNotifier() {
+	utils.RegisterGrantNotifier(logGrantNotifier)
+}
+
+// logGrantNotifier 将临时角色授权的变化写入系统日志
+func logGrantNotifier(grant system.SysAuthorityGrant, event string) {
+	global.JY_LOG.Info(grantEventMessages[event],
+		zap.String("event", event),
+		zap.Uint("grant_id", grant.ID),
+		zap.Uint("user_id", grant.UserId),
+		zap.String("authority_id", grant.AuthorityId),
+		zap.Time("start_at", grant.StartAt),
+		zap.Time("end_at", grant.EndAt),
+		zap.Uint("approver_id", grant.ApproverId),
+		zap.String("reason", grant.Reason),
+		zap.Uint("revoked_by", grant.RevokedBy),
+	)
+}
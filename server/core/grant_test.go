@@ -0,0 +1,75 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"jiangyi.com/global"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils"
+)
+
+func TestExpireAuthorityGrantsNotifies(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&system.SysAuthorityGrant{}); err != nil {
+		t.Fatal(err)
+	}
+	core, logs := observer.New(zap.InfoLevel)
+	previousDB, previousLog := global.JY_DB, global.JY_LOG
+	global.JY_DB, global.JY_LOG = db, zap.New(core)
+	t.Cleanup(func() { global.JY_DB, global.JY_LOG = previousDB, previousLog })
+
+	now := time.Now()
+	grants := []system.SysAuthorityGrant{
+		{UserId: 1, AuthorityId: "200", StartAt: now.Add(-2 * time.Hour), EndAt: now.Add(-time.Minute), Status: system.GrantStatusActive},
+		{UserId: 2, AuthorityId: "200", StartAt: now.Add(-2 * time.Hour), EndAt: now.Add(time.Hour), Status: system.GrantStatusActive},
+		{UserId: 3, AuthorityId: "200", StartAt: now.Add(-2 * time.Hour), EndAt: now.Add(-time.Minute), Status: system.GrantStatusRevoked},
+	}
+	if err = db.Create(&grants).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var notified []system.SysAuthorityGrant
+	InitGrantNotifier()
+	utils.RegisterGrantNotifier(func(grant system.SysAuthorityGrant, event string) {
+		if event == utils.GrantEventExpired {
+			notified = append(notified, grant)
+		}
+	})
+
+	if err = ExpireAuthorityGrants(); err != nil {
+		t.Fatalf("ExpireAuthorityGrants() error = %v", err)
+	}
+	if len(notified) != 1 || notified[0].ID != grants[0].ID || notified[0].Status != system.GrantStatusExpired || notified[0].RevokedAt == nil {
+		t.Fatalf("到期通知 = %+v, want 授权 %d", notified, grants[0].ID)
+	}
+	entries := logs.FilterMessage(grantEventMessages[utils.GrantEventExpired]).All()
+	if len(entries) != 1 || entries[0].ContextMap()["grant_id"] != uint64(grants[0].ID) {
+		t.Errorf("到期日志 = %+v", entries)
+	}
+
+	var expired system.SysAuthorityGrant
+	if err = db.First(&expired, grants[0].ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if expired.Status != system.GrantStatusExpired {
+		t.Errorf("授权状态 = %s, want %s", expired.Status, system.GrantStatusExpired)
+	}
+
+	// 再次执行不会重复通知
+	if err = ExpireAuthorityGrants(); err != nil {
+		t.Fatal(err)
+	}
+	if len(notified) != 1 {
+		t.Errorf("重复通知 %d 次", len(notified))
+	}
+}
@@ -16,52 +16,121 @@ func InitDb(db *gorm.DB) error {
 			&system.SysUser{},
 			&system.SysAuthority{},
 			&system.SysBaseMenu{},
+			&system.SysBaseMenuI18n{},
+			&system.SysBaseMenuParameter{},
 			&system.ExaFileUploadAndDownload{},
 			&system.JwtBlacklist{},
+			&system.SysTenant{},
+			&system.SysDept{},
+			&system.SysPreferenceDefinition{},
+			&system.SysUserPreference{},
+			&system.SysSetup{},
+			&system.SysAuditLog{},
 			&business.Customer{},
+			&business.CustomerField{},
+			&business.CustomerFieldValue{},
+			&business.CustomerContact{},
+			&business.CustomerAddress{},
+			&business.CustomerActivity{},
+			&business.CustomerWorkflow{},
+			&business.CustomerStatusHistory{},
+			&business.CustomerPoolSetting{},
+			&business.CustomerImportJob{},
+			&business.CustomerImportError{},
+			&business.CustomerView{},
+			&business.SearchDocument{},
 		); err != nil {
 			return err
 		}
 
-		// 2. Init Data
-		var total int64
-		if err := tx.Model(&system.SysUser{}).Count(&total).Error; err != nil {
-			return err
-		}
+		// 管理员账号和角色不在这里写入，由首次启动后的初始化接口创建（见 utils.RunSetup）
 
-		// 初始化默认角色
-		var authorityTotal int64
-		if err := tx.Model(&system.SysAuthority{}).Count(&authorityTotal).Error; err != nil {
+		// 初始化默认租户
+		var tenantTotal int64
+		if err := tx.Model(&system.SysTenant{}).Count(&tenantTotal).Error; err != nil {
 			return err
 		}
-		if authorityTotal == 0 {
-			// 创建超级管理员角色
-			adminAuthority := system.SysAuthority{
-				AuthorityId:   "888",
-				AuthorityName: "超级管理员",
-				ParentId:      "0",
-				DefaultRouter: "dashboard",
+		if tenantTotal == 0 {
+			tenant := system.SysTenant{
+				Name:   "默认租户",
+				Code:   "default",
+				Enable: true,
 			}
-			if err := tx.Create(&adminAuthority).Error; err != nil {
+			tenant.ID = system.DefaultTenantId
+			if err := tx.Create(&tenant).Error; err != nil {
 				return err
 			}
-			fmt.Println("InitSysAuthority success")
+			fmt.Println("InitSysTenant success")
 		}
 
-		if total == 0 {
-			// Seed admin user
-			user := system.SysUser{
-				Username:    "admin",
-				Password:    utils.BcryptHash("123456"), // 使用 bcrypt 加密密码
-				NickName:    "超级管理员",
-				HeaderImg:   "https://qmplusimg.henrongyi.top/gva_header.jpg",
-				AuthorityId: "888",
-			}
-			if err := tx.Create(&user).Error; err != nil {
-				return err
+		// 旧版本的默认头像指向外部图床，替换为内置的默认头像
+		if err := tx.Model(&system.SysUser{}).Where("header_img = ?", "https://qmplusimg.henrongyi.top/gva_header.jpg").
+			Updates(map[string]interface{}{"header_img": system.DefaultHeaderImg, "header_thumb": system.DefaultHeaderImg}).Error; err != nil {
+			return err
+		}
+
+		// 旧版本的客户电话按原样保存，统一转换为 E.164 格式，无法识别的号码保持不变
+		if err := normalizeCustomerPhones(tx); err != nil {
+			return err
+		}
+
+		// 导入任务的进度只保存在内存中，服务重启时未完成的任务已经中断
+		if err := tx.Model(&business.CustomerImportJob{}).Where("status IN ?", []string{business.ImportStatusPending, business.ImportStatusRunning}).
+			Updates(map[string]interface{}{"status": business.ImportStatusFailed, "message": "服务重启，导入任务中断，已提交的行可能已部分导入"}).Error; err != nil {
+			return err
+		}
+
+		// 初始化内置的用户偏好设置项
+		return utils.InitPreferenceDefinitions(tx)
+	})
+}
+
+// prepareCustomerOwner 在自动迁移之前检查客户负责人字段，返回迁移后是否需要回填负责人
+// 旧版本迁移时负责人字段允许为空，字段改为非空之前先回填这些客户
+func prepareCustomerOwner(db *gorm.DB) (bool, error) {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&business.Customer{}) {
+		return false, nil
+	}
+	if !migrator.HasColumn(&business.Customer{}, "OwnerID") {
+		return true, nil
+	}
+	return false, backfillCustomerOwner(db, true)
+}
+
+// backfillCustomerOwner 升级前的客户由创建人负责，而不是进入公海；没有创建人的客户进入公海
+// onlyNull 为 true 时只处理负责人为空的客户
+func backfillCustomerOwner(db *gorm.DB, onlyNull bool) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		query := func() *gorm.DB {
+			q := tx.Unscoped().Model(&business.Customer{})
+			if onlyNull {
+				q = q.Where("owner_id IS NULL")
 			}
-			fmt.Println("InitSysUser success")
+			return q
 		}
-		return nil
+		if err := query().Where("created_by <> 0").
+			UpdateColumns(map[string]interface{}{"owner_id": gorm.Expr("created_by"), "owned_at": gorm.Expr("created_at")}).Error; err != nil {
+			return err
+		}
+		return query().Where("owner_id IS NULL").UpdateColumn("owner_id", 0).Error
 	})
 }
+
+// normalizeCustomerPhones 将未规范化的客户电话转换为 E.164 格式
+func normalizeCustomerPhones(tx *gorm.DB) error {
+	var customers []business.Customer
+	if err := tx.Unscoped().Select("id", "customer_phone").Where("customer_phone NOT LIKE ?", "+%").Find(&customers).Error; err != nil {
+		return err
+	}
+	for _, customer := range customers {
+		phone, err := utils.NormalizePhone(customer.CustomerPhone)
+		if err != nil {
+			continue
+		}
+		if err = tx.Unscoped().Model(&business.Customer{}).Where("id = ?", customer.ID).UpdateColumn("customer_phone", phone).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
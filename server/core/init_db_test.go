@@ -0,0 +1,102 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"jiangyi.com/model/business"
+)
+
+func TestMigrateTablesBackfillCustomerOwner(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema []string
+		want   map[uint]uint // 客户ID -> 负责人ID
+	}{
+		{
+			name: "升级前没有创建人和负责人字段",
+			schema: []string{
+				"CREATE TABLE customers (id integer PRIMARY KEY AUTOINCREMENT, created_at datetime, updated_at datetime, deleted_at datetime, customer_name text, customer_phone text, customer_status text)",
+				"INSERT INTO customers (id, created_at, customer_name) VALUES (1, '2024-01-01 00:00:00', 'a'), (2, '2024-01-02 00:00:00', 'b')",
+			},
+			want: map[uint]uint{1: 0, 2: 0},
+		},
+		{
+			name: "有创建人但没有负责人字段",
+			schema: []string{
+				"CREATE TABLE customers (id integer PRIMARY KEY AUTOINCREMENT, created_at datetime, updated_at datetime, deleted_at datetime, customer_name text, customer_phone text, customer_status text, tenant_id integer DEFAULT 1, created_by integer)",
+				"INSERT INTO customers (id, created_at, customer_name, created_by) VALUES (1, '2024-01-01 00:00:00', 'a', 7), (2, '2024-01-02 00:00:00', 'b', NULL), (3, '2024-01-03 00:00:00', 'c', 0)",
+				"UPDATE customers SET deleted_at = '2024-02-01 00:00:00' WHERE id = 1",
+			},
+			want: map[uint]uint{1: 7, 2: 0, 3: 0},
+		},
+		{
+			name: "负责人字段允许为空且有空值",
+			schema: []string{
+				"CREATE TABLE customers (id integer PRIMARY KEY AUTOINCREMENT, created_at datetime, updated_at datetime, deleted_at datetime, customer_name text, customer_phone text, customer_status text, tenant_id integer DEFAULT 1, created_by integer, owner_id integer, owned_at datetime)",
+				"INSERT INTO customers (id, created_at, customer_name, created_by, owner_id) VALUES (1, '2024-01-01 00:00:00', 'a', 7, NULL), (2, '2024-01-02 00:00:00', 'b', NULL, NULL), (3, '2024-01-03 00:00:00', 'c', 7, 9)",
+			},
+			want: map[uint]uint{1: 7, 2: 0, 3: 9},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, sql := range tt.schema {
+				if err := db.Exec(sql).Error; err != nil {
+					t.Fatalf("创建旧版本数据表失败: %v", err)
+				}
+			}
+
+			if err := migrateTables(db); err != nil {
+				t.Fatalf("migrateTables() error = %v", err)
+			}
+
+			var customers []business.Customer
+			if err := db.Unscoped().Order("id").Find(&customers).Error; err != nil {
+				t.Fatal(err)
+			}
+			if len(customers) != len(tt.want) {
+				t.Fatalf("客户数量 = %d, want %d", len(customers), len(tt.want))
+			}
+			for _, customer := range customers {
+				if customer.OwnerID != tt.want[customer.ID] {
+					t.Errorf("客户 %d 负责人 = %d, want %d", customer.ID, customer.OwnerID, tt.want[customer.ID])
+				}
+				if customer.OwnerID != 0 && customer.OwnedAt == nil && customer.CreatedBy == customer.OwnerID {
+					t.Errorf("客户 %d 没有回填分配时间", customer.ID)
+				}
+			}
+
+			var nullOwners int64
+			if err := db.Table("customers").Where("owner_id IS NULL").Count(&nullOwners).Error; err != nil {
+				t.Fatal(err)
+			}
+			if nullOwners != 0 {
+				t.Errorf("负责人为空的客户 = %d, want 0", nullOwners)
+			}
+
+			// 再次启动不会改变负责人
+			if err := db.Unscoped().Model(&business.Customer{}).Where("id = ?", customers[0].ID).UpdateColumn("owner_id", 5).Error; err != nil {
+				t.Fatal(err)
+			}
+			if err := migrateTables(db); err != nil {
+				t.Fatalf("migrateTables() again error = %v", err)
+			}
+			var customer business.Customer
+			if err := db.Unscoped().First(&customer, customers[0].ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if customer.OwnerID != 5 {
+				t.Errorf("再次迁移后客户 %d 负责人 = %d, want 5", customer.ID, customer.OwnerID)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package core
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/utils/search"
+)
+
+// InitSearch 按数据库类型选择全文检索实现并创建索引，数据库不支持时降级为 LIKE 查询
+func InitSearch(db *gorm.DB) {
+	var engine search.Engine
+	switch global.JY_Config.System.DBType {
+	case "mysql":
+		engine = search.MySQL{}
+	default:
+		engine = &search.FTS5{}
+	}
+	if err := engine.Init(db); err != nil {
+		fmt.Printf("初始化全文索引失败，使用 LIKE 查询: %v\n", err)
+		if _, ok := engine.(*search.FTS5); ok {
+			fmt.Println("SQLite 全文索引需要 FTS5，请使用 go run -tags sqlite_fts5 main.go 启动")
+		}
+		engine = search.Like{}
+	}
+	search.Use(engine)
+	fmt.Printf("全文检索: %s\n", engine.Name())
+
+	// 首次启用全文检索时按已有数据生成文档
+	if err := search.RebuildIfEmpty(db); err != nil {
+		fmt.Printf("生成全文索引文档失败: %v\n", err)
+	}
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"jiangyi.com/utils"
+)
+
+// tenantColumn 租户隔离字段，模型中包含该字段即自动参与租户隔离
+const tenantColumn = "tenant_id"
+
+// RegisterTenantCallbacks 注册租户隔离回调
+// 通过 db.WithContext(c) 传入 gin.Context 的查询会根据 token 中的租户ID自动过滤/填充 tenant_id，
+// 超级管理员（888）是唯一可以跨租户访问的角色，未携带 token 的查询（如定时任务、登录）不做限制
+func RegisterTenantCallbacks(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("tenant:create", tenantCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("tenant:query", tenantWhere); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("tenant:row", tenantWhere); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("tenant:update", tenantWhere); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:delete").Register("tenant:delete", tenantWhere)
+}
+
+// tenantClaims 获取当前语句关联请求的 token 信息
+func tenantClaims(db *gorm.DB) *utils.CustomClaims {
+	if db.Statement.Context == nil || db.Statement.Schema == nil {
+		return nil
+	}
+	if db.Statement.Schema.LookUpField(tenantColumn) == nil {
+		return nil
+	}
+	claims, ok := db.Statement.Context.Value("claims").(*utils.CustomClaims)
+	if !ok {
+		return nil
+	}
+	return claims
+}
+
+// tenantWhere 为查询、更新、删除追加租户条件
+func tenantWhere(db *gorm.DB) {
+	claims := tenantClaims(db)
+	if claims == nil || claims.AuthorityId == "888" {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: tenantColumn}, Value: claims.TenantId},
+	}})
+}
+
+// tenantCreate 创建数据时写入租户ID
+// 普通用户只能写入自己的租户，超级管理员未指定租户时写入自己的租户
+func tenantCreate(db *gorm.DB) {
+	claims := tenantClaims(db)
+	if claims == nil {
+		return
+	}
+	field := db.Statement.Schema.LookUpField(tenantColumn)
+	ctx := db.Statement.Context
+	setTenant := func(rv reflect.Value) {
+		if _, isZero := field.ValueOf(ctx, rv); isZero || claims.AuthorityId != "888" {
+			db.AddError(field.Set(ctx, rv, claims.TenantId))
+		}
+	}
+
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			setTenant(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		setTenant(rv)
+	}
+}
@@ -25,6 +25,13 @@ func main() {
 		core.LoadBlacklistFromDB()
 		// 启动JWT token清理定时任务（每天凌晨执行一次）
 		go core.StartJwtCleanupTask()
+		// 注册临时角色授权的通知方式，启动到期收回定时任务（每分钟执行一次）
+		core.InitGrantNotifier()
+		go core.StartGrantExpireTask()
+		// 启动回收站清理定时任务（每天执行一次）
+		go core.StartTrashPurgeTask()
+		// 启动未跟进客户自动退回公海定时任务（每小时执行一次）
+		go core.StartCustomerPoolTask()
 		// close db connection logic if needed
 		sqlDB, _ := global.JY_DB.DB()
 		defer sqlDB.Close()
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"jiangyi.com/global"
 	"jiangyi.com/model/common"
@@ -8,6 +10,12 @@ import (
 )
 
 func JWTAuth() gin.HandlerFunc {
+	// 需要修改密码的用户只能访问的接口
+	prefix := strings.TrimRight(global.JY_Config.System.RouterPrefix, "/")
+	restrictedRoutes := map[string]bool{
+		prefix + "/user/changePassword": true,
+		prefix + "/logout":              true,
+	}
 	return func(c *gin.Context) {
 		// 我们这里jwt鉴权取头部信息 Authorization 登录时回返回token信息 这里前端需要把token存储到cookie或者本地localStorage中 不过需要跟后端协商过期时间 可以约定刷新令牌或者重新登录
 		authorization := c.Request.Header.Get("Authorization")
@@ -30,6 +38,11 @@ func JWTAuth() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		if claims.MustChangePassword && !restrictedRoutes[c.FullPath()] {
+			common.Result(common.ERROR, gin.H{"mustChangePassword": true}, "请先修改密码", c)
+			c.Abort()
+			return
+		}
 		c.Set("claims", claims)
 		c.Next()
 	}
@@ -37,7 +37,7 @@ func RBACAuth() gin.HandlerFunc {
 		}
 
 		// 检查是否有权限访问该路径
-		hasPermission := checkPermission(&authority, path, method)
+		hasPermission := checkPermission(waitClaims.ID, &authority, path, method)
 		if !hasPermission {
 			common.FailWithMsg(c, "没有权限访问该资源")
 			c.Abort()
@@ -48,37 +48,8 @@ func RBACAuth() gin.HandlerFunc {
 	}
 }
 
-// checkPermission 检查角色是否有权限访问指定路径
-func checkPermission(authority *system.SysAuthority, path string, method string) bool {
-	// 超级管理员（888）拥有所有权限
-	if authority.AuthorityId == "888" {
-		return true
-	}
-
-	// 检查角色的菜单权限
-	for _, menu := range authority.SysBaseMenus {
-		// 简单匹配：如果路径包含菜单路径，则认为有权限
-		// 实际项目中可以根据需要实现更复杂的匹配逻辑
-		if menu.Path != "" && containsPath(path, menu.Path) {
-			return true
-		}
-	}
-
-	return false
+// checkPermission 检查用户角色及其临时授权角色是否有权限访问指定路径
+// 判定逻辑与权限模拟接口共用 utils.ExplainUserPermission，保证两者结论一致
+func checkPermission(userId uint, authority *system.SysAuthority, path string, method string) bool {
+	return utils.ExplainUserPermission(userId, authority, path, method).Allowed
 }
-
-// containsPath 检查请求路径是否匹配菜单路径
-func containsPath(requestPath string, menuPath string) bool {
-	// 精确匹配
-	if requestPath == menuPath {
-		return true
-	}
-	// 前缀匹配（支持子路径）
-	if len(menuPath) > 0 && len(requestPath) >= len(menuPath) {
-		if requestPath[:len(menuPath)] == menuPath {
-			return true
-		}
-	}
-	return false
-}
-
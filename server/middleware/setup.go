@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/utils"
+)
+
+// SetupGuard 系统未初始化时只开放初始化接口，其余接口直接返回未初始化
+// 不在接口前缀下的请求（前端页面、接口文档）不受影响，以便前端展示初始化页面
+func SetupGuard() gin.HandlerFunc {
+	prefix := "/" + strings.Trim(global.JY_Config.System.RouterPrefix, "/")
+	setupPath := strings.TrimRight(prefix, "/") + "/setup"
+	return func(c *gin.Context) {
+		if !utils.SetupRequired() {
+			c.Next()
+			return
+		}
+		path := c.Request.URL.Path
+		if !strings.HasPrefix(path, prefix) || path == setupPath || strings.HasPrefix(path, setupPath+"/") {
+			c.Next()
+			return
+		}
+		common.Result(common.ERROR, gin.H{"needSetup": true}, "系统尚未初始化，请先完成初始化", c)
+		c.Abort()
+	}
+}
@@ -11,6 +11,7 @@ type AIConversation struct {
 	Title     string `json:"title" gorm:"comment:会话标题"`
 	LastMsg   string `json:"lastMsg" gorm:"type:text;comment:最后一条消息"`
 	MessageCount int `json:"messageCount" gorm:"default:0;comment:消息数量"`
+	TenantID  uint   `json:"tenantId" gorm:"index;default:1;comment:租户ID"`
 }
 
 // AIMessage AI 消息表
@@ -1,12 +1,25 @@
 package business
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type Customer struct {
 	gorm.Model
 	CustomerName   string `json:"customerName" gorm:"comment:客户名"`
-	CustomerPhone  string `json:"customerPhone" gorm:"comment:客户手机号"`
+	CustomerPhone  string `json:"customerPhone" gorm:"comment:客户手机号" mask:"phone,customer:view-phone"`
 	CustomerStatus string `json:"customerStatus" gorm:"comment:客户状态"`
+	TenantID       uint   `json:"tenantId" gorm:"index;default:1;comment:租户ID"`
+	CreatedBy      uint   `json:"createdBy" gorm:"index;comment:创建人ID"`
+
+	OwnerID        uint       `json:"ownerId" gorm:"index;not null;default:0;comment:负责人ID，0 表示在公海中"` // 负责人ID，0 表示在公海中
+	OwnedAt        *time.Time `json:"ownedAt" gorm:"comment:分配给当前负责人的时间"`                             // 领取、分配或转移给当前负责人的时间
+	LastFollowedAt *time.Time `json:"lastFollowedAt" gorm:"comment:最近跟进时间"`                           // 最近一条人工跟进记录的发生时间
+	Tags           []string   `json:"tags" gorm:"serializer:json;type:text;comment:标签"`               // 标签
+
+	CustomFields map[string]interface{} `json:"customFields" gorm:"-"`                            // 自定义字段值，字段标识 -> 值，见 CustomerField
+	Contacts     []CustomerContact      `json:"contacts,omitempty" gorm:"foreignKey:CustomerID"`  // 联系人，仅详情中返回
+	Addresses    []CustomerAddress      `json:"addresses,omitempty" gorm:"foreignKey:CustomerID"` // 地址，仅详情中返回
 }
@@ -0,0 +1,35 @@
+package business
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"jiangyi.com/model/system"
+)
+
+// 客户跟进记录类型
+const (
+	ActivityCall   = "call"   // 电话
+	ActivityVisit  = "visit"  // 拜访
+	ActivityEmail  = "email"  // 邮件
+	ActivityNote   = "note"   // 备注
+	ActivityStatus = "status" // 状态变更，由系统自动记录
+	ActivityAssign = "assign" // 负责人变更，由系统自动记录
+	ActivityMerge  = "merge"  // 合并客户，由系统自动记录
+)
+
+// CustomerActivity 客户跟进记录，包括人工录入的跟进和系统自动记录的变更
+type CustomerActivity struct {
+	gorm.Model
+	TenantID   uint      `json:"tenantId" gorm:"index;default:1;comment:租户ID"`                                  // 租户ID
+	CustomerID uint      `json:"customerId" gorm:"index;comment:客户ID"`                                          // 客户ID
+	Type       string    `json:"type" gorm:"size:16;index;comment:类型 call, visit, email, note, status, assign"` // 类型
+	Content    string    `json:"content" gorm:"type:text;comment:内容" mask:"phoneText,customer:view-phone"`      // 内容，其中的电话号码对没有查看完整电话权限的用户脱敏
+	FromValue  string    `json:"fromValue" gorm:"comment:变更前的值（系统记录）"`                                          // 变更前的值，仅系统记录的变更有值
+	ToValue    string    `json:"toValue" gorm:"comment:变更后的值（系统记录）"`                                            // 变更后的值，仅系统记录的变更有值
+	OccurredAt time.Time `json:"occurredAt" gorm:"index;comment:发生时间"`                                          // 发生时间
+	CreatedBy  uint      `json:"createdBy" gorm:"index;comment:记录人ID"`                                          // 记录人ID，系统记录时为触发变更的用户
+
+	Files       []system.ExaFileUploadAndDownload `json:"files" gorm:"many2many:customer_activity_files;"` // 附件
+	CreatorName string                            `json:"creatorName" gorm:"-"`                            // 记录人昵称，查询时填充
+}
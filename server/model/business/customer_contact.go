@@ -0,0 +1,36 @@
+package business
+
+import (
+	"time"
+)
+
+// CustomerContact 客户联系人
+type CustomerContact struct {
+	ID         uint      `gorm:"primarykey" json:"ID"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+	TenantID   uint      `json:"tenantId" gorm:"index;default:1;comment:租户ID"`                                        // 租户ID
+	CustomerID uint      `json:"customerId" gorm:"index;comment:客户ID"`                                                // 客户ID
+	Name       string    `json:"name" gorm:"comment:姓名"`                                                              // 姓名
+	Title      string    `json:"title" gorm:"comment:职务"`                                                             // 职务
+	Phones     []string  `json:"phones" gorm:"serializer:json;type:text;comment:电话" mask:"phone,customer:view-phone"` // 电话
+	Emails     []string  `json:"emails" gorm:"serializer:json;type:text;comment:邮箱"`                                  // 邮箱
+	IsPrimary  bool      `json:"isPrimary" gorm:"default:0;comment:是否主联系人"`                                           // 是否主联系人，每个客户只有一个
+	Remark     string    `json:"remark" gorm:"comment:备注"`                                                            // 备注
+}
+
+// CustomerAddress 客户地址
+type CustomerAddress struct {
+	ID         uint      `gorm:"primarykey" json:"ID"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+	TenantID   uint      `json:"tenantId" gorm:"index;default:1;comment:租户ID"` // 租户ID
+	CustomerID uint      `json:"customerId" gorm:"index;comment:客户ID"`         // 客户ID
+	Label      string    `json:"label" gorm:"comment:地址类型，如 办公、收货、开票"`         // 地址类型，如 办公、收货、开票
+	Province   string    `json:"province" gorm:"comment:省"`                    // 省
+	City       string    `json:"city" gorm:"comment:市"`                        // 市
+	District   string    `json:"district" gorm:"comment:区县"`                   // 区县
+	Detail     string    `json:"detail" gorm:"comment:详细地址"`                   // 详细地址
+	PostalCode string    `json:"postalCode" gorm:"comment:邮编"`                 // 邮编
+	IsPrimary  bool      `json:"isPrimary" gorm:"default:0;comment:是否默认地址"`    // 是否默认地址，每个客户只有一个
+}
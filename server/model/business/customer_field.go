@@ -0,0 +1,56 @@
+package business
+
+import (
+	"time"
+)
+
+// 客户自定义字段类型
+const (
+	CustomerFieldText        = "text"        // 文本
+	CustomerFieldNumber      = "number"      // 数字
+	CustomerFieldDate        = "date"        // 日期，格式 2006-01-02
+	CustomerFieldSelect      = "select"      // 单选
+	CustomerFieldMultiSelect = "multiSelect" // 多选
+	CustomerFieldUser        = "user"        // 用户（本租户内的用户ID）
+)
+
+// CustomerFieldRules 自定义字段的校验规则，只有与字段类型对应的规则生效
+type CustomerFieldRules struct {
+	MinLength *int     `json:"minLength,omitempty"` // 文本最小长度
+	MaxLength *int     `json:"maxLength,omitempty"` // 文本最大长度
+	Pattern   string   `json:"pattern,omitempty"`   // 文本正则
+	Minimum   *float64 `json:"minimum,omitempty"`   // 数字最小值
+	Maximum   *float64 `json:"maximum,omitempty"`   // 数字最大值
+	Integer   bool     `json:"integer,omitempty"`   // 数字必须为整数
+	MinDate   string   `json:"minDate,omitempty"`   // 最早日期
+	MaxDate   string   `json:"maxDate,omitempty"`   // 最晚日期
+	MinItems  *int     `json:"minItems,omitempty"`  // 多选最少选择项数
+	MaxItems  *int     `json:"maxItems,omitempty"`  // 多选最多选择项数
+}
+
+// CustomerField 客户自定义字段定义，由管理员按租户维护
+type CustomerField struct {
+	ID        uint               `gorm:"primarykey" json:"ID"`
+	CreatedAt time.Time          `json:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt"`
+	TenantID  uint               `json:"tenantId" gorm:"uniqueIndex:idx_customer_field_key;default:1;comment:租户ID"`        // 租户ID
+	FieldKey  string             `json:"fieldKey" gorm:"size:64;uniqueIndex:idx_customer_field_key;not null;comment:字段标识"` // 字段标识，同一租户内唯一
+	Name      string             `json:"name" gorm:"comment:字段名称"`                                                         // 字段名称
+	Type      string             `json:"type" gorm:"size:16;comment:字段类型 text, number, date, select, multiSelect, user"`   // 字段类型
+	Options   []string           `json:"options" gorm:"serializer:json;type:text;comment:可选项（单选、多选）"`                      // 可选项（单选、多选）
+	Required  bool               `json:"required" gorm:"default:0;comment:是否必填"`                                           // 是否必填
+	Unique    bool               `json:"unique" gorm:"column:is_unique;default:0;comment:是否唯一"`                            // 同一租户内取值是否唯一
+	Rules     CustomerFieldRules `json:"rules" gorm:"serializer:json;type:text;comment:校验规则"`                              // 校验规则
+	Remark    string             `json:"remark" gorm:"comment:备注"`                                                         // 备注
+	Sort      int                `json:"sort" gorm:"comment:排序标记"`                                                         // 排序标记
+}
+
+// CustomerFieldValue 客户自定义字段值，每个值一行（多选的每个选项各一行），便于筛选和唯一性检查
+type CustomerFieldValue struct {
+	ID          uint     `gorm:"primarykey" json:"ID"`
+	TenantID    uint     `json:"tenantId" gorm:"index;default:1;comment:租户ID"`                     // 租户ID
+	CustomerID  uint     `json:"customerId" gorm:"index;comment:客户ID"`                             // 客户ID
+	FieldID     uint     `json:"fieldId" gorm:"index:idx_customer_field_value;comment:字段ID"`       // 字段ID
+	Value       string   `json:"value" gorm:"size:255;index:idx_customer_field_value;comment:字段值"` // 统一转换为字符串的字段值
+	NumberValue *float64 `json:"numberValue" gorm:"comment:数字字段的值，用于范围筛选"`                         // 数字字段的值，用于范围筛选
+}
@@ -0,0 +1,51 @@
+package business
+
+import (
+	"time"
+)
+
+// 客户导入任务状态
+const (
+	ImportStatusPending   = "pending"   // 等待执行
+	ImportStatusRunning   = "running"   // 执行中
+	ImportStatusSucceeded = "succeeded" // 已完成（部分行失败也属于完成，见 Failed）
+	ImportStatusFailed    = "failed"    // 任务出错中断
+)
+
+// 客户导入模式
+const (
+	ImportModePartial = "partial" // 导入校验通过的行，跳过有错误的行
+	ImportModeAll     = "all"     // 全部行校验通过才导入，任一行有错误时不导入任何客户
+)
+
+// CustomerImportJob 客户导入任务，导入在后台执行，前端轮询进度
+type CustomerImportJob struct {
+	ID               uint              `gorm:"primarykey" json:"ID"`
+	CreatedAt        time.Time         `json:"createdAt"`
+	UpdatedAt        time.Time         `json:"updatedAt"`
+	TenantID         uint              `json:"tenantId" gorm:"index;default:1;comment:租户ID"`                 // 租户ID
+	CreatedBy        uint              `json:"createdBy" gorm:"index;comment:创建人ID"`                         // 创建人ID
+	FileName         string            `json:"fileName" gorm:"comment:文件名"`                                  // 文件名
+	Mode             string            `json:"mode" gorm:"size:16;comment:导入模式 partial, all"`                // 导入模式
+	DryRun           bool              `json:"dryRun" gorm:"comment:是否仅校验"`                                  // 是否仅校验不导入
+	IgnoreDuplicates bool              `json:"ignoreDuplicates" gorm:"comment:是否忽略疑似重复的客户"`                  // 是否忽略疑似重复的客户
+	Header           []string          `json:"header" gorm:"serializer:json;type:text;comment:文件表头"`         // 文件表头
+	Mapping          map[string]string `json:"mapping" gorm:"serializer:json;type:text;comment:表头与导入列的对应关系"` // 表头 -> 导入列标识
+	Status           string            `json:"status" gorm:"size:16;index;comment:状态"`                       // 状态
+	Total            int               `json:"total" gorm:"comment:数据行数"`                                    // 数据行数
+	Processed        int               `json:"processed" gorm:"comment:已处理行数"`                               // 已处理行数
+	Succeeded        int               `json:"succeeded" gorm:"comment:校验通过的行数"`                             // 校验通过的行数
+	Failed           int               `json:"failed" gorm:"comment:失败的行数"`                                  // 失败的行数
+	Created          int               `json:"created" gorm:"comment:实际创建的客户数"`                              // 实际创建的客户数
+	Message          string            `json:"message" gorm:"type:text;comment:任务说明"`                        // 任务结果说明，出错时为错误原因
+	FinishedAt       *time.Time        `json:"finishedAt" gorm:"comment:完成时间"`                               // 完成时间
+}
+
+// CustomerImportError 客户导入失败的行，保留原始数据用于生成错误文件
+type CustomerImportError struct {
+	ID     uint     `gorm:"primarykey" json:"ID"`
+	JobID  uint     `json:"jobId" gorm:"index;comment:导入任务ID"`                   // 导入任务ID
+	Row    int      `json:"row" gorm:"comment:文件中的行号"`                           // 文件中的行号（表头为第1行）
+	Cells  []string `json:"cells" gorm:"serializer:json;type:text;comment:原始数据"` // 原始数据
+	Errors []string `json:"errors" gorm:"serializer:json;type:text;comment:错误"`  // 错误
+}
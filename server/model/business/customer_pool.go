@@ -0,0 +1,16 @@
+package business
+
+import (
+	"time"
+)
+
+// CustomerPoolSetting 租户的公海规则，未配置时不限制领取且不自动退回公海
+type CustomerPoolSetting struct {
+	ID              uint      `gorm:"primarykey" json:"ID"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+	TenantID        uint      `json:"tenantId" gorm:"uniqueIndex;default:1;comment:租户ID"`     // 租户ID
+	MaxOwned        int       `json:"maxOwned" gorm:"comment:每人最多负责的客户数，0 表示不限制"`             // 每人最多负责的客户数，0 表示不限制
+	DailyClaimLimit int       `json:"dailyClaimLimit" gorm:"comment:每人每天最多从公海领取的客户数，0 表示不限制"` // 每人每天最多从公海领取的客户数，0 表示不限制
+	ReturnDays      int       `json:"returnDays" gorm:"comment:超过多少天未跟进自动退回公海，0 表示不自动退回"`     // 超过多少天未跟进自动退回公海，0 表示不自动退回
+}
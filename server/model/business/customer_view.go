@@ -0,0 +1,48 @@
+package business
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 客户查询条件的运算符
+const (
+	CustomerOpEq       = "eq"       // 等于，多选字段和标签为包含该值
+	CustomerOpIn       = "in"       // 等于其中之一，多选字段和标签为包含其中之一
+	CustomerOpRange    = "range"    // 范围，值为 {"from": 起, "to": 止}，可省略一端，包含两端
+	CustomerOpContains = "contains" // 文本包含
+	CustomerOpIsNull   = "isNull"   // 值为 true 时未填写，为 false 时已填写
+)
+
+// CustomerFilter 客户查询的一个条件
+type CustomerFilter struct {
+	Field string          `json:"field"` // 字段，固定字段见 utils.CustomerQueryFields，自定义字段为 cf.字段标识
+	Op    string          `json:"op"`    // 运算符
+	Value json.RawMessage `json:"value"` // 值，类型由字段和运算符决定
+}
+
+// CustomerSort 客户查询的一个排序条件
+type CustomerSort struct {
+	Field string `json:"field"` // 排序字段，只能是允许排序的固定字段
+	Desc  bool   `json:"desc"`  // 是否倒序
+}
+
+// CustomerQuery 客户的结构化查询条件，各条件之间为“且”的关系
+type CustomerQuery struct {
+	Keyword string           `json:"keyword"` // 关键字，模糊匹配名称和电话
+	Filters []CustomerFilter `json:"filters"` // 筛选条件
+	Sorts   []CustomerSort   `json:"sorts"`   // 排序，按顺序依次排序
+}
+
+// CustomerView 保存的客户查询视图，只有创建人可以修改，共享给角色后该角色的用户也可以使用
+type CustomerView struct {
+	ID          uint          `gorm:"primarykey" json:"ID"`
+	CreatedAt   time.Time     `json:"createdAt"`
+	UpdatedAt   time.Time     `json:"updatedAt"`
+	TenantID    uint          `json:"tenantId" gorm:"index;default:1;comment:租户ID"`               // 租户ID
+	CreatedBy   uint          `json:"createdBy" gorm:"index;comment:创建人ID"`                       // 创建人ID
+	Name        string        `json:"name" gorm:"size:64;comment:视图名称"`                           // 视图名称
+	AuthorityId string        `json:"authorityId" gorm:"size:90;index;comment:共享的角色ID，为空时仅创建人可见"` // 共享的角色ID，为空时仅创建人可见
+	Query       CustomerQuery `json:"query" gorm:"serializer:json;type:text;comment:查询条件"`        // 查询条件
+	Sort        int           `json:"sort" gorm:"comment:排序标记"`                                   // 排序标记
+}
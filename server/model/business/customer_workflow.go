@@ -0,0 +1,51 @@
+package business
+
+import (
+	"time"
+)
+
+// WorkflowAnyStatus 流转规则中表示任意状态的起始状态
+const WorkflowAnyStatus = "*"
+
+// WorkflowStatus 客户状态
+type WorkflowStatus struct {
+	Code    string `json:"code"`    // 状态编码，保存在客户的 customerStatus 中
+	Name    string `json:"name"`    // 状态名称
+	Initial bool   `json:"initial"` // 是否为初始状态，新建客户的状态，有且只有一个
+	Final   bool   `json:"final"`   // 是否为终止状态，用于展示和统计，处于终止状态的客户不会自动退回公海
+	Color   string `json:"color"`   // 前端展示颜色
+}
+
+// WorkflowTransition 状态流转规则
+type WorkflowTransition struct {
+	From           string   `json:"from"`           // 起始状态编码，* 表示任意状态
+	To             string   `json:"to"`             // 目标状态编码
+	Name           string   `json:"name"`           // 操作名称，如“标记流失”
+	AuthorityIds   []string `json:"authorityIds"`   // 可执行该流转的角色，为空时所有角色都可以执行
+	RequireReason  bool     `json:"requireReason"`  // 是否必须填写原因，如流失原因
+	RequiredFields []string `json:"requiredFields"` // 进入目标状态时必须已填写的自定义字段标识
+}
+
+// CustomerWorkflow 客户状态流程，每个租户一份，未配置时使用内置的默认流程
+type CustomerWorkflow struct {
+	ID          uint                 `gorm:"primarykey" json:"ID"`
+	CreatedAt   time.Time            `json:"createdAt"`
+	UpdatedAt   time.Time            `json:"updatedAt"`
+	TenantID    uint                 `json:"tenantId" gorm:"uniqueIndex;default:1;comment:租户ID"`        // 租户ID
+	Statuses    []WorkflowStatus     `json:"statuses" gorm:"serializer:json;type:text;comment:状态"`      // 状态
+	Transitions []WorkflowTransition `json:"transitions" gorm:"serializer:json;type:text;comment:流转规则"` // 流转规则
+}
+
+// CustomerStatusHistory 客户状态历史，每进入一个状态记录一行，离开时写入离开时间和停留时长
+type CustomerStatusHistory struct {
+	ID         uint       `gorm:"primarykey" json:"ID"`
+	TenantID   uint       `json:"tenantId" gorm:"index;default:1;comment:租户ID"` // 租户ID
+	CustomerID uint       `json:"customerId" gorm:"index;comment:客户ID"`         // 客户ID
+	FromStatus string     `json:"fromStatus" gorm:"size:32;comment:之前的状态"`      // 之前的状态
+	Status     string     `json:"status" gorm:"size:32;index;comment:进入的状态"`    // 进入的状态
+	Reason     string     `json:"reason" gorm:"type:text;comment:流转原因"`         // 流转原因
+	ChangedBy  uint       `json:"changedBy" gorm:"comment:操作人ID"`               // 操作人ID
+	EnteredAt  time.Time  `json:"enteredAt" gorm:"index;comment:进入时间"`          // 进入时间
+	LeftAt     *time.Time `json:"leftAt" gorm:"comment:离开时间"`                   // 离开时间，仍处于该状态时为空
+	Duration   int64      `json:"duration" gorm:"default:0;comment:停留时长（秒）"`    // 停留时长（秒），离开时写入
+}
@@ -0,0 +1,23 @@
+package business
+
+import (
+	"time"
+)
+
+// 全文索引文档类型
+const (
+	SearchDocCustomer = "customer" // 客户：名称、电话、标签、联系人、地址
+	SearchDocActivity = "activity" // 人工录入的跟进记录内容
+)
+
+// SearchDocument 全文索引文档，由客户及其联系人、地址、跟进记录写入时自动生成，见 utils/search
+type SearchDocument struct {
+	ID         uint      `gorm:"primarykey" json:"ID"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+	TenantID   uint      `json:"tenantId" gorm:"index;default:1;comment:租户ID"`                            // 租户ID
+	CustomerID uint      `json:"customerId" gorm:"index;comment:客户ID"`                                    // 所属客户ID
+	Type       string    `json:"type" gorm:"size:16;uniqueIndex:idx_search_document_source;comment:文档类型"` // 文档类型
+	SourceID   uint      `json:"sourceId" gorm:"uniqueIndex:idx_search_document_source;comment:来源记录ID"`   // 来源记录ID，客户文档为客户ID，跟进记录文档为跟进记录ID
+	Title      string    `json:"title" gorm:"size:255;comment:标题"`                                        // 标题
+	Content    string    `json:"content" gorm:"type:text;comment:内容"`                                     // 内容
+}
@@ -0,0 +1,24 @@
+package system
+
+import (
+	"time"
+)
+
+// 审计操作
+const (
+	AuditCustomerMerge       = "customer.merge"        // 合并客户
+	AuditCustomerRevealPhone = "customer.reveal-phone" // 查看客户完整电话
+)
+
+// SysAuditLog 审计日志，记录合并客户等不易撤销或涉及敏感数据的操作，只增不改
+type SysAuditLog struct {
+	ID           uint      `gorm:"primarykey" json:"ID"`                                              // 主键ID
+	CreatedAt    time.Time `json:"createdAt" gorm:"index"`                                            // 操作时间
+	TenantId     uint      `json:"tenantId" gorm:"index;default:1;comment:租户ID"`                      // 租户ID，为被操作数据所在的租户
+	UserId       uint      `json:"userId" gorm:"index;comment:操作人ID"`                                 // 操作人ID
+	Action       string    `json:"action" gorm:"size:64;index;comment:操作"`                            // 操作，如 customer.merge
+	ResourceType string    `json:"resourceType" gorm:"size:64;index:idx_audit_resource;comment:资源类型"` // 资源类型，如 customer
+	ResourceId   uint      `json:"resourceId" gorm:"index:idx_audit_resource;comment:资源ID"`           // 资源ID
+	Detail       string    `json:"detail" gorm:"type:text;comment:操作详情(JSON)"`                        // 操作详情(JSON)
+	IP           string    `json:"ip" gorm:"size:64;comment:操作IP"`                                    // 操作IP
+}
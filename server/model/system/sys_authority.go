@@ -6,19 +6,32 @@ import (
 	"gorm.io/gorm"
 )
 
+// 角色数据范围
+const (
+	DataScopeAll          = "all"          // 全部数据
+	DataScopeDept         = "dept"         // 本部门数据
+	DataScopeDeptAndChild = "deptAndChild" // 本部门及以下数据
+	DataScopeSelf         = "self"         // 仅本人数据
+	DataScopeCustom       = "custom"       // 自定义部门数据
+)
+
 type SysAuthority struct {
-	ID            uint           `gorm:"primarykey" json:"ID"`
-	CreatedAt     time.Time      `json:"createdAt"`
-	UpdatedAt     time.Time      `json:"updatedAt"`
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
-	AuthorityId   string         `json:"authorityId" gorm:"not null;unique;primary_key;comment:角色ID"` // 角色ID
-	AuthorityName string         `json:"authorityName" gorm:"comment:角色名"`                            // 角色名
-	ParentId      string         `json:"parentId" gorm:"comment:父角色ID"`                               // 父角色ID
-	DataAuthority []SysAuthority `json:"dataAuthority" gorm:"many2many:sys_data_authority_id;"`
-	Children      []SysAuthority `json:"children" gorm:"-"`
-	SysBaseMenus  []SysBaseMenu  `json:"menus" gorm:"many2many:sys_authority_menus;"`
-	DefaultRouter string         `json:"defaultRouter" gorm:"comment:默认路由;default:dashboard"` // 默认路由
-	Enable        bool           `json:"enable" gorm:"default:1;comment:角色状态，1-启用，0-禁用"`      // 角色状态
+	ID             uint           `gorm:"primarykey" json:"ID"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+	AuthorityId    string         `json:"authorityId" gorm:"not null;unique;primary_key;comment:角色ID"` // 角色ID
+	AuthorityName  string         `json:"authorityName" gorm:"comment:角色名"`                            // 角色名
+	ParentId       string         `json:"parentId" gorm:"comment:父角色ID"`                               // 父角色ID
+	DataAuthority  []SysAuthority `json:"dataAuthority" gorm:"many2many:sys_data_authority_id;"`
+	Children       []SysAuthority `json:"children" gorm:"-"`
+	SysBaseMenus   []SysBaseMenu  `json:"menus" gorm:"many2many:sys_authority_menus;"`
+	DefaultRouter  string         `json:"defaultRouter" gorm:"comment:默认路由;default:dashboard"`                             // 默认路由
+	Enable         bool           `json:"enable" gorm:"default:1;comment:角色状态，1-启用，0-禁用"`                                  // 角色状态
+	TenantId       uint           `json:"tenantId" gorm:"index;default:1;comment:租户ID"`                                    // 租户ID
+	DataScope      string         `json:"dataScope" gorm:"default:all;comment:数据范围 all, dept, deptAndChild, self, custom"` // 数据范围
+	DataScopeDepts []SysDept      `json:"dataScopeDepts" gorm:"many2many:sys_authority_depts;"`                            // 自定义数据范围的部门
+	Permissions    []string       `json:"permissions" gorm:"serializer:json;type:text;comment:操作权限标识"`                     // 操作权限标识，见 PermissionDefinitions
 }
 
 type SysBaseMenu struct {
@@ -32,6 +45,32 @@ type SysBaseMenu struct {
 	Sort      int                                        `json:"sort" gorm:"comment:排序标记"`                       // 排序标记
 	Enable    bool                                       `json:"enable" gorm:"default:1;comment:菜单状态，1-启用，0-禁用"` // 菜单状态
 	Meta      `json:"meta" gorm:"embedded;comment:附加属性"` // 附加属性
+
+	I18n       []SysBaseMenuI18n      `json:"i18n" gorm:"foreignKey:SysBaseMenuID"`       // 多语言标题
+	Parameters []SysBaseMenuParameter `json:"parameters" gorm:"foreignKey:SysBaseMenuID"` // 路由参数
+}
+
+// SysBaseMenuI18n 菜单多语言标题
+type SysBaseMenuI18n struct {
+	gorm.Model
+	SysBaseMenuID uint   `json:"sysBaseMenuId" gorm:"index;comment:菜单ID"`      // 菜单ID
+	Locale        string `json:"locale" gorm:"size:20;comment:语言，如 en, en-US"` // 语言
+	Title         string `json:"title" gorm:"comment:菜单名"`                     // 菜单名
+}
+
+// 菜单路由参数类型
+const (
+	MenuParameterQuery  = "query"  // 查询参数 ?key=value
+	MenuParameterParams = "params" // 路径参数 /:key
+)
+
+// SysBaseMenuParameter 菜单路由参数，用于菜单直接跳转到带参数的页面
+type SysBaseMenuParameter struct {
+	gorm.Model
+	SysBaseMenuID uint   `json:"sysBaseMenuId" gorm:"index;comment:菜单ID"`        // 菜单ID
+	Type          string `json:"type" gorm:"size:20;comment:参数类型 query, params"` // 参数类型
+	Key           string `json:"key" gorm:"comment:参数名"`                         // 参数名
+	Value         string `json:"value" gorm:"comment:参数值"`                       // 参数值
 }
 
 type Meta struct {
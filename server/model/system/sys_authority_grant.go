@@ -0,0 +1,29 @@
+package system
+
+import (
+	"time"
+
+	"jiangyi.com/global"
+)
+
+// 临时角色授权状态
+const (
+	GrantStatusActive  = "active"  // 有效（是否生效还取决于生效时间）
+	GrantStatusExpired = "expired" // 已到期收回
+	GrantStatusRevoked = "revoked" // 已手动撤销
+)
+
+// SysAuthorityGrant 临时角色授权，在有效期内为用户追加一个角色
+type SysAuthorityGrant struct {
+	global.GlobalModel
+	UserId      uint       `json:"userId" gorm:"index;comment:被授权用户ID"`                                             // 被授权用户ID
+	AuthorityId string     `json:"authorityId" gorm:"index;comment:临时授予的角色ID"`                                      // 临时授予的角色ID
+	StartAt     time.Time  `json:"startAt" gorm:"comment:生效时间"`                                                     // 生效时间
+	EndAt       time.Time  `json:"endAt" gorm:"index;comment:失效时间"`                                                 // 失效时间
+	Reason      string     `json:"reason" gorm:"comment:授权原因"`                                                      // 授权原因
+	ApproverId  uint       `json:"approverId" gorm:"comment:审批人ID"`                                                 // 审批人ID
+	Status      string     `json:"status" gorm:"index;default:active;comment:状态 active-有效 expired-已到期 revoked-已撤销"` // 状态
+	RevokedAt   *time.Time `json:"revokedAt" gorm:"comment:收回时间"`                                                   // 收回时间
+	RevokedBy   uint       `json:"revokedBy" gorm:"comment:撤销人ID，到期自动收回时为0"`                                        // 撤销人ID
+	TenantId    uint       `json:"tenantId" gorm:"index;default:1;comment:租户ID"`                                    // 租户ID
+}
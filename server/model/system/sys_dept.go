@@ -0,0 +1,15 @@
+package system
+
+import "jiangyi.com/global"
+
+// SysDept 部门，按 ParentId 组成树形结构
+type SysDept struct {
+	global.GlobalModel
+	ParentId uint      `json:"parentId" gorm:"index;default:0;comment:父部门ID，0为顶级部门"` // 父部门ID
+	Name     string    `json:"name" gorm:"not null;comment:部门名称"`                    // 部门名称
+	LeaderId uint      `json:"leaderId" gorm:"comment:负责人用户ID"`                      // 负责人用户ID
+	Sort     int       `json:"sort" gorm:"comment:排序标记"`                             // 排序标记
+	Enable   bool      `json:"enable" gorm:"default:1;comment:部门状态，1-启用，0-禁用"`       // 部门状态
+	TenantId uint      `json:"tenantId" gorm:"index;default:1;comment:租户ID"`         // 租户ID
+	Children []SysDept `json:"children" gorm:"-"`
+}
@@ -0,0 +1,38 @@
+package system
+
+// 操作权限标识，菜单控制页面和接口的访问，操作权限控制接口内更细的操作（如查看完整的敏感字段），通过角色的 Permissions 授予
+const (
+	PermissionCustomerViewPhone = "customer:view-phone" // 查看客户完整电话
+	PermissionAuditView         = "audit:view"          // 查看审计日志
+)
+
+// PermissionDefinition 操作权限说明
+type PermissionDefinition struct {
+	Code        string `json:"code"`        // 权限标识
+	Name        string `json:"name"`        // 名称
+	Description string `json:"description"` // 说明
+}
+
+// PermissionDefinitions 所有可以授予角色的操作权限
+var PermissionDefinitions = []PermissionDefinition{
+	{
+		Code:        PermissionCustomerViewPhone,
+		Name:        "查看客户完整电话",
+		Description: "客户列表、详情、导出等接口中显示完整的客户电话和联系人电话，没有该权限时显示为 138****8000，需要时可逐个查看并记录审计日志",
+	},
+	{
+		Code:        PermissionAuditView,
+		Name:        "查看审计日志",
+		Description: "查看所在租户的审计日志，包括客户合并、查看完整电话等操作的记录",
+	},
+}
+
+// IsPermissionDefined 权限标识是否存在
+func IsPermissionDefined(code string) bool {
+	for _, definition := range PermissionDefinitions {
+		if definition.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,11 @@
+package system
+
+import "jiangyi.com/global"
+
+// SysSetup 系统初始化记录，存在记录即表示系统已完成初始化，初始化接口永久关闭
+type SysSetup struct {
+	global.GlobalModel
+	AdminUserId uint   `json:"adminUserId" gorm:"comment:初始化时创建的管理员用户ID"` // 初始化时创建的管理员用户ID
+	Seeds       string `json:"seeds" gorm:"comment:初始化时写入的默认数据，逗号分隔"`     // 初始化时写入的默认数据
+	Remark      string `json:"remark" gorm:"comment:备注"`                  // 备注
+}
@@ -0,0 +1,15 @@
+package system
+
+import "jiangyi.com/global"
+
+// DefaultTenantId 默认租户ID，历史数据和未指定租户的数据归属该租户
+const DefaultTenantId uint = 1
+
+// SysTenant 租户，不同租户之间的数据相互隔离
+type SysTenant struct {
+	global.GlobalModel
+	Name   string `json:"name" gorm:"unique;not null;comment:租户名称"`       // 租户名称
+	Code   string `json:"code" gorm:"unique;not null;comment:租户编码"`       // 租户编码
+	Remark string `json:"remark" gorm:"comment:备注"`                       // 备注
+	Enable bool   `json:"enable" gorm:"default:1;comment:租户状态，1-启用，0-禁用"` // 租户状态
+}
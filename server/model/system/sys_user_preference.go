@@ -0,0 +1,29 @@
+package system
+
+import (
+	"encoding/json"
+	"time"
+
+	"jiangyi.com/global"
+)
+
+// SysPreferenceDefinition 用户偏好设置项定义，所有租户共用，由超级管理员维护默认值和取值规则
+type SysPreferenceDefinition struct {
+	global.GlobalModel
+	Key          string          `json:"key" gorm:"size:64;unique;not null;comment:设置项标识"`  // 设置项标识，如 locale、theme
+	Name         string          `json:"name" gorm:"comment:设置项名称"`                         // 设置项名称
+	Schema       json.RawMessage `json:"schema" gorm:"type:text;comment:取值规则（JSON Schema）"` // 取值规则（JSON Schema）
+	DefaultValue json.RawMessage `json:"defaultValue" gorm:"type:text;comment:默认值（JSON）"`   // 默认值（JSON）
+	Remark       string          `json:"remark" gorm:"comment:备注"`                          // 备注
+	Sort         int             `json:"sort" gorm:"comment:排序标记"`                          // 排序标记
+}
+
+// SysUserPreference 用户偏好设置值，未设置的项使用定义中的默认值
+type SysUserPreference struct {
+	ID        uint            `gorm:"primarykey" json:"ID"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	UserId    uint            `json:"userId" gorm:"uniqueIndex:idx_user_preference;comment:用户ID"`       // 用户ID
+	Key       string          `json:"key" gorm:"size:64;uniqueIndex:idx_user_preference;comment:设置项标识"` // 设置项标识
+	Value     json.RawMessage `json:"value" gorm:"type:text;comment:设置值（JSON）"`                         // 设置值（JSON）
+}
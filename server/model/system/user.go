@@ -6,6 +6,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// DefaultHeaderImg 默认头像，内置在程序中，通过 /avatar/default.png 访问（与本地存储的文件一样使用相对路径）
+const DefaultHeaderImg = "avatar/default.png"
+
 type SysUser struct {
 	ID          uint           `gorm:"primarykey" json:"ID"`
 	CreatedAt   time.Time      `json:"createdAt"`
@@ -14,7 +17,13 @@ type SysUser struct {
 	Username    string         `json:"username" gorm:"index;comment:用户登录名"`
 	Password    string         `json:"-" gorm:"comment:用户登录密码"`
 	NickName    string         `json:"nickName" gorm:"default:系统用户;comment:用户昵称;unique;not null"`
-	HeaderImg   string         `json:"headerImg" gorm:"default:https://qmplusimg.henrongyi.top/gva_header.jpg;comment:用户头像"`
+	HeaderImg   string         `json:"headerImg" gorm:"default:avatar/default.png;comment:用户头像"`
+	HeaderThumb string         `json:"headerThumb" gorm:"default:avatar/default.png;comment:用户头像缩略图"`
+	AvatarKey   string         `json:"-" gorm:"comment:头像在对象存储中的key，多个尺寸以逗号分隔"`
 	AuthorityId string         `json:"authorityId" gorm:"default:888;comment:用户角色ID"`
 	Enable      bool           `json:"enable" gorm:"default:1;comment:用户状态，1-启用，0-禁用"`
+	TenantId    uint           `json:"tenantId" gorm:"index;default:1;comment:租户ID"`
+	DeptId      uint           `json:"deptId" gorm:"index;default:0;comment:部门ID"`
+
+	MustChangePassword bool `json:"mustChangePassword" gorm:"default:0;comment:下次登录必须修改密码，管理员创建用户或重置密码后为1"`
 }
@@ -0,0 +1,8 @@
+package resource
+
+import _ "embed"
+
+// DefaultAvatar 内置的默认头像（256x256 PNG），不依赖外部图床
+//
+//go:embed avatar/default.png
+var DefaultAvatar []byte
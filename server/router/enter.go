@@ -11,6 +11,8 @@ import (
 	_ "jiangyi.com/docs"
 	"jiangyi.com/global"
 	"jiangyi.com/middleware"
+	"jiangyi.com/model/system"
+	"jiangyi.com/resource"
 )
 
 type justFilesFilesystem struct {
@@ -44,6 +46,8 @@ func InitGinRouter() *gin.Engine {
 	}
 	//	错误处理
 	Router.Use(gin.Recovery())
+	//	未初始化时只开放初始化接口
+	Router.Use(middleware.SetupGuard())
 
 	// CORS 配置
 	// Router.Use(cors.New(cors.Config{
@@ -69,6 +73,11 @@ func registerRouter(Router *gin.Engine) *gin.Engine {
 	//api分组
 	apiGroup := api.ApiGroup
 
+	//系统初始化（开放路由，仅未初始化时可用）
+	{
+		publicGroup.GET("/setup/status", apiGroup.SetupApi.GetSetupStatus)
+		publicGroup.POST("/setup", apiGroup.SetupApi.InitSystem)
+	}
 	//登录相关（开放路由，不需要认证）
 	{
 		publicGroup.GET("/login/captcha", apiGroup.LoginApi.GetCaptcha)
@@ -82,20 +91,62 @@ func registerRouter(Router *gin.Engine) *gin.Engine {
 	//客户管理
 	{
 		privateGroup.GET("/customer/list", apiGroup.CustomerApi.GetCustomerList)
+		privateGroup.GET("/customer/:id", apiGroup.CustomerApi.GetCustomerDetail)
+		privateGroup.GET("/customer/:id/timeline", apiGroup.CustomerApi.GetCustomerTimeline)
+		privateGroup.GET("/customer/:id/status/history", apiGroup.CustomerApi.GetStatusHistory)
+		privateGroup.POST("/customer/:id/reveal", apiGroup.CustomerApi.RevealCustomerPhone)
 		privateGroup.POST("/customer", apiGroup.CustomerApi.CreateCustomer)
 		privateGroup.PUT("/customer", apiGroup.CustomerApi.UpdateCustomer)
 		privateGroup.DELETE("/customer", apiGroup.CustomerApi.DeleteCustomer)
+		privateGroup.GET("/customer/field/list", apiGroup.CustomerApi.GetFieldList)
+		privateGroup.POST("/customer/field", apiGroup.CustomerApi.CreateField)
+		privateGroup.PUT("/customer/field", apiGroup.CustomerApi.UpdateField)
+		privateGroup.DELETE("/customer/field/:id", apiGroup.CustomerApi.DeleteField)
+		privateGroup.GET("/customer/activity/list", apiGroup.CustomerApi.GetActivityList)
+		privateGroup.POST("/customer/activity", apiGroup.CustomerApi.CreateActivity)
+		privateGroup.GET("/customer/workflow", apiGroup.CustomerApi.GetWorkflow)
+		privateGroup.PUT("/customer/workflow", apiGroup.CustomerApi.UpdateWorkflow)
+		privateGroup.POST("/customer/transition", apiGroup.CustomerApi.TransitionCustomer)
+		privateGroup.GET("/customer/status/stats", apiGroup.CustomerApi.GetStatusStats)
+		privateGroup.POST("/customer/assign", apiGroup.CustomerApi.AssignCustomer)
+		privateGroup.POST("/customer/transfer", apiGroup.CustomerApi.TransferCustomer)
+		privateGroup.POST("/customer/release", apiGroup.CustomerApi.ReleaseCustomer)
+		privateGroup.POST("/customer/claim", apiGroup.CustomerApi.ClaimCustomer)
+		privateGroup.GET("/customer/pool/list", apiGroup.CustomerApi.GetPoolList)
+		privateGroup.GET("/customer/pool/setting", apiGroup.CustomerApi.GetPoolSetting)
+		privateGroup.PUT("/customer/pool/setting", apiGroup.CustomerApi.UpdatePoolSetting)
+		privateGroup.GET("/customer/duplicate/list", apiGroup.CustomerApi.GetDuplicateList)
+		privateGroup.POST("/customer/merge", apiGroup.CustomerApi.MergeCustomer)
+		privateGroup.POST("/customer/import", apiGroup.CustomerApi.ImportCustomers)
+		privateGroup.GET("/customer/import/columns", apiGroup.CustomerApi.GetImportColumns)
+		privateGroup.GET("/customer/import/list", apiGroup.CustomerApi.GetImportList)
+		privateGroup.GET("/customer/import/:id", apiGroup.CustomerApi.GetImportJob)
+		privateGroup.GET("/customer/import/:id/errors", apiGroup.CustomerApi.DownloadImportErrors)
+		privateGroup.GET("/customer/export", apiGroup.CustomerApi.ExportCustomers)
+		privateGroup.POST("/customer/search", apiGroup.CustomerApi.SearchCustomers)
+		privateGroup.GET("/customer/query/fields", apiGroup.CustomerApi.GetQueryFields)
+		privateGroup.GET("/customer/view/list", apiGroup.CustomerApi.GetViewList)
+		privateGroup.POST("/customer/view", apiGroup.CustomerApi.CreateView)
+		privateGroup.PUT("/customer/view", apiGroup.CustomerApi.UpdateView)
+		privateGroup.DELETE("/customer/view/:id", apiGroup.CustomerApi.DeleteView)
+		privateGroup.GET("/customer/fulltext", apiGroup.CustomerApi.SearchFulltext)
+		privateGroup.POST("/customer/fulltext/rebuild", apiGroup.CustomerApi.RebuildFulltext)
 	}
 	//用户管理
 	{
 		privateGroup.GET("/user/list", apiGroup.UserApi.GetUserList)
+		privateGroup.GET("/user/export", apiGroup.UserApi.ExportUsers)
+		privateGroup.POST("/user/import", apiGroup.UserApi.ImportUsers)
 		privateGroup.GET("/user/userinfo", apiGroup.UserApi.GetCurrentUser)
 		privateGroup.POST("/user", apiGroup.UserApi.CreateUser)
 		privateGroup.PUT("/user", apiGroup.UserApi.UpdateUser)
 		privateGroup.PUT("/user/profile", apiGroup.UserApi.UpdateProfile)
+		privateGroup.POST("/user/avatar", apiGroup.UserApi.UploadAvatar)
 		privateGroup.DELETE("/user/:id", apiGroup.UserApi.DeleteUser)
 		privateGroup.POST("/user/changePassword", apiGroup.UserApi.ChangePassword)
 		privateGroup.POST("/user/resetPassword", apiGroup.UserApi.ResetPassword)
+		privateGroup.GET("/user/preferences", apiGroup.UserApi.GetPreferences)
+		privateGroup.PATCH("/user/preferences", apiGroup.UserApi.UpdatePreferences)
 	}
 	//文件管理
 	{
@@ -112,6 +163,22 @@ func registerRouter(Router *gin.Engine) *gin.Engine {
 		privateGroup.GET("/authority/getMenus", apiGroup.AuthorityApi.GetAuthorityMenus)
 		privateGroup.GET("/authority/getMenusByRole", apiGroup.AuthorityApi.GetAuthorityMenusByRole)
 		privateGroup.POST("/authority/setMenus", apiGroup.AuthorityApi.SetAuthorityMenus)
+		privateGroup.POST("/authority/checkPermission", apiGroup.AuthorityApi.CheckPermission)
+		privateGroup.POST("/authority/copy", apiGroup.AuthorityApi.CopyAuthority)
+		privateGroup.POST("/authority/setDataScope", apiGroup.AuthorityApi.SetDataScope)
+		privateGroup.GET("/authority/permissions", apiGroup.AuthorityApi.GetPermissions)
+		privateGroup.POST("/authority/setPermissions", apiGroup.AuthorityApi.SetPermissions)
+		privateGroup.POST("/authority/grant", apiGroup.AuthorityApi.CreateGrant)
+		privateGroup.GET("/authority/grant/list", apiGroup.AuthorityApi.GetGrantList)
+		privateGroup.POST("/authority/grant/revoke", apiGroup.AuthorityApi.RevokeGrant)
+	}
+	//部门管理
+	{
+		privateGroup.GET("/dept/list", apiGroup.DeptApi.GetDeptList)
+		privateGroup.GET("/dept/tree", apiGroup.DeptApi.GetDeptTree)
+		privateGroup.POST("/dept", apiGroup.DeptApi.CreateDept)
+		privateGroup.PUT("/dept", apiGroup.DeptApi.UpdateDept)
+		privateGroup.DELETE("/dept/:id", apiGroup.DeptApi.DeleteDept)
 	}
 	//菜单管理
 	{
@@ -119,6 +186,9 @@ func registerRouter(Router *gin.Engine) *gin.Engine {
 		privateGroup.POST("/menu", apiGroup.MenuApi.CreateMenu)
 		privateGroup.PUT("/menu", apiGroup.MenuApi.UpdateMenu)
 		privateGroup.DELETE("/menu/:id", apiGroup.MenuApi.DeleteMenu)
+		privateGroup.POST("/menu/move", apiGroup.MenuApi.MoveMenus)
+		privateGroup.GET("/menu/export", apiGroup.MenuApi.ExportMenus)
+		privateGroup.POST("/menu/import", apiGroup.MenuApi.ImportMenus)
 	}
 	//AI对话管理
 	{
@@ -129,12 +199,43 @@ func registerRouter(Router *gin.Engine) *gin.Engine {
 		privateGroup.POST("/ai/chat", apiGroup.AIApi.ChatMessage)
 	}
 
+	//租户管理（仅超级管理员）
+	{
+		privateGroup.GET("/tenant/list", apiGroup.TenantApi.GetTenantList)
+		privateGroup.POST("/tenant", apiGroup.TenantApi.CreateTenant)
+		privateGroup.PUT("/tenant", apiGroup.TenantApi.UpdateTenant)
+		privateGroup.DELETE("/tenant/:id", apiGroup.TenantApi.DeleteTenant)
+	}
+	//偏好设置项管理
+	{
+		privateGroup.GET("/preference/definition/list", apiGroup.PreferenceApi.GetDefinitionList)
+		privateGroup.POST("/preference/definition", apiGroup.PreferenceApi.CreateDefinition)
+		privateGroup.PUT("/preference/definition", apiGroup.PreferenceApi.UpdateDefinition)
+		privateGroup.DELETE("/preference/definition/:id", apiGroup.PreferenceApi.DeleteDefinition)
+	}
+	//审计日志
+	{
+		privateGroup.GET("/audit/list", apiGroup.AuditApi.GetAuditList)
+	}
+	//回收站
+	{
+		privateGroup.GET("/trash/:resource/list", apiGroup.TrashApi.GetTrashList)
+		privateGroup.POST("/trash/:resource/restore", apiGroup.TrashApi.RestoreTrash)
+		privateGroup.POST("/trash/:resource/purge", apiGroup.TrashApi.PurgeTrash)
+	}
+
 	Router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	if global.JY_Config.System.OSSType == "local" {
 		publicGroup.StaticFS(global.JY_Config.Local.StorePath, justFilesFilesystem{http.Dir(global.JY_Config.Local.StorePath)}) // Router.Use(middleware.LoadTls())  // 如果需要使用https 请打开此中间件 然后前往 core/server.go 将启动模式 更变为 Router.RunTLS("端口","你的cre/pem文件","你的key文件")
 	}
 
+	//内置默认头像
+	publicGroup.GET("/"+system.DefaultHeaderImg, func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=86400")
+		c.Data(http.StatusOK, "image/png", resource.DefaultAvatar)
+	})
+
 	//开放健康检查
 	publicGroup.GET("/health", func(c *gin.Context) {
 		c.String(http.StatusOK, "Hello, World!")
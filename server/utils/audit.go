@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"jiangyi.com/model/system"
+)
+
+// RecordAudit 写入审计日志，操作人和 IP 取自请求，detail 序列化为 JSON 保存
+// 需要与被审计的操作在同一事务中调用，保证操作成功时一定有审计记录
+func RecordAudit(tx *gorm.DB, c *gin.Context, log system.SysAuditLog, detail interface{}) error {
+	if claims, exists := c.Get("claims"); exists {
+		log.UserId = claims.(*CustomClaims).ID
+	}
+	log.IP = c.ClientIP()
+	if detail != nil {
+		data, err := json.Marshal(detail)
+		if err != nil {
+			return err
+		}
+		log.Detail = string(data)
+	}
+	return tx.Create(&log).Error
+}
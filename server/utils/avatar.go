@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	AvatarMaxSize   = 5 << 20  // 头像文件大小上限（5MB）
+	AvatarMaxPixels = 40000000 // 头像图片像素上限，防止解码超大图片耗尽内存
+)
+
+// AvatarSizes 头像生成的尺寸（正方形边长），第一个为主头像，其余为缩略图
+var AvatarSizes = []int{256, 64}
+
+// avatarFormats 支持的头像格式，按文件内容识别，不依赖扩展名和 Content-Type
+var avatarFormats = map[string]bool{"jpeg": true, "png": true, "gif": true, "webp": true}
+
+// AvatarImage 处理后的头像
+type AvatarImage struct {
+	Size int    // 边长
+	Ext  string // 扩展名，不透明图片为 .jpg，带透明通道的为 .png
+	Data []byte // 重新编码后的图片，不包含原图的 EXIF 等元数据
+}
+
+// ProcessAvatar 处理上传的头像：按内容识别真实格式、按 EXIF 方向摆正、居中裁剪为正方形、
+// 缩放到 AvatarSizes 中的各个尺寸并重新编码（重新编码会去掉原图中的所有元数据）
+func ProcessAvatar(r io.Reader) ([]AvatarImage, error) {
+	content, err := io.ReadAll(io.LimitReader(r, AvatarMaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取图片失败: %v", err)
+	}
+	if len(content) > AvatarMaxSize {
+		return nil, fmt.Errorf("图片大小不能超过%dMB", AvatarMaxSize>>20)
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil || !avatarFormats[format] {
+		return nil, errors.New("不支持的图片格式，仅支持 jpg、png、gif、webp")
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > AvatarMaxPixels {
+		return nil, errors.New("图片尺寸过大")
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("图片解析失败: %v", err)
+	}
+	if format == "jpeg" {
+		src = orientImage(src, jpegOrientation(content))
+	}
+	opaque := isOpaque(src)
+
+	// 居中裁剪为正方形
+	bounds := src.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+	x0 := bounds.Min.X + (bounds.Dx()-side)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-side)/2
+	crop := image.Rect(x0, y0, x0+side, y0+side)
+
+	images := make([]AvatarImage, 0, len(AvatarSizes))
+	for _, size := range AvatarSizes {
+		dst := image.NewRGBA(image.Rect(0, 0, size, size))
+		if opaque {
+			draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		}
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Over, nil)
+
+		var buf bytes.Buffer
+		avatar := AvatarImage{Size: size}
+		if opaque {
+			avatar.Ext = ".jpg"
+			err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 90})
+		} else {
+			avatar.Ext = ".png"
+			err = png.Encode(&buf, dst)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("图片编码失败: %v", err)
+		}
+		avatar.Data = buf.Bytes()
+		images = append(images, avatar)
+	}
+	return images, nil
+}
+
+// isOpaque 判断图片是否完全不透明
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	// gif 等调色板图片可能包含透明色
+	if _, ok := img.(*image.Paletted); ok {
+		return false
+	}
+	return true
+}
+
+// jpegOrientation 读取 JPEG 中 EXIF 的方向标记（1-8），没有或解析失败时返回 1
+func jpegOrientation(content []byte) int {
+	reader := bufio.NewReader(bytes.NewReader(content))
+	var marker [2]byte
+	if _, err := io.ReadFull(reader, marker[:]); err != nil || marker[0] != 0xFF || marker[1] != 0xD8 {
+		return 1
+	}
+	for {
+		if _, err := io.ReadFull(reader, marker[:]); err != nil || marker[0] != 0xFF {
+			return 1
+		}
+		// 图像数据开始，之后不会再有 EXIF
+		if marker[1] == 0xDA || marker[1] == 0xD9 {
+			return 1
+		}
+		var length uint16
+		if err := binary.Read(reader, binary.BigEndian, &length); err != nil || length < 2 {
+			return 1
+		}
+		segment := make([]byte, length-2)
+		if _, err := io.ReadFull(reader, segment); err != nil {
+			return 1
+		}
+		if marker[1] == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+	}
+}
+
+// exifOrientation 从 TIFF 结构的 EXIF 数据中读取方向标记（tag 0x0112）
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value >= 1 && value <= 8 {
+				return value
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orientImage 按 EXIF 方向标记旋转/翻转图片，使其按正常方向显示
+func orientImage(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	// 5-8 需要旋转 90 度，宽高互换
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // 水平翻转
+				dx, dy = w-1-x, y
+			case 3: // 旋转 180 度
+				dx, dy = w-1-x, h-1-y
+			case 4: // 垂直翻转
+				dx, dy = x, h-1-y
+			case 5: // 沿左上-右下对角线翻转
+				dx, dy = y, x
+			case 6: // 顺时针旋转 90 度
+				dx, dy = h-1-y, x
+			case 7: // 沿右上-左下对角线翻转
+				dx, dy = h-1-y, w-1-x
+			case 8: // 逆时针旋转 90 度
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, src.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,251 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"jiangyi.com/model/business"
+)
+
+const (
+	CustomerMaxContacts  = 50 // 每个客户最多的联系人数
+	CustomerMaxAddresses = 20 // 每个客户最多的地址数
+	ContactMaxPhones     = 10 // 每个联系人最多的电话数
+	ContactMaxEmails     = 10 // 每个联系人最多的邮箱数
+	CustomerMaxTags      = 20 // 每个客户最多的标签数
+	CustomerTagMaxLength = 32 // 标签最大长度
+)
+
+// SaveCustomerContacts 按请求中的联系人列表整体替换客户的联系人
+// 带 ID 的联系人更新，不带 ID 的新建，列表中没有的删除；没有标记主联系人时第一个联系人作为主联系人
+func SaveCustomerContacts(tx *gorm.DB, customer business.Customer, contacts []business.CustomerContact) error {
+	if len(contacts) > CustomerMaxContacts {
+		return fmt.Errorf("联系人不能超过%d个", CustomerMaxContacts)
+	}
+	primary := -1
+	for i := range contacts {
+		contact := &contacts[i]
+		contact.Name = strings.TrimSpace(contact.Name)
+		if contact.Name == "" {
+			return fmt.Errorf("第%d个联系人的姓名不能为空", i+1)
+		}
+		phones, err := normalizeContactList(contact.Phones, ContactMaxPhones, "电话", func(phone string) (string, bool) {
+			phone, err := NormalizeContactPhone(phone)
+			return phone, err == nil
+		})
+		if err != nil {
+			return fmt.Errorf("联系人 %s: %v", contact.Name, err)
+		}
+		emails, err := normalizeContactList(contact.Emails, ContactMaxEmails, "邮箱", func(email string) (string, bool) {
+			address, err := mail.ParseAddress(email)
+			return email, err == nil && address.Address == email
+		})
+		if err != nil {
+			return fmt.Errorf("联系人 %s: %v", contact.Name, err)
+		}
+		contact.Phones = phones
+		contact.Emails = emails
+		if contact.IsPrimary {
+			if primary >= 0 {
+				return errors.New("只能有一个主联系人")
+			}
+			primary = i
+		}
+	}
+	if primary < 0 && len(contacts) > 0 {
+		contacts[0].IsPrimary = true
+	}
+
+	ids := make([]uint, 0, len(contacts))
+	for _, contact := range contacts {
+		if contact.ID != 0 {
+			ids = append(ids, contact.ID)
+		}
+	}
+	if err := checkCustomerChildIds(tx, &business.CustomerContact{}, customer.ID, ids, "联系人"); err != nil {
+		return err
+	}
+	deleteQuery := tx.Where(&business.CustomerContact{CustomerID: customer.ID})
+	if len(ids) > 0 {
+		deleteQuery = deleteQuery.Where("id NOT IN ?", ids)
+	}
+	if err := deleteQuery.Delete(&business.CustomerContact{}).Error; err != nil {
+		return err
+	}
+	for i := range contacts {
+		contacts[i].CustomerID = customer.ID
+		contacts[i].TenantID = customer.TenantID
+		if err := saveCustomerChild(tx, contacts[i].ID, &contacts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreMaskedContactPhones 已有联系人的电话原样提交了脱敏显示的值时（没有查看完整电话的权限），还原为原来的电话
+func RestoreMaskedContactPhones(tx *gorm.DB, customerId uint, contacts []business.CustomerContact) error {
+	ids := make([]uint, 0, len(contacts))
+	for _, contact := range contacts {
+		if contact.ID != 0 {
+			ids = append(ids, contact.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var existing []business.CustomerContact
+	if err := tx.Select("id", "phones").Where("customer_id = ? AND id IN ?", customerId, ids).Find(&existing).Error; err != nil {
+		return err
+	}
+	phones := make(map[uint][]string, len(existing))
+	for _, contact := range existing {
+		phones[contact.ID] = contact.Phones
+	}
+	for i := range contacts {
+		for j, phone := range contacts[i].Phones {
+			contacts[i].Phones[j] = RestoreMasked(MaskPhone, phone, phones[contacts[i].ID]...)
+		}
+	}
+	return nil
+}
+
+// SaveCustomerAddresses 按请求中的地址列表整体替换客户的地址，规则同 SaveCustomerContacts
+func SaveCustomerAddresses(tx *gorm.DB, customer business.Customer, addresses []business.CustomerAddress) error {
+	if len(addresses) > CustomerMaxAddresses {
+		return fmt.Errorf("地址不能超过%d个", CustomerMaxAddresses)
+	}
+	primary := -1
+	for i := range addresses {
+		address := &addresses[i]
+		address.Detail = strings.TrimSpace(address.Detail)
+		if address.Detail == "" {
+			return fmt.Errorf("第%d个地址的详细地址不能为空", i+1)
+		}
+		if address.IsPrimary {
+			if primary >= 0 {
+				return errors.New("只能有一个默认地址")
+			}
+			primary = i
+		}
+	}
+	if primary < 0 && len(addresses) > 0 {
+		addresses[0].IsPrimary = true
+	}
+
+	ids := make([]uint, 0, len(addresses))
+	for _, address := range addresses {
+		if address.ID != 0 {
+			ids = append(ids, address.ID)
+		}
+	}
+	if err := checkCustomerChildIds(tx, &business.CustomerAddress{}, customer.ID, ids, "地址"); err != nil {
+		return err
+	}
+	deleteQuery := tx.Where(&business.CustomerAddress{CustomerID: customer.ID})
+	if len(ids) > 0 {
+		deleteQuery = deleteQuery.Where("id NOT IN ?", ids)
+	}
+	if err := deleteQuery.Delete(&business.CustomerAddress{}).Error; err != nil {
+		return err
+	}
+	for i := range addresses {
+		addresses[i].CustomerID = customer.ID
+		addresses[i].TenantID = customer.TenantID
+		if err := saveCustomerChild(tx, addresses[i].ID, &addresses[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCustomerChildIds 检查请求中带 ID 的联系人、地址都属于该客户，防止修改其他客户的数据
+func checkCustomerChildIds(tx *gorm.DB, model interface{}, customerId uint, ids []uint, name string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var existing []uint
+	if err := tx.Model(model).Where("customer_id = ? AND id IN ?", customerId, ids).Pluck("id", &existing).Error; err != nil {
+		return err
+	}
+	found := make(map[uint]bool, len(existing))
+	for _, id := range existing {
+		found[id] = true
+	}
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if !found[id] {
+			return fmt.Errorf("%s %d 不存在", name, id)
+		}
+		if seen[id] {
+			return fmt.Errorf("%s %d 重复", name, id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// saveCustomerChild 保存联系人、地址，id 为 0 时新建，否则更新除创建时间外的所有字段
+func saveCustomerChild(tx *gorm.DB, id uint, value interface{}) error {
+	if id == 0 {
+		return tx.Create(value).Error
+	}
+	return tx.Omit("created_at").Save(value).Error
+}
+
+// normalizeContactList 去掉空白，按 normalize 检查格式并转换为规范格式后去掉重复项，再检查数量
+func normalizeContactList(items []string, max int, name string, normalize func(string) (string, bool)) ([]string, error) {
+	result := make([]string, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		normalized, ok := normalize(item)
+		if !ok {
+			return nil, fmt.Errorf("%s %s 格式不正确", name, item)
+		}
+		if seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		result = append(result, normalized)
+	}
+	if len(result) > max {
+		return nil, fmt.Errorf("%s不能超过%d个", name, max)
+	}
+	return result, nil
+}
+
+// NormalizeCustomerTags 去掉空白和重复的标签，检查长度和数量
+// 标签以 JSON 保存并按 LIKE 筛选，不能包含 JSON 中会被转义的字符
+func NormalizeCustomerTags(tags []string) ([]string, error) {
+	return normalizeContactList(tags, CustomerMaxTags, "标签", func(tag string) (string, bool) {
+		return tag, utf8.RuneCountInString(tag) <= CustomerTagMaxLength && !strings.ContainsAny(tag, `"\<>&`)
+	})
+}
+
+// beforePurgeCustomer 彻底删除客户前删除客户的自定义字段值、联系人、地址、跟进记录（附件文件本身保留）和状态历史
+func beforePurgeCustomer(tx *gorm.DB, id uint) error {
+	if err := tx.Where(&business.CustomerStatusHistory{CustomerID: id}).Delete(&business.CustomerStatusHistory{}).Error; err != nil {
+		return err
+	}
+	activityIds := tx.Unscoped().Model(&business.CustomerActivity{}).Select("id").Where("customer_id = ?", id)
+	if err := tx.Exec("DELETE FROM customer_activity_files WHERE customer_activity_id IN (?)", activityIds).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("customer_id = ?", id).Delete(&business.CustomerActivity{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where(&business.CustomerFieldValue{CustomerID: id}).Delete(&business.CustomerFieldValue{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where(&business.CustomerContact{CustomerID: id}).Delete(&business.CustomerContact{}).Error; err != nil {
+		return err
+	}
+	return tx.Where(&business.CustomerAddress{CustomerID: id}).Delete(&business.CustomerAddress{}).Error
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/system"
+)
+
+// ActivityTypeNames 跟进记录类型名称
+var ActivityTypeNames = map[string]string{
+	business.ActivityCall:   "电话",
+	business.ActivityVisit:  "拜访",
+	business.ActivityEmail:  "邮件",
+	business.ActivityNote:   "备注",
+	business.ActivityStatus: "状态变更",
+	business.ActivityAssign: "负责人变更",
+	business.ActivityMerge:  "合并客户",
+}
+
+// ManualActivityTypes 可以人工录入的跟进记录类型，状态变更、负责人变更、合并客户只能由系统记录
+var ManualActivityTypes = map[string]bool{
+	business.ActivityCall:  true,
+	business.ActivityVisit: true,
+	business.ActivityEmail: true,
+	business.ActivityNote:  true,
+}
+
+// RecordCustomerChange 记录客户的状态、负责人等变更，需要与变更在同一事务中调用
+// from、to 为变更前后的值，content 为空时按类型生成说明
+func RecordCustomerChange(tx *gorm.DB, customer business.Customer, activityType string, from, to string, content string, userId uint) error {
+	if content == "" {
+		content = fmt.Sprintf("%s: %s → %s", ActivityTypeNames[activityType], displayActivityValue(from), displayActivityValue(to))
+	}
+	activity := business.CustomerActivity{
+		TenantID:   customer.TenantID,
+		CustomerID: customer.ID,
+		Type:       activityType,
+		Content:    content,
+		FromValue:  from,
+		ToValue:    to,
+		OccurredAt: time.Now(),
+		CreatedBy:  userId,
+	}
+	return tx.Create(&activity).Error
+}
+
+// displayActivityValue 变更说明中空值显示为“无”
+func displayActivityValue(value string) string {
+	if value == "" {
+		return "无"
+	}
+	return value
+}
+
+// FillActivityCreators 填充跟进记录的记录人昵称，已删除的用户也会显示
+func FillActivityCreators(db *gorm.DB, activities []business.CustomerActivity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(activities))
+	for _, activity := range activities {
+		ids = append(ids, activity.CreatedBy)
+	}
+	var users []system.SysUser
+	if err := db.Unscoped().Select("id", "nick_name").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return err
+	}
+	names := make(map[uint]string, len(users))
+	for _, user := range users {
+		names[user.ID] = user.NickName
+	}
+	for i := range activities {
+		activities[i].CreatorName = names[activities[i].CreatedBy]
+	}
+	return nil
+}
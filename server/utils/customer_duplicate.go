@@ -0,0 +1,327 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"jiangyi.com/model/business"
+)
+
+// CustomerDuplicateLimit 新建、修改客户时最多返回的疑似重复客户数
+const CustomerDuplicateLimit = 20
+
+// CustomerMaxMergeSources 一次最多合并的客户数
+const CustomerMaxMergeSources = 10
+
+// CustomerDuplicate 疑似重复的客户，只包含识别客户所需的基本信息
+type CustomerDuplicate struct {
+	ID            uint     `json:"ID"`
+	CustomerName  string   `json:"customerName"`
+	CustomerPhone string   `json:"customerPhone" mask:"phone,customer:view-phone"`
+	OwnerID       uint     `json:"ownerId"`
+	Reasons       []string `json:"reasons"` // 疑似重复的原因
+}
+
+// CustomerDuplicateGroup 重复客户报表中的一组客户
+type CustomerDuplicateGroup struct {
+	Type      string              `json:"type"`                                       // 重复类型 phone-电话相同 name-名称相同
+	Value     string              `json:"value" mask:"phoneText,customer:view-phone"` // 相同的电话或名称，其中的电话按权限脱敏
+	TenantID  uint                `json:"tenantId"`                                   // 租户ID
+	Count     int                 `json:"count"`                                      // 客户数
+	Customers []business.Customer `json:"customers"`                                  // 客户
+}
+
+// CustomerMergeResult 合并客户时转移到保留客户的数据
+type CustomerMergeResult struct {
+	TargetID    uint   `json:"targetId"`    // 保留的客户
+	SourceIds   []uint `json:"sourceIds"`   // 被合并的客户
+	Contacts    int64  `json:"contacts"`    // 转移的联系人数，包括由被合并客户的电话生成的联系人
+	Addresses   int64  `json:"addresses"`   // 转移的地址数
+	Activities  int64  `json:"activities"`  // 转移的跟进记录数（附件随跟进记录转移）
+	FieldValues int64  `json:"fieldValues"` // 转移的自定义字段值数，保留客户已填写的字段不覆盖
+}
+
+// CustomerPhones 客户电话和联系人电话中可用于查重的号码，格式不正确的忽略
+func CustomerPhones(phone string, contacts []business.CustomerContact) []string {
+	phones := []string{}
+	if phone, err := NormalizePhone(phone); err == nil {
+		phones = append(phones, phone)
+	}
+	for _, contact := range contacts {
+		for _, phone := range contact.Phones {
+			if phone, err := NormalizeContactPhone(phone); err == nil && !ContainsString(phones, phone) {
+				phones = append(phones, phone)
+			}
+		}
+	}
+	return phones
+}
+
+// FindCustomerDuplicates 查找租户内名称相同，或客户电话、联系人电话与 phones 中任一号码相同的其他客户
+// excludeId 为修改时的客户自身，不受数据范围限制，以免重复录入他人负责的客户
+func FindCustomerDuplicates(db *gorm.DB, tenantId uint, excludeId uint, name string, phones []string) ([]CustomerDuplicate, error) {
+	name = strings.TrimSpace(name)
+	var customers []business.Customer
+	query := db.Where("tenant_id = ? AND id <> ?", tenantId, excludeId)
+	if len(phones) > 0 {
+		query = query.Where("customer_name = ? OR customer_phone IN ?", name, phones)
+	} else {
+		query = query.Where("customer_name = ?", name)
+	}
+	if err := query.Limit(CustomerDuplicateLimit).Find(&customers).Error; err != nil {
+		return nil, err
+	}
+
+	// 联系人电话以 JSON 数组保存，按带引号的完整号码匹配
+	contactMatches := map[uint]bool{}
+	if len(phones) > 0 {
+		contactQuery := db.Model(&business.CustomerContact{}).Where("tenant_id = ? AND customer_id <> ?", tenantId, excludeId)
+		conditions := db.Where("1 = 0")
+		for _, phone := range phones {
+			conditions = conditions.Or("phones LIKE ?", `%"`+phone+`"%`)
+		}
+		var customerIds []uint
+		if err := contactQuery.Where(conditions).Distinct().Limit(CustomerDuplicateLimit).Pluck("customer_id", &customerIds).Error; err != nil {
+			return nil, err
+		}
+		for _, id := range customerIds {
+			contactMatches[id] = true
+		}
+		found := make(map[uint]bool, len(customers))
+		for _, customer := range customers {
+			found[customer.ID] = true
+		}
+		missing := []uint{}
+		for _, id := range customerIds {
+			if !found[id] {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) > 0 {
+			var more []business.Customer
+			if err := db.Where("id IN ?", missing).Find(&more).Error; err != nil {
+				return nil, err
+			}
+			customers = append(customers, more...)
+		}
+	}
+
+	duplicates := make([]CustomerDuplicate, 0, len(customers))
+	for _, customer := range customers {
+		duplicate := CustomerDuplicate{
+			ID:            customer.ID,
+			CustomerName:  customer.CustomerName,
+			CustomerPhone: customer.CustomerPhone,
+			OwnerID:       customer.OwnerID,
+			Reasons:       []string{},
+		}
+		if name != "" && customer.CustomerName == name {
+			duplicate.Reasons = append(duplicate.Reasons, "名称相同")
+		}
+		if ContainsString(phones, customer.CustomerPhone) {
+			duplicate.Reasons = append(duplicate.Reasons, "电话相同")
+		}
+		if contactMatches[customer.ID] {
+			duplicate.Reasons = append(duplicate.Reasons, "联系人电话相同")
+		}
+		if len(duplicate.Reasons) > 0 {
+			duplicates = append(duplicates, duplicate)
+		}
+	}
+	if len(duplicates) > CustomerDuplicateLimit {
+		duplicates = duplicates[:CustomerDuplicateLimit]
+	}
+	return duplicates, nil
+}
+
+// FindCustomerDuplicateGroups 按电话、名称分组查找重复的客户，query 为已按租户和数据范围过滤的客户查询
+// groupType 为空时两种都查，结果按客户数倒序
+func FindCustomerDuplicateGroups(query *gorm.DB, groupType string) ([]CustomerDuplicateGroup, error) {
+	columns := map[string]string{"phone": "customer_phone", "name": "customer_name"}
+	groups := []CustomerDuplicateGroup{}
+	for _, t := range []string{"phone", "name"} {
+		if groupType != "" && groupType != t {
+			continue
+		}
+		var rows []struct {
+			TenantID uint
+			Value    string
+			Count    int
+		}
+		err := query.Session(&gorm.Session{}).Model(&business.Customer{}).
+			Select("tenant_id, " + columns[t] + " AS value, COUNT(*) AS count").
+			Where(columns[t] + " <> ''").
+			Group("tenant_id, " + columns[t]).Having("COUNT(*) > 1").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			groups = append(groups, CustomerDuplicateGroup{Type: t, Value: row.Value, TenantID: row.TenantID, Count: row.Count})
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Value < groups[j].Value
+	})
+	return groups, nil
+}
+
+// FillCustomerDuplicateGroups 查询每组中的客户，query 与 FindCustomerDuplicateGroups 相同
+func FillCustomerDuplicateGroups(query *gorm.DB, groups []CustomerDuplicateGroup) error {
+	columns := map[string]string{"phone": "customer_phone", "name": "customer_name"}
+	for i := range groups {
+		err := query.Session(&gorm.Session{}).
+			Where("tenant_id = ? AND "+columns[groups[i].Type]+" = ?", groups[i].TenantID, groups[i].Value).
+			Order("id ASC").Find(&groups[i].Customers).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MergeCustomers 将 sources 合并到 target：联系人、地址、跟进记录（含附件）转移到 target，标签取并集，
+// target 未填写的自定义字段取被合并客户的值，被合并客户的电话与 target 不同时生成一个联系人，
+// 被合并的客户软删除进入回收站，并在 target 的时间线中记录。需要在事务中调用
+func MergeCustomers(tx *gorm.DB, target business.Customer, sources []business.Customer, userId uint) (CustomerMergeResult, error) {
+	result := CustomerMergeResult{TargetID: target.ID}
+	sourceIds := make([]uint, 0, len(sources))
+	for _, source := range sources {
+		sourceIds = append(sourceIds, source.ID)
+	}
+	result.SourceIds = sourceIds
+
+	// 每个被合并的客户最多生成一个联系人
+	customerIds := append([]uint{target.ID}, sourceIds...)
+	var contacts, addresses int64
+	if err := tx.Model(&business.CustomerContact{}).Where("customer_id IN ?", customerIds).Count(&contacts).Error; err != nil {
+		return result, err
+	}
+	if err := tx.Model(&business.CustomerAddress{}).Where("customer_id IN ?", customerIds).Count(&addresses).Error; err != nil {
+		return result, err
+	}
+	if contacts+int64(len(sources)) > CustomerMaxContacts {
+		return result, fmt.Errorf("合并后联系人会超过%d个，请先整理联系人", CustomerMaxContacts)
+	}
+	if addresses > CustomerMaxAddresses {
+		return result, fmt.Errorf("合并后地址会超过%d个，请先整理地址", CustomerMaxAddresses)
+	}
+
+	tags := append([]string{}, target.Tags...)
+	for _, source := range sources {
+		for _, tag := range source.Tags {
+			if !ContainsString(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	if len(tags) > CustomerMaxTags {
+		return result, fmt.Errorf("合并后标签会超过%d个，请先整理标签", CustomerMaxTags)
+	}
+
+	var targetFieldIds []uint
+	if err := tx.Model(&business.CustomerFieldValue{}).Where(&business.CustomerFieldValue{CustomerID: target.ID}).Distinct().Pluck("field_id", &targetFieldIds).Error; err != nil {
+		return result, err
+	}
+	lastFollowedAt := target.LastFollowedAt
+
+	for _, source := range sources {
+		if source.TenantID != target.TenantID {
+			return result, errors.New("不能合并不同租户的客户")
+		}
+
+		updated := tx.Model(&business.CustomerContact{}).Where(&business.CustomerContact{CustomerID: source.ID}).
+			Updates(map[string]interface{}{"customer_id": target.ID, "is_primary": false})
+		if updated.Error != nil {
+			return result, updated.Error
+		}
+		result.Contacts += updated.RowsAffected
+
+		// 被合并客户的电话作为联系人保留，已有联系人使用该号码时不重复添加
+		if source.CustomerPhone != "" && source.CustomerPhone != target.CustomerPhone {
+			var count int64
+			err := tx.Model(&business.CustomerContact{}).Where("customer_id = ? AND phones LIKE ?", target.ID, `%"`+source.CustomerPhone+`"%`).Count(&count).Error
+			if err != nil {
+				return result, err
+			}
+			if count == 0 {
+				contact := business.CustomerContact{
+					TenantID:   target.TenantID,
+					CustomerID: target.ID,
+					Name:       source.CustomerName,
+					Phones:     []string{source.CustomerPhone},
+					Emails:     []string{},
+					Remark:     "合并客户时由被合并客户的电话生成",
+				}
+				if err = tx.Create(&contact).Error; err != nil {
+					return result, err
+				}
+				result.Contacts++
+			}
+		}
+
+		updated = tx.Model(&business.CustomerAddress{}).Where(&business.CustomerAddress{CustomerID: source.ID}).
+			Updates(map[string]interface{}{"customer_id": target.ID, "is_primary": false})
+		if updated.Error != nil {
+			return result, updated.Error
+		}
+		result.Addresses += updated.RowsAffected
+
+		updated = tx.Model(&business.CustomerActivity{}).Where(&business.CustomerActivity{CustomerID: source.ID}).Update("customer_id", target.ID)
+		if updated.Error != nil {
+			return result, updated.Error
+		}
+		result.Activities += updated.RowsAffected
+
+		// 自定义字段：保留客户已填写的不覆盖，未填写的取先合并的客户的值
+		var fieldIds []uint
+		if err := tx.Model(&business.CustomerFieldValue{}).Where(&business.CustomerFieldValue{CustomerID: source.ID}).Distinct().Pluck("field_id", &fieldIds).Error; err != nil {
+			return result, err
+		}
+		moveFieldIds := []uint{}
+		for _, fieldId := range fieldIds {
+			if !ContainsUint(targetFieldIds, fieldId) {
+				moveFieldIds = append(moveFieldIds, fieldId)
+				targetFieldIds = append(targetFieldIds, fieldId)
+			}
+		}
+		if len(moveFieldIds) > 0 {
+			updated = tx.Model(&business.CustomerFieldValue{}).Where("customer_id = ? AND field_id IN ?", source.ID, moveFieldIds).Update("customer_id", target.ID)
+			if updated.Error != nil {
+				return result, updated.Error
+			}
+			result.FieldValues += updated.RowsAffected
+		}
+
+		if source.LastFollowedAt != nil && (lastFollowedAt == nil || source.LastFollowedAt.After(*lastFollowedAt)) {
+			lastFollowedAt = source.LastFollowedAt
+		}
+
+		if err := tx.Delete(&source).Error; err != nil {
+			return result, err
+		}
+		content := fmt.Sprintf("%s: %s（ID:%d）合并到本客户", ActivityTypeNames[business.ActivityMerge], source.CustomerName, source.ID)
+		if err := RecordCustomerChange(tx, target, business.ActivityMerge, strconv.Itoa(int(source.ID)), strconv.Itoa(int(target.ID)), content, userId); err != nil {
+			return result, err
+		}
+	}
+
+	if len(tags) != len(target.Tags) {
+		if err := tx.Model(&business.Customer{}).Where("id = ?", target.ID).Select("tags").Updates(business.Customer{Tags: tags}).Error; err != nil {
+			return result, err
+		}
+	}
+	if lastFollowedAt != target.LastFollowedAt {
+		if err := tx.Model(&business.Customer{}).Where("id = ?", target.ID).Update("last_followed_at", lastFollowedAt).Error; err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
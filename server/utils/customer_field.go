@@ -0,0 +1,554 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/system"
+)
+
+// CustomerFieldMaxLength 自定义字段值（文本、可选项）的最大长度，与字段值表的列长度一致
+const CustomerFieldMaxLength = 255
+
+// customerFieldDateLayout 日期字段的格式
+const customerFieldDateLayout = "2006-01-02"
+
+// customerFieldKeyPattern 字段标识格式：字母开头，只能包含字母、数字和下划线
+var customerFieldKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,63}$`)
+
+var customerFieldTypes = map[string]bool{
+	business.CustomerFieldText:        true,
+	business.CustomerFieldNumber:      true,
+	business.CustomerFieldDate:        true,
+	business.CustomerFieldSelect:      true,
+	business.CustomerFieldMultiSelect: true,
+	business.CustomerFieldUser:        true,
+}
+
+// ValidateCustomerField 检查自定义字段定义是否合法
+func ValidateCustomerField(field business.CustomerField) error {
+	if !customerFieldKeyPattern.MatchString(field.FieldKey) {
+		return errors.New("字段标识必须以字母开头，只能包含字母、数字和下划线，且不超过64个字符")
+	}
+	if strings.TrimSpace(field.Name) == "" {
+		return errors.New("字段名称不能为空")
+	}
+	if !customerFieldTypes[field.Type] {
+		return fmt.Errorf("不支持的字段类型: %s", field.Type)
+	}
+
+	isSelect := field.Type == business.CustomerFieldSelect || field.Type == business.CustomerFieldMultiSelect
+	if isSelect {
+		if len(field.Options) == 0 {
+			return errors.New("单选、多选字段至少需要一个可选项")
+		}
+		seen := make(map[string]bool, len(field.Options))
+		for _, option := range field.Options {
+			if option == "" || utf8.RuneCountInString(option) > CustomerFieldMaxLength {
+				return fmt.Errorf("可选项不能为空且不能超过%d个字符", CustomerFieldMaxLength)
+			}
+			if seen[option] {
+				return fmt.Errorf("可选项 %s 重复", option)
+			}
+			seen[option] = true
+		}
+	} else if len(field.Options) > 0 {
+		return errors.New("只有单选、多选字段可以设置可选项")
+	}
+	if field.Unique && field.Type == business.CustomerFieldMultiSelect {
+		return errors.New("多选字段不能设置为唯一")
+	}
+
+	rules := field.Rules
+	if rules.MinLength != nil && rules.MaxLength != nil && *rules.MinLength > *rules.MaxLength {
+		return errors.New("最小长度不能大于最大长度")
+	}
+	if rules.MaxLength != nil && *rules.MaxLength > CustomerFieldMaxLength {
+		return fmt.Errorf("最大长度不能超过%d", CustomerFieldMaxLength)
+	}
+	if rules.Pattern != "" {
+		if _, err := regexp.Compile(rules.Pattern); err != nil {
+			return fmt.Errorf("正则表达式错误: %v", err)
+		}
+	}
+	if rules.Minimum != nil && rules.Maximum != nil && *rules.Minimum > *rules.Maximum {
+		return errors.New("最小值不能大于最大值")
+	}
+	for _, date := range []string{rules.MinDate, rules.MaxDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(customerFieldDateLayout, date); err != nil {
+			return fmt.Errorf("日期 %s 格式错误，应为 YYYY-MM-DD", date)
+		}
+	}
+	if rules.MinDate != "" && rules.MaxDate != "" && rules.MinDate > rules.MaxDate {
+		return errors.New("最早日期不能晚于最晚日期")
+	}
+	if rules.MinItems != nil && rules.MaxItems != nil && *rules.MinItems > *rules.MaxItems {
+		return errors.New("最少选择项数不能大于最多选择项数")
+	}
+	return nil
+}
+
+// GetCustomerFields 获取租户的全部自定义字段定义
+func GetCustomerFields(db *gorm.DB, tenantId uint) ([]business.CustomerField, error) {
+	var fields []business.CustomerField
+	err := db.Where(&business.CustomerField{TenantID: tenantId}).Order("sort ASC, id ASC").Find(&fields).Error
+	return fields, err
+}
+
+// SaveCustomerFieldValues 校验并保存客户的自定义字段值
+// values 只包含需要修改的字段，值为 null 表示清空；creating 为 true 时检查所有必填字段，否则只检查本次修改的字段
+// 所有字段都校验通过后才会写入，需要与客户的创建、更新在同一事务中调用
+func SaveCustomerFieldValues(tx *gorm.DB, customer business.Customer, values map[string]json.RawMessage, creating bool) error {
+	fields, err := GetCustomerFields(tx, customer.TenantID)
+	if err != nil {
+		return errors.New("获取自定义字段失败")
+	}
+	fieldMap := make(map[string]business.CustomerField, len(fields))
+	for _, field := range fields {
+		fieldMap[field.FieldKey] = field
+	}
+	for key := range values {
+		if _, ok := fieldMap[key]; !ok {
+			return fmt.Errorf("自定义字段 %s 不存在", key)
+		}
+	}
+
+	changes := make(map[uint][]business.CustomerFieldValue)
+	for _, field := range fields {
+		raw, present := values[field.FieldKey]
+		if !present {
+			if creating && field.Required {
+				return fmt.Errorf("%s不能为空", field.Name)
+			}
+			continue
+		}
+		rows, err := parseCustomerFieldValue(tx, field, customer.TenantID, raw)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 && field.Required {
+			return fmt.Errorf("%s不能为空", field.Name)
+		}
+		if field.Unique && len(rows) > 0 {
+			// 锁定字段到事务结束，同一唯一字段的检查和写入依次执行，避免并发保存相同的值
+			if err := LockCustomerField(tx, field.ID); err != nil {
+				return err
+			}
+			conflict, err := customerFieldConflict(tx, field.ID, rows[0].Value, customer.ID)
+			if err != nil {
+				return err
+			}
+			if conflict {
+				return fmt.Errorf("%s %s 已被其他客户使用", field.Name, rows[0].Value)
+			}
+		}
+		for i := range rows {
+			rows[i].TenantID = customer.TenantID
+			rows[i].CustomerID = customer.ID
+			rows[i].FieldID = field.ID
+		}
+		changes[field.ID] = rows
+	}
+
+	for fieldId, rows := range changes {
+		if err := tx.Where(&business.CustomerFieldValue{CustomerID: customer.ID, FieldID: fieldId}).Delete(&business.CustomerFieldValue{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseCustomerFieldValue 按字段类型和校验规则解析字段值，返回待保存的值（多选每个选项一行），空值返回 nil
+func parseCustomerFieldValue(tx *gorm.DB, field business.CustomerField, tenantId uint, raw json.RawMessage) ([]business.CustomerFieldValue, error) {
+	if isJSONNull(raw) {
+		return nil, nil
+	}
+	rules := field.Rules
+	switch field.Type {
+	case business.CustomerFieldText:
+		var str string
+		if err := json.Unmarshal(raw, &str); err != nil {
+			return nil, fmt.Errorf("%s应为文本", field.Name)
+		}
+		str = strings.TrimSpace(str)
+		if str == "" {
+			return nil, nil
+		}
+		length := utf8.RuneCountInString(str)
+		if rules.MinLength != nil && length < *rules.MinLength {
+			return nil, fmt.Errorf("%s长度不能少于%d", field.Name, *rules.MinLength)
+		}
+		maxLength := CustomerFieldMaxLength
+		if rules.MaxLength != nil {
+			maxLength = *rules.MaxLength
+		}
+		if length > maxLength {
+			return nil, fmt.Errorf("%s长度不能超过%d", field.Name, maxLength)
+		}
+		if rules.Pattern != "" {
+			if matched, _ := regexp.MatchString(rules.Pattern, str); !matched {
+				return nil, fmt.Errorf("%s格式不正确", field.Name)
+			}
+		}
+		return []business.CustomerFieldValue{{Value: str}}, nil
+
+	case business.CustomerFieldNumber:
+		var num float64
+		if err := json.Unmarshal(raw, &num); err != nil {
+			return nil, fmt.Errorf("%s应为数字", field.Name)
+		}
+		if rules.Integer && num != math.Trunc(num) {
+			return nil, fmt.Errorf("%s应为整数", field.Name)
+		}
+		if rules.Minimum != nil && num < *rules.Minimum {
+			return nil, fmt.Errorf("%s不能小于%v", field.Name, *rules.Minimum)
+		}
+		if rules.Maximum != nil && num > *rules.Maximum {
+			return nil, fmt.Errorf("%s不能大于%v", field.Name, *rules.Maximum)
+		}
+		return []business.CustomerFieldValue{{Value: strconv.FormatFloat(num, 'f', -1, 64), NumberValue: &num}}, nil
+
+	case business.CustomerFieldDate:
+		var str string
+		if err := json.Unmarshal(raw, &str); err != nil {
+			return nil, fmt.Errorf("%s应为日期", field.Name)
+		}
+		if str == "" {
+			return nil, nil
+		}
+		if _, err := time.Parse(customerFieldDateLayout, str); err != nil {
+			return nil, fmt.Errorf("%s日期格式错误，应为 YYYY-MM-DD", field.Name)
+		}
+		// 日期按 YYYY-MM-DD 保存，字符串比较即可得到先后顺序
+		if rules.MinDate != "" && str < rules.MinDate {
+			return nil, fmt.Errorf("%s不能早于%s", field.Name, rules.MinDate)
+		}
+		if rules.MaxDate != "" && str > rules.MaxDate {
+			return nil, fmt.Errorf("%s不能晚于%s", field.Name, rules.MaxDate)
+		}
+		return []business.CustomerFieldValue{{Value: str}}, nil
+
+	case business.CustomerFieldSelect:
+		var str string
+		if err := json.Unmarshal(raw, &str); err != nil {
+			return nil, fmt.Errorf("%s应为文本", field.Name)
+		}
+		if str == "" {
+			return nil, nil
+		}
+		if !ContainsString(field.Options, str) {
+			return nil, fmt.Errorf("%s取值必须是 %v 之一", field.Name, field.Options)
+		}
+		return []business.CustomerFieldValue{{Value: str}}, nil
+
+	case business.CustomerFieldMultiSelect:
+		var items []string
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, fmt.Errorf("%s应为文本数组", field.Name)
+		}
+		rows := make([]business.CustomerFieldValue, 0, len(items))
+		seen := make(map[string]bool, len(items))
+		for _, item := range items {
+			if !ContainsString(field.Options, item) {
+				return nil, fmt.Errorf("%s取值必须是 %v 之一", field.Name, field.Options)
+			}
+			if seen[item] {
+				continue
+			}
+			seen[item] = true
+			rows = append(rows, business.CustomerFieldValue{Value: item})
+		}
+		if len(rows) == 0 {
+			return nil, nil
+		}
+		if rules.MinItems != nil && len(rows) < *rules.MinItems {
+			return nil, fmt.Errorf("%s至少需要选择%d项", field.Name, *rules.MinItems)
+		}
+		if rules.MaxItems != nil && len(rows) > *rules.MaxItems {
+			return nil, fmt.Errorf("%s最多只能选择%d项", field.Name, *rules.MaxItems)
+		}
+		return rows, nil
+
+	case business.CustomerFieldUser:
+		var userId uint
+		if err := json.Unmarshal(raw, &userId); err != nil {
+			return nil, fmt.Errorf("%s应为用户ID", field.Name)
+		}
+		if userId == 0 {
+			return nil, nil
+		}
+		var count int64
+		err := tx.Model(&system.SysUser{}).Where(&system.SysUser{TenantId: tenantId}).Where("id = ?", userId).Count(&count).Error
+		if err != nil {
+			return nil, errors.New("查询用户失败")
+		}
+		if count == 0 {
+			return nil, fmt.Errorf("%s: 用户 %d 不存在", field.Name, userId)
+		}
+		return []business.CustomerFieldValue{{Value: strconv.FormatUint(uint64(userId), 10)}}, nil
+	}
+	return nil, fmt.Errorf("不支持的字段类型: %s", field.Type)
+}
+
+// customerFieldConflict 检查唯一字段的值是否已被其他未删除的客户使用
+// 加锁读取最新提交的数据，不使用事务开始时的快照，需要先通过 LockCustomerField 锁定字段
+func customerFieldConflict(tx *gorm.DB, fieldId uint, value string, customerId uint) (bool, error) {
+	var count int64
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Model(&business.CustomerFieldValue{}).
+		Joins("JOIN customers ON customers.id = customer_field_values.customer_id AND customers.deleted_at IS NULL").
+		Where("customer_field_values.field_id = ? AND customer_field_values.value = ? AND customer_field_values.customer_id <> ?", fieldId, value, customerId).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.New("检查字段唯一性失败")
+	}
+	return count > 0, nil
+}
+
+// LockCustomerField 锁定自定义字段直到事务结束，唯一字段的值在检查和写入期间不会被其他事务修改。需要在事务中调用
+func LockCustomerField(tx *gorm.DB, fieldId uint) error {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", fieldId).Find(&business.CustomerField{}).Error
+	if err != nil {
+		return errors.New("锁定自定义字段失败")
+	}
+	return nil
+}
+
+// CheckCustomerFieldUnique 字段改为唯一前检查现有客户中是否已有重复的值，返回第一个重复的值
+func CheckCustomerFieldUnique(db *gorm.DB, fieldId uint) (string, error) {
+	var values []string
+	err := db.Model(&business.CustomerFieldValue{}).
+		Joins("JOIN customers ON customers.id = customer_field_values.customer_id AND customers.deleted_at IS NULL").
+		Where("customer_field_values.field_id = ?", fieldId).
+		Group("customer_field_values.value").
+		Having("COUNT(*) > 1").
+		Limit(1).
+		Pluck("customer_field_values.value", &values).Error
+	if err != nil || len(values) == 0 {
+		return "", err
+	}
+	return values[0], nil
+}
+
+// FillCustomerFields 为客户列表填充自定义字段值，未填写的字段值为 null
+func FillCustomerFields(db *gorm.DB, customers []business.Customer) error {
+	if len(customers) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(customers))
+	tenantIds := make([]uint, 0)
+	seenTenant := make(map[uint]bool)
+	for _, customer := range customers {
+		ids = append(ids, customer.ID)
+		if !seenTenant[customer.TenantID] {
+			seenTenant[customer.TenantID] = true
+			tenantIds = append(tenantIds, customer.TenantID)
+		}
+	}
+
+	var fields []business.CustomerField
+	if err := db.Where("tenant_id IN ?", tenantIds).Order("sort ASC, id ASC").Find(&fields).Error; err != nil {
+		return err
+	}
+	var values []business.CustomerFieldValue
+	if err := db.Where("customer_id IN ?", ids).Order("id ASC").Find(&values).Error; err != nil {
+		return err
+	}
+	valueMap := make(map[uint]map[uint][]business.CustomerFieldValue, len(customers))
+	for _, value := range values {
+		if valueMap[value.CustomerID] == nil {
+			valueMap[value.CustomerID] = make(map[uint][]business.CustomerFieldValue)
+		}
+		valueMap[value.CustomerID][value.FieldID] = append(valueMap[value.CustomerID][value.FieldID], value)
+	}
+
+	for i := range customers {
+		customFields := make(map[string]interface{})
+		for _, field := range fields {
+			if field.TenantID != customers[i].TenantID {
+				continue
+			}
+			customFields[field.FieldKey] = customerFieldOutput(field, valueMap[customers[i].ID][field.ID])
+		}
+		customers[i].CustomFields = customFields
+	}
+	return nil
+}
+
+// customerFieldOutput 将保存的字段值转换为接口返回的类型
+func customerFieldOutput(field business.CustomerField, rows []business.CustomerFieldValue) interface{} {
+	if field.Type == business.CustomerFieldMultiSelect {
+		items := make([]string, 0, len(rows))
+		for _, row := range rows {
+			items = append(items, row.Value)
+		}
+		return items
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	switch field.Type {
+	case business.CustomerFieldNumber:
+		if rows[0].NumberValue != nil {
+			return *rows[0].NumberValue
+		}
+	case business.CustomerFieldUser:
+		if id, err := strconv.ParseUint(rows[0].Value, 10, 64); err == nil {
+			return uint(id)
+		}
+	}
+	return rows[0].Value
+}
+
+// CustomerFieldFilter 按自定义字段筛选客户，filters 为 字段标识 -> 筛选值
+// 文本模糊匹配；数字、日期支持精确值或 min~max 范围（可省略一端）；单选、多选、用户支持逗号分隔的多个值，满足其一即可
+// 字段定义按当前请求的租户查找，超级管理员跨租户查询时匹配所有租户中同标识的字段
+func CustomerFieldFilter(c *gin.Context, filters map[string]string) (func(db *gorm.DB) *gorm.DB, error) {
+	conditions := make([]*gorm.DB, 0, len(filters))
+	for key, filter := range filters {
+		var fields []business.CustomerField
+		if err := global.JY_DB.WithContext(c).Where(&business.CustomerField{FieldKey: key}).Find(&fields).Error; err != nil {
+			return nil, errors.New("获取自定义字段失败")
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("自定义字段 %s 不存在", key)
+		}
+
+		var group *gorm.DB
+		for _, field := range fields {
+			condition, err := customerFieldCondition(field, filter)
+			if err != nil {
+				return nil, err
+			}
+			if group == nil {
+				group = global.JY_DB.Where(condition)
+			} else {
+				group = group.Or(condition)
+			}
+		}
+		conditions = append(conditions, global.JY_DB.Model(&business.CustomerFieldValue{}).Select("customer_id").Where(group))
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		for _, condition := range conditions {
+			db = db.Where("id IN (?)", condition)
+		}
+		return db
+	}, nil
+}
+
+// customerFieldCondition 单个字段的筛选条件
+func customerFieldCondition(field business.CustomerField, filter string) (*gorm.DB, error) {
+	condition := global.JY_DB.Where("field_id = ?", field.ID)
+	switch field.Type {
+	case business.CustomerFieldText:
+		return condition.Where("value LIKE ?", "%"+filter+"%"), nil
+
+	case business.CustomerFieldNumber:
+		min, max, isRange := strings.Cut(filter, "~")
+		if !isRange {
+			max = min
+		}
+		for _, bound := range []struct {
+			value string
+			op    string
+		}{{min, ">="}, {max, "<="}} {
+			if bound.value == "" {
+				continue
+			}
+			num, err := strconv.ParseFloat(bound.value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s的筛选值 %s 不是数字", field.Name, bound.value)
+			}
+			condition = condition.Where("number_value "+bound.op+" ?", num)
+		}
+		return condition, nil
+
+	case business.CustomerFieldDate:
+		min, max, isRange := strings.Cut(filter, "~")
+		if !isRange {
+			max = min
+		}
+		for _, bound := range []struct {
+			value string
+			op    string
+		}{{min, ">="}, {max, "<="}} {
+			if bound.value == "" {
+				continue
+			}
+			if _, err := time.Parse(customerFieldDateLayout, bound.value); err != nil {
+				return nil, fmt.Errorf("%s的筛选值 %s 日期格式错误，应为 YYYY-MM-DD", field.Name, bound.value)
+			}
+			condition = condition.Where("value "+bound.op+" ?", bound.value)
+		}
+		return condition, nil
+
+	default:
+		return condition.Where("value IN ?", strings.Split(filter, ",")), nil
+	}
+}
+
+// checkRestoreCustomer 恢复客户前检查唯一字段的值是否已被其他客户使用
+func checkRestoreCustomer(tx *gorm.DB, id uint) error {
+	var values []business.CustomerFieldValue
+	if err := tx.Where(&business.CustomerFieldValue{CustomerID: id}).Find(&values).Error; err != nil {
+		return err
+	}
+	for _, value := range values {
+		var field business.CustomerField
+		if err := tx.Where("id = ?", value.FieldID).First(&field).Error; err != nil || !field.Unique {
+			continue
+		}
+		if err := LockCustomerField(tx, field.ID); err != nil {
+			return err
+		}
+		conflict, err := customerFieldConflict(tx, field.ID, value.Value, id)
+		if err != nil {
+			return err
+		}
+		if conflict {
+			return fmt.Errorf("%s %s 已被其他客户使用，无法恢复", field.Name, value.Value)
+		}
+	}
+	return nil
+}
+
+// ContainsString 判断切片中是否包含指定字符串
+func ContainsString(list []string, target string) bool {
+	for _, item := range list {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsUint 判断切片中是否包含指定ID
+func ContainsUint(list []uint, target uint) bool {
+	for _, item := range list {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
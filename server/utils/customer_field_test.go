@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+	"jiangyi.com/model/business"
+)
+
+func TestSaveCustomerFieldValuesConcurrentUnique(t *testing.T) {
+	db := newTestDB(t, &business.Customer{}, &business.CustomerField{}, &business.CustomerFieldValue{})
+	field := business.CustomerField{TenantID: 1, FieldKey: "license", Name: "营业执照号", Type: business.CustomerFieldText, Unique: true}
+	if err := db.Create(&field).Error; err != nil {
+		t.Fatal(err)
+	}
+	customers := make([]business.Customer, 8)
+	for i := range customers {
+		customers[i] = business.Customer{CustomerName: fmt.Sprintf("客户%d", i), TenantID: 1}
+		if err := db.Create(&customers[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 多个客户同时保存相同的唯一字段值，最多只能有一个成功
+	var wg sync.WaitGroup
+	for _, customer := range customers {
+		wg.Add(1)
+		go func(customer business.Customer) {
+			defer wg.Done()
+			_ = db.Transaction(func(tx *gorm.DB) error {
+				return SaveCustomerFieldValues(tx, customer, map[string]json.RawMessage{"license": json.RawMessage(`"91310000X"`)}, false)
+			})
+		}(customer)
+	}
+	wg.Wait()
+
+	var count int64
+	if err := db.Model(&business.CustomerFieldValue{}).Where("field_id = ? AND value = ?", field.ID, "91310000X").Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count > 1 {
+		t.Errorf("使用相同值的客户 = %d, want <= 1", count)
+	}
+}
+
+func TestRestoreTrashCustomerUniqueField(t *testing.T) {
+	db := newTestDB(t, &business.Customer{}, &business.CustomerField{}, &business.CustomerFieldValue{})
+	field := business.CustomerField{TenantID: 1, FieldKey: "license", Name: "营业执照号", Type: business.CustomerFieldText, Unique: true}
+	if err := db.Create(&field).Error; err != nil {
+		t.Fatal(err)
+	}
+	deleted := business.Customer{CustomerName: "已删除", TenantID: 1}
+	other := business.Customer{CustomerName: "其他", TenantID: 1}
+	for _, customer := range []*business.Customer{&deleted, &other} {
+		if err := db.Create(customer).Error; err != nil {
+			t.Fatal(err)
+		}
+		value := business.CustomerFieldValue{TenantID: 1, CustomerID: customer.ID, FieldID: field.ID, Value: "91310000X"}
+		if err := db.Create(&value).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Delete(&deleted).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	result := RestoreTrash(db, TrashResources["customer"], []uint{deleted.ID}, nil)
+	if result.Success != 0 || len(result.Messages) != 1 || result.Messages[0].Message != "营业执照号 91310000X 已被其他客户使用，无法恢复" {
+		t.Fatalf("RestoreTrash() = %+v", result)
+	}
+
+	// 其他客户删除后可以恢复
+	if err := db.Delete(&other).Error; err != nil {
+		t.Fatal(err)
+	}
+	result = RestoreTrash(db, TrashResources["customer"], []uint{deleted.ID}, nil)
+	if result.Success != 1 || len(result.Messages) != 0 {
+		t.Fatalf("RestoreTrash() = %+v", result)
+	}
+}
@@ -0,0 +1,388 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+)
+
+const (
+	CustomerImportMaxRows  = 50000 // 单次导入的最大行数
+	CustomerImportSyncRows = 500   // 不超过该行数时在请求中直接执行，超过时在后台执行
+	customerImportBatch    = 200   // 部分导入模式下每批提交的行数
+)
+
+// errImportRollback 仅校验或全部导入模式下有错误时回滚事务
+var errImportRollback = errors.New("rollback")
+
+// customerImportJobs 执行中的导入任务，进度只保存在内存中，完成后写入数据库
+// 全部导入模式在一个事务中执行，SQLite 下事务期间无法查询数据库，轮询进度时从这里读取
+var customerImportJobs sync.Map
+
+type customerImportState struct {
+	mu  sync.Mutex
+	job business.CustomerImportJob
+}
+
+// RunningCustomerImport 获取执行中的导入任务的当前进度
+func RunningCustomerImport(id uint) (business.CustomerImportJob, bool) {
+	value, ok := customerImportJobs.Load(id)
+	if !ok {
+		return business.CustomerImportJob{}, false
+	}
+	state := value.(*customerImportState)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return state.job, true
+}
+
+// customerImportRow 解析后的一行
+type customerImportRow struct {
+	customer  business.Customer
+	fields    map[string]json.RawMessage
+	contacts  []business.CustomerContact
+	addresses []business.CustomerAddress
+}
+
+// customerImporter 一次导入任务中共用的数据
+type customerImporter struct {
+	job      *business.CustomerImportJob
+	columns  map[string]int // 列标识 -> 列序号
+	fields   map[string]business.CustomerField
+	workflow business.CustomerWorkflow
+	users    map[string]uint
+	names    map[string]int // 已有客户和文件中已导入的客户名称 -> 行号，已有客户为 0
+	phones   map[string]int // 已有客户和文件中已导入的客户电话 -> 行号，已有客户为 0
+}
+
+// RunCustomerImport 执行导入任务，rows 为不含表头的数据行。任务状态和结果写入数据库，失败的行写入 CustomerImportError
+func RunCustomerImport(db *gorm.DB, job business.CustomerImportJob, rows [][]string) {
+	state := &customerImportState{job: job}
+	customerImportJobs.Store(job.ID, state)
+	defer customerImportJobs.Delete(job.ID)
+
+	update := func(change func(job *business.CustomerImportJob)) {
+		state.mu.Lock()
+		change(&state.job)
+		state.mu.Unlock()
+	}
+	finish := func(status, message string) {
+		now := time.Now()
+		update(func(job *business.CustomerImportJob) {
+			job.Status = status
+			job.Message = message
+			job.FinishedAt = &now
+		})
+		state.mu.Lock()
+		job := state.job
+		state.mu.Unlock()
+		if err := db.Save(&job).Error; err != nil {
+			global.JY_LOG.Error("保存客户导入任务失败", zap.Uint("job_id", job.ID), zap.Error(err))
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			global.JY_LOG.Error("客户导入任务异常", zap.Uint("job_id", job.ID), zap.Any("error", r))
+			finish(business.ImportStatusFailed, fmt.Sprintf("导入任务异常: %v", r))
+		}
+	}()
+
+	update(func(job *business.CustomerImportJob) { job.Status = business.ImportStatusRunning })
+	db.Model(&business.CustomerImportJob{}).Where("id = ?", job.ID).Update("status", business.ImportStatusRunning)
+
+	importer, err := newCustomerImporter(db, &job)
+	if err != nil {
+		finish(business.ImportStatusFailed, err.Error())
+		return
+	}
+
+	var failedRows []business.CustomerImportError
+	handle := func(tx *gorm.DB, i int, row []string) {
+		line := i + 2
+		parsed, errs := importer.parse(line, row)
+		if len(errs) == 0 {
+			// 每行使用保存点，单行失败只回滚该行
+			err := tx.Transaction(func(tx *gorm.DB) error {
+				return importer.create(tx, parsed)
+			})
+			if err != nil {
+				errs = append(errs, err.Error())
+			} else {
+				importer.names[parsed.customer.CustomerName] = line
+				importer.phones[parsed.customer.CustomerPhone] = line
+			}
+		}
+		if len(errs) > 0 {
+			failedRows = append(failedRows, business.CustomerImportError{JobID: job.ID, Row: line, Cells: row, Errors: errs})
+		}
+		update(func(job *business.CustomerImportJob) {
+			job.Processed++
+			if len(errs) > 0 {
+				job.Failed++
+			} else {
+				job.Succeeded++
+			}
+		})
+	}
+
+	created := 0
+	if job.Mode == business.ImportModeAll {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for i, row := range rows {
+				handle(tx, i, row)
+			}
+			if job.DryRun || len(failedRows) > 0 {
+				return errImportRollback
+			}
+			created = len(rows)
+			return nil
+		})
+	} else {
+		for start := 0; start < len(rows) && (err == nil || err == errImportRollback); start += customerImportBatch {
+			end := start + customerImportBatch
+			if end > len(rows) {
+				end = len(rows)
+			}
+			failedBefore := len(failedRows)
+			err = db.Transaction(func(tx *gorm.DB) error {
+				for i := start; i < end; i++ {
+					handle(tx, i, rows[i])
+				}
+				if job.DryRun {
+					return errImportRollback
+				}
+				return nil
+			})
+			if err == nil {
+				created += end - start - (len(failedRows) - failedBefore)
+			}
+		}
+	}
+	if err != nil && err != errImportRollback {
+		global.JY_LOG.Error("客户导入失败", zap.Uint("job_id", job.ID), zap.Error(err))
+		update(func(job *business.CustomerImportJob) { job.Created = created })
+		finish(business.ImportStatusFailed, "导入失败: "+err.Error())
+		return
+	}
+
+	if len(failedRows) > 0 {
+		if err = db.CreateInBatches(&failedRows, 500).Error; err != nil {
+			global.JY_LOG.Error("保存客户导入错误失败", zap.Uint("job_id", job.ID), zap.Error(err))
+		}
+	}
+	update(func(job *business.CustomerImportJob) { job.Created = created })
+
+	var message string
+	switch {
+	case job.DryRun:
+		message = fmt.Sprintf("校验完成，%d行通过，%d行有错误", len(rows)-len(failedRows), len(failedRows))
+	case job.Mode == business.ImportModeAll && len(failedRows) > 0:
+		message = fmt.Sprintf("%d行有错误，未导入任何客户", len(failedRows))
+	default:
+		message = fmt.Sprintf("导入完成，成功%d行，失败%d行", created, len(failedRows))
+	}
+	finish(business.ImportStatusSucceeded, message)
+}
+
+// newCustomerImporter 加载导入所需的字段、流程、用户和已有客户
+func newCustomerImporter(db *gorm.DB, job *business.CustomerImportJob) (*customerImporter, error) {
+	importer := &customerImporter{
+		job:     job,
+		columns: make(map[string]int),
+		fields:  make(map[string]business.CustomerField),
+		names:   make(map[string]int),
+		phones:  make(map[string]int),
+	}
+	for i, name := range job.Header {
+		if key := job.Mapping[strings.TrimSpace(name)]; key != "" {
+			importer.columns[key] = i
+		}
+	}
+
+	fields, err := GetCustomerFields(db, job.TenantID)
+	if err != nil {
+		return nil, errors.New("获取自定义字段失败")
+	}
+	for _, field := range fields {
+		importer.fields[field.FieldKey] = field
+	}
+	if importer.workflow, err = GetCustomerWorkflow(db, job.TenantID); err != nil {
+		return nil, errors.New("获取客户状态流程失败")
+	}
+	if importer.users, err = TenantUserKeys(db, job.TenantID); err != nil {
+		return nil, errors.New("查询用户失败")
+	}
+
+	if !job.IgnoreDuplicates {
+		var existing []business.Customer
+		err = db.Select("id", "customer_name", "customer_phone").Where("tenant_id = ?", job.TenantID).
+			FindInBatches(&existing, 2000, func(tx *gorm.DB, batch int) error {
+				for _, customer := range existing {
+					importer.names[customer.CustomerName] = 0
+					importer.phones[customer.CustomerPhone] = 0
+				}
+				return nil
+			}).Error
+		if err != nil {
+			return nil, errors.New("查询已有客户失败")
+		}
+	}
+	return importer, nil
+}
+
+// cell 读取一行中指定列的内容
+func (im *customerImporter) cell(row []string, key string) string {
+	i, ok := im.columns[key]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// parse 校验一行中不依赖数据库写入的内容，自定义字段、联系人、地址在创建时由对应的保存函数校验
+func (im *customerImporter) parse(line int, row []string) (customerImportRow, []string) {
+	errs := []string{}
+	customer := business.Customer{
+		TenantID:  im.job.TenantID,
+		CreatedBy: im.job.CreatedBy,
+		OwnerID:   im.job.CreatedBy,
+	}
+
+	customer.CustomerName = im.cell(row, "customerName")
+	if customer.CustomerName == "" {
+		errs = append(errs, "客户名称不能为空")
+	} else if first, ok := im.names[customer.CustomerName]; ok && !im.job.IgnoreDuplicates {
+		errs = append(errs, duplicateMessage("客户名称", first))
+	}
+
+	if phone := im.cell(row, "customerPhone"); phone == "" {
+		errs = append(errs, "客户电话不能为空")
+	} else if normalized, err := NormalizePhone(phone); err != nil {
+		errs = append(errs, "客户"+err.Error())
+	} else {
+		customer.CustomerPhone = normalized
+		if first, ok := im.phones[normalized]; ok && !im.job.IgnoreDuplicates {
+			errs = append(errs, duplicateMessage("客户电话", first))
+		}
+	}
+
+	customer.CustomerStatus = WorkflowInitialStatus(im.workflow).Code
+	if status := im.cell(row, "customerStatus"); status != "" {
+		found := false
+		for _, s := range im.workflow.Statuses {
+			if status == s.Code || status == s.Name {
+				customer.CustomerStatus = s.Code
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Sprintf("客户状态 %s 不存在", status))
+		}
+	}
+
+	if owner := im.cell(row, "owner"); owner == CustomerPoolOwnerName {
+		customer.OwnerID = 0
+	} else if owner != "" {
+		if id, ok := im.users[owner]; ok {
+			customer.OwnerID = id
+		} else {
+			errs = append(errs, fmt.Sprintf("负责人 %s 不存在", owner))
+		}
+	}
+	if customer.OwnerID != 0 {
+		now := time.Now()
+		customer.OwnedAt = &now
+	}
+
+	tags, err := NormalizeCustomerTags(SplitSheetList(im.cell(row, "tags")))
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	customer.Tags = tags
+
+	values := make(map[string]json.RawMessage)
+	for key, field := range im.fields {
+		if value := im.cell(row, CustomerSheetFieldPrefix+key); value != "" {
+			values[key] = CustomerFieldCellValue(field, value, im.users)
+		}
+	}
+
+	parsed := customerImportRow{customer: customer, fields: values}
+	contactName := im.cell(row, "contactName")
+	contactPhones := SplitSheetList(im.cell(row, "contactPhone"))
+	contactEmails := SplitSheetList(im.cell(row, "contactEmail"))
+	if contactName != "" || len(contactPhones) > 0 || len(contactEmails) > 0 {
+		if contactName == "" {
+			contactName = customer.CustomerName
+		}
+		for _, email := range contactEmails {
+			if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+				errs = append(errs, fmt.Sprintf("联系人邮箱 %s 格式不正确", email))
+			}
+		}
+		parsed.contacts = []business.CustomerContact{{Name: contactName, Phones: contactPhones, Emails: contactEmails, IsPrimary: true}}
+	}
+	if address := im.cell(row, "address"); address != "" {
+		parsed.addresses = []business.CustomerAddress{{Detail: address, IsPrimary: true}}
+	}
+	return parsed, errs
+}
+
+// create 创建一行客户，与新建客户接口使用相同的保存逻辑
+func (im *customerImporter) create(tx *gorm.DB, row customerImportRow) error {
+	customer := row.customer
+	if customer.OwnerID != 0 {
+		// 与分配客户相同，负责人负责的客户数不能超过公海规则的上限
+		if err := CheckCustomerOwner(tx, customer.TenantID, customer.OwnerID); err != nil {
+			return err
+		}
+	}
+	if err := tx.Create(&customer).Error; err != nil {
+		return err
+	}
+	if customer.OwnerID != 0 {
+		if err := recordImportedCustomerOwner(tx, customer, im.job.CreatedBy); err != nil {
+			return err
+		}
+	}
+	if err := StartCustomerStatusHistory(tx, customer, im.job.CreatedBy); err != nil {
+		return err
+	}
+	if err := SaveCustomerFieldValues(tx, customer, row.fields, true); err != nil {
+		return err
+	}
+	if err := SaveCustomerContacts(tx, customer, row.contacts); err != nil {
+		return err
+	}
+	return SaveCustomerAddresses(tx, customer, row.addresses)
+}
+
+// recordImportedCustomerOwner 在客户时间线中记录导入时指定的负责人
+// 新客户没有原负责人，变更前的值留空，不会被当作从公海领取计入每日领取数
+func recordImportedCustomerOwner(tx *gorm.DB, customer business.Customer, userId uint) error {
+	names, err := customerOwnerNames(tx, customer.OwnerID)
+	if err != nil {
+		return err
+	}
+	content := fmt.Sprintf("%s: 无 → %s，原因: 导入客户", ActivityTypeNames[business.ActivityAssign], names[customer.OwnerID])
+	return RecordCustomerChange(tx, customer, business.ActivityAssign, "", strconv.Itoa(int(customer.OwnerID)), content, userId)
+}
+
+// duplicateMessage 重复的提示，first 为 0 时表示与已有客户重复
+func duplicateMessage(name string, first int) string {
+	if first == 0 {
+		return name + "与已有客户重复"
+	}
+	return fmt.Sprintf("%s与第%d行重复", name, first)
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"testing"
+
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/system"
+)
+
+func TestCustomerImporterCreateOwner(t *testing.T) {
+	db := newTestDB(t, &system.SysUser{}, &business.Customer{}, &business.CustomerActivity{}, &business.CustomerPoolSetting{},
+		&business.CustomerStatusHistory{}, &business.CustomerField{}, &business.CustomerFieldValue{}, &business.CustomerContact{}, &business.CustomerAddress{})
+	user := system.SysUser{Username: "u7", NickName: "用户7", TenantId: 1}
+	user.ID = 7
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&business.CustomerPoolSetting{TenantID: 1, MaxOwned: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	importer := &customerImporter{job: &business.CustomerImportJob{TenantID: 1, CreatedBy: 7}}
+	rows := []customerImportRow{
+		{customer: business.Customer{CustomerName: "客户1", CustomerPhone: "13800138001", TenantID: 1, CreatedBy: 7, OwnerID: 7}},
+		{customer: business.Customer{CustomerName: "客户2", CustomerPhone: "13800138002", TenantID: 1, CreatedBy: 7, OwnerID: 7}},
+		{customer: business.Customer{CustomerName: "客户3", CustomerPhone: "13800138003", TenantID: 1, CreatedBy: 7}},
+	}
+	wantErrs := []string{"", "每人最多负责1个客户", ""}
+	for i, row := range rows {
+		err := importer.create(db, row)
+		if wantErrs[i] == "" && err != nil {
+			t.Fatalf("第%d行 create() error = %v", i+1, err)
+		}
+		if wantErrs[i] != "" && (err == nil || err.Error() != wantErrs[i]) {
+			t.Fatalf("第%d行 create() error = %v, want %q", i+1, err, wantErrs[i])
+		}
+	}
+
+	var customers []business.Customer
+	if err := db.Order("id").Find(&customers).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(customers) != 2 || customers[0].OwnerID != 7 || customers[1].OwnerID != 0 {
+		t.Fatalf("导入的客户 = %+v, want 客户1由用户7负责、客户3在公海中", customers)
+	}
+
+	var activities []business.CustomerActivity
+	if err := db.Where("type = ?", business.ActivityAssign).Find(&activities).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(activities) != 1 {
+		t.Fatalf("负责人变更记录 = %d, want 1", len(activities))
+	}
+	activity := activities[0]
+	if activity.CustomerID != customers[0].ID || activity.FromValue != "" || activity.ToValue != "7" || activity.CreatedBy != 7 {
+		t.Errorf("负责人变更记录 = %+v", activity)
+	}
+	if want := "负责人变更: 无 → 用户7，原因: 导入客户"; activity.Content != want {
+		t.Errorf("Content = %q, want %q", activity.Content, want)
+	}
+
+	// 导入时指定负责人不计入从公海领取的数量
+	if err := CheckDailyClaimLimit(db, business.CustomerPoolSetting{TenantID: 1, DailyClaimLimit: 1}, 7, 1); err != nil {
+		t.Errorf("CheckDailyClaimLimit() error = %v", err)
+	}
+}
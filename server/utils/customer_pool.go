@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/system"
+)
+
+// CustomerBatchResult 批量分配、转移、领取、退回公海的结果
+type CustomerBatchResult struct {
+	Success  int                  `json:"success"`  // 成功数
+	Messages []CustomerBatchError `json:"messages"` // 失败的客户
+}
+
+// CustomerBatchError 单个客户的失败原因
+type CustomerBatchError struct {
+	ID      uint   `json:"id"`
+	Message string `json:"message"`
+}
+
+// GetCustomerPoolSetting 获取租户的公海规则，未配置时返回不限制的规则
+func GetCustomerPoolSetting(db *gorm.DB, tenantId uint) (business.CustomerPoolSetting, error) {
+	setting := business.CustomerPoolSetting{TenantID: tenantId}
+	err := db.Where(&business.CustomerPoolSetting{TenantID: tenantId}).Limit(1).Find(&setting).Error
+	return setting, err
+}
+
+// CheckDailyClaimLimit 检查用户今天再从公海领取 count 个客户是否超过上限，“今天”按用户的时区计算
+func CheckDailyClaimLimit(db *gorm.DB, setting business.CustomerPoolSetting, userId uint, count int) error {
+	if setting.DailyClaimLimit <= 0 {
+		return nil
+	}
+	now := time.Now().In(UserLocation(userId))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// 领取记录为本人把公海客户转给自己的负责人变更记录
+	var claimed int64
+	err := db.Model(&business.CustomerActivity{}).
+		Where("tenant_id = ? AND type = ? AND from_value = ? AND to_value = ? AND created_by = ? AND occurred_at >= ?",
+			setting.TenantID, business.ActivityAssign, "0", strconv.Itoa(int(userId)), userId, today).
+		Count(&claimed).Error
+	if err != nil {
+		return errors.New("查询领取记录失败")
+	}
+	if int(claimed)+count > setting.DailyClaimLimit {
+		return fmt.Errorf("每天最多领取%d个客户，今天已领取%d个", setting.DailyClaimLimit, claimed)
+	}
+	return nil
+}
+
+// CheckCustomerOwner 检查用户能否负责租户中的客户：必须是租户的用户，且负责的客户数没有达到公海规则的上限。需要在事务中调用
+// 负责人的用户记录会被锁定到事务结束，同一负责人的并发分配、领取依次检查，不会同时通过上限检查
+func CheckCustomerOwner(tx *gorm.DB, tenantId uint, ownerId uint) error {
+	var owner system.SysUser
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+		Where(&system.SysUser{TenantId: tenantId}).Where("id = ?", ownerId).Limit(1).Find(&owner).Error
+	if err != nil {
+		return errors.New("查询负责人失败")
+	}
+	if owner.ID == 0 {
+		return errors.New("负责人不存在或不属于客户所在租户")
+	}
+
+	setting, err := GetCustomerPoolSetting(tx, tenantId)
+	if err != nil {
+		return errors.New("获取公海规则失败")
+	}
+	if setting.MaxOwned > 0 {
+		// 加锁读取最新提交的数据，不使用事务开始时的快照
+		var owned int64
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Model(&business.Customer{}).
+			Where(&business.Customer{TenantID: tenantId, OwnerID: ownerId}).Count(&owned).Error
+		if err != nil {
+			return errors.New("查询负责的客户数失败")
+		}
+		if int(owned) >= setting.MaxOwned {
+			return fmt.Errorf("每人最多负责%d个客户", setting.MaxOwned)
+		}
+	}
+	return nil
+}
+
+// ChangeCustomerOwner 修改客户负责人并写入客户时间线，ownerId 为 0 时退回公海
+// 新负责人需要通过 CheckCustomerOwner 的检查。需要在事务中调用
+func ChangeCustomerOwner(tx *gorm.DB, customer business.Customer, ownerId uint, reason string, userId uint) error {
+	if customer.OwnerID == ownerId {
+		if ownerId == 0 {
+			return errors.New("客户已在公海中")
+		}
+		return errors.New("客户已由该用户负责")
+	}
+	if ownerId != 0 {
+		if err := CheckCustomerOwner(tx, customer.TenantID, ownerId); err != nil {
+			return err
+		}
+	}
+
+	updates := map[string]interface{}{"owner_id": ownerId, "owned_at": nil}
+	if ownerId != 0 {
+		updates["owned_at"] = time.Now()
+	}
+	result := tx.Model(&business.Customer{}).Where("id = ? AND owner_id = ?", customer.ID, customer.OwnerID).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("客户负责人已被修改，请刷新后重试")
+	}
+
+	names, err := customerOwnerNames(tx, customer.OwnerID, ownerId)
+	if err != nil {
+		return err
+	}
+	content := fmt.Sprintf("%s: %s → %s", ActivityTypeNames[business.ActivityAssign], names[customer.OwnerID], names[ownerId])
+	if reason != "" {
+		content += "，原因: " + reason
+	}
+	return RecordCustomerChange(tx, customer, business.ActivityAssign,
+		strconv.Itoa(int(customer.OwnerID)), strconv.Itoa(int(ownerId)), content, userId)
+}
+
+// ChangeCustomerOwners 逐个修改客户负责人，每个客户单独提交，失败的客户不影响其他客户
+// customers 为可以操作的客户，ids 中不在 customers 里的客户以 notFound 作为失败原因
+func ChangeCustomerOwners(db *gorm.DB, customers []business.Customer, ids []uint, ownerId uint, reason string, userId uint, notFound string) CustomerBatchResult {
+	result := CustomerBatchResult{Messages: []CustomerBatchError{}}
+	found := make(map[uint]business.Customer, len(customers))
+	for _, customer := range customers {
+		found[customer.ID] = customer
+	}
+	for _, id := range ids {
+		customer, ok := found[id]
+		if !ok {
+			result.Messages = append(result.Messages, CustomerBatchError{ID: id, Message: notFound})
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			return ChangeCustomerOwner(tx, customer, ownerId, reason, userId)
+		})
+		if err != nil {
+			result.Messages = append(result.Messages, CustomerBatchError{ID: id, Message: err.Error()})
+			continue
+		}
+		result.Success++
+	}
+	return result
+}
+
+// ReturnIdleCustomers 将超过公海规则天数未跟进的客户退回公海
+// 以最近跟进时间和分配给当前负责人的时间中较晚的一个计算，处于终止状态的客户不退回
+func ReturnIdleCustomers(db *gorm.DB) (CustomerBatchResult, error) {
+	result := CustomerBatchResult{Messages: []CustomerBatchError{}}
+	var settings []business.CustomerPoolSetting
+	if err := db.Where("return_days > 0").Find(&settings).Error; err != nil {
+		return result, err
+	}
+	for _, setting := range settings {
+		workflow, err := GetCustomerWorkflow(db, setting.TenantID)
+		if err != nil {
+			return result, err
+		}
+		finals := []string{}
+		for _, status := range workflow.Statuses {
+			if status.Final {
+				finals = append(finals, status.Code)
+			}
+		}
+
+		cutoff := time.Now().AddDate(0, 0, -setting.ReturnDays)
+		query := db.Where("tenant_id = ? AND owner_id <> 0", setting.TenantID).
+			Where("last_followed_at IS NULL OR last_followed_at < ?", cutoff).
+			Where("owned_at IS NULL OR owned_at < ?", cutoff)
+		if len(finals) > 0 {
+			query = query.Where("customer_status NOT IN ?", finals)
+		}
+		var customers []business.Customer
+		if err = query.Find(&customers).Error; err != nil {
+			return result, err
+		}
+
+		ids := make([]uint, 0, len(customers))
+		for _, customer := range customers {
+			ids = append(ids, customer.ID)
+		}
+		reason := fmt.Sprintf("超过%d天未跟进，自动退回公海", setting.ReturnDays)
+		tenantResult := ChangeCustomerOwners(db, customers, ids, 0, reason, 0, "")
+		result.Success += tenantResult.Success
+		result.Messages = append(result.Messages, tenantResult.Messages...)
+	}
+	return result, nil
+}
+
+// customerOwnerNames 负责人变更说明中使用的名称，0 为公海，已删除的用户也会显示
+func customerOwnerNames(tx *gorm.DB, ids ...uint) (map[uint]string, error) {
+	names := map[uint]string{0: "公海"}
+	var users []system.SysUser
+	if err := tx.Unscoped().Select("id", "nick_name").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		names[user.ID] = user.NickName
+	}
+	return names, nil
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/system"
+)
+
+func TestChangeCustomerOwner(t *testing.T) {
+	tests := []struct {
+		name      string
+		owner     uint // 数据库中客户当前的负责人
+		loaded    uint // 调用方读取客户时的负责人
+		newOwner  uint
+		maxOwned  int
+		wantErr   string
+		wantOwner uint
+	}{
+		{name: "分配给其他用户", owner: 7, loaded: 7, newOwner: 8, wantOwner: 8},
+		{name: "从公海领取", owner: 0, loaded: 0, newOwner: 7, wantOwner: 7},
+		{name: "退回公海", owner: 7, loaded: 7, newOwner: 0, wantOwner: 0},
+		{name: "读取后负责人已被修改", owner: 9, loaded: 7, newOwner: 8, wantErr: "客户负责人已被修改，请刷新后重试", wantOwner: 9},
+		{name: "读取后已被他人领取", owner: 9, loaded: 0, newOwner: 7, wantErr: "客户负责人已被修改，请刷新后重试", wantOwner: 9},
+		{name: "负责人相同", owner: 7, loaded: 7, newOwner: 7, wantErr: "客户已由该用户负责", wantOwner: 7},
+		{name: "已在公海中", owner: 0, loaded: 0, newOwner: 0, wantErr: "客户已在公海中", wantOwner: 0},
+		{name: "负责人属于其他租户", owner: 7, loaded: 7, newOwner: 10, wantErr: "负责人不存在或不属于客户所在租户", wantOwner: 7},
+		{name: "超过负责的客户数上限", owner: 0, loaded: 0, newOwner: 8, maxOwned: 1, wantErr: "每人最多负责1个客户", wantOwner: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &system.SysUser{}, &business.Customer{}, &business.CustomerActivity{}, &business.CustomerPoolSetting{})
+			for id, tenantId := range map[uint]uint{7: 1, 8: 1, 9: 1, 10: 2} {
+				user := system.SysUser{Username: fmt.Sprintf("u%d", id), NickName: fmt.Sprintf("用户%d", id), TenantId: tenantId}
+				user.ID = id
+				if err := db.Create(&user).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.maxOwned > 0 {
+				if err := db.Create(&business.CustomerPoolSetting{TenantID: 1, MaxOwned: tt.maxOwned}).Error; err != nil {
+					t.Fatal(err)
+				}
+				if err := db.Create(&business.Customer{CustomerName: "已负责", TenantID: 1, OwnerID: tt.newOwner}).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+			customer := business.Customer{CustomerName: "客户", TenantID: 1, OwnerID: tt.owner}
+			if err := db.Create(&customer).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			loaded := customer
+			loaded.OwnerID = tt.loaded
+			err := db.Transaction(func(tx *gorm.DB) error {
+				return ChangeCustomerOwner(tx, loaded, tt.newOwner, "测试", 1)
+			})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ChangeCustomerOwner() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("ChangeCustomerOwner() error = %v, want %q", err, tt.wantErr)
+			}
+
+			var saved business.Customer
+			if err = db.First(&saved, customer.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if saved.OwnerID != tt.wantOwner {
+				t.Errorf("负责人 = %d, want %d", saved.OwnerID, tt.wantOwner)
+			}
+			if tt.wantErr == "" && (saved.OwnedAt != nil) != (tt.wantOwner != 0) {
+				t.Errorf("分配时间 = %v, 负责人 %d", saved.OwnedAt, tt.wantOwner)
+			}
+
+			var activities int64
+			db.Model(&business.CustomerActivity{}).Where("customer_id = ? AND type = ?", customer.ID, business.ActivityAssign).Count(&activities)
+			want := int64(1)
+			if tt.wantErr != "" {
+				want = 0
+			}
+			if activities != want {
+				t.Errorf("负责人变更记录 = %d, want %d", activities, want)
+			}
+		})
+	}
+}
+
+func TestChangeCustomerOwnerConcurrentMaxOwned(t *testing.T) {
+	db := newTestDB(t, &system.SysUser{}, &business.Customer{}, &business.CustomerActivity{}, &business.CustomerPoolSetting{})
+	user := system.SysUser{Username: "u7", NickName: "用户7", TenantId: 1}
+	user.ID = 7
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&business.CustomerPoolSetting{TenantID: 1, MaxOwned: 2}).Error; err != nil {
+		t.Fatal(err)
+	}
+	customers := make([]business.Customer, 8)
+	for i := range customers {
+		customers[i] = business.Customer{CustomerName: fmt.Sprintf("客户%d", i), TenantID: 1}
+		if err := db.Create(&customers[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 同时领取多个公海客户，无论并发请求如何交错，负责的客户数都不能超过上限
+	var wg sync.WaitGroup
+	for _, customer := range customers {
+		wg.Add(1)
+		go func(customer business.Customer) {
+			defer wg.Done()
+			_ = db.Transaction(func(tx *gorm.DB) error {
+				return ChangeCustomerOwner(tx, customer, 7, "", 7)
+			})
+		}(customer)
+	}
+	wg.Wait()
+
+	var owned int64
+	if err := db.Model(&business.Customer{}).Where("owner_id = ?", 7).Count(&owned).Error; err != nil {
+		t.Fatal(err)
+	}
+	if owned > 2 {
+		t.Errorf("负责的客户数 = %d, want <= 2", owned)
+	}
+}
@@ -0,0 +1,545 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/system"
+	"jiangyi.com/utils/search"
+)
+
+const (
+	CustomerQueryMaxFilters  = 30   // 每个查询最多的筛选条件数
+	CustomerQueryMaxValues   = 100  // in 条件最多的值数
+	CustomerQueryMaxSorts    = 3    // 每个查询最多的排序条件数
+	CustomerQueryCurrentUser = "me" // 用户类条件的值，表示当前用户，保存的视图共享后对每个用户各自生效
+	CustomerMaxViews         = 50   // 每个用户最多保存的视图数
+)
+
+// 查询字段的类型，决定可用的运算符和值的格式
+const (
+	customerQueryText   = "text"   // 文本
+	customerQueryNumber = "number" // 数字
+	customerQueryDate   = "date"   // 日期 YYYY-MM-DD
+	customerQueryTime   = "time"   // 时间
+	customerQueryOption = "option" // 单选、状态
+	customerQueryMulti  = "multi"  // 多选、标签
+	customerQueryUser   = "user"   // 用户
+)
+
+// customerQueryOps 各类型字段可用的运算符
+var customerQueryOps = map[string][]string{
+	customerQueryText:   {business.CustomerOpEq, business.CustomerOpIn, business.CustomerOpContains, business.CustomerOpIsNull},
+	customerQueryNumber: {business.CustomerOpEq, business.CustomerOpIn, business.CustomerOpRange, business.CustomerOpIsNull},
+	customerQueryDate:   {business.CustomerOpEq, business.CustomerOpIn, business.CustomerOpRange, business.CustomerOpIsNull},
+	customerQueryTime:   {business.CustomerOpRange, business.CustomerOpIsNull},
+	customerQueryOption: {business.CustomerOpEq, business.CustomerOpIn, business.CustomerOpIsNull},
+	customerQueryMulti:  {business.CustomerOpEq, business.CustomerOpIn, business.CustomerOpIsNull},
+	customerQueryUser:   {business.CustomerOpEq, business.CustomerOpIn, business.CustomerOpIsNull},
+}
+
+// CustomerQueryField 可以查询的字段
+type CustomerQueryField struct {
+	Field    string   `json:"field"`    // 字段，自定义字段为 cf.字段标识
+	Name     string   `json:"name"`     // 字段名称
+	Type     string   `json:"type"`     // 类型 text, number, date, time, option, multi, user
+	Ops      []string `json:"ops"`      // 可用的运算符
+	Sortable bool     `json:"sortable"` // 是否可以排序
+	column   string
+	// 按该字段筛选和排序需要的操作权限，没有权限的用户逐位筛选、排序可以推断出脱敏的值
+	permission string
+}
+
+// customerQueryFields 客户的固定字段，自定义字段在其后
+var customerQueryFields = []CustomerQueryField{
+	{Field: "id", Name: "ID", Type: customerQueryNumber, Sortable: true, column: "id"},
+	{Field: "customerName", Name: "客户名称", Type: customerQueryText, Sortable: true, column: "customer_name"},
+	{Field: "customerPhone", Name: "客户电话", Type: customerQueryText, Sortable: true, column: "customer_phone", permission: system.PermissionCustomerViewPhone},
+	{Field: "customerStatus", Name: "客户状态", Type: customerQueryOption, Sortable: true, column: "customer_status"},
+	{Field: "ownerId", Name: "负责人", Type: customerQueryUser, Sortable: true, column: "owner_id"},
+	{Field: "createdBy", Name: "创建人", Type: customerQueryUser, column: "created_by"},
+	{Field: "tags", Name: "标签", Type: customerQueryMulti, column: "tags"},
+	{Field: "createdAt", Name: "创建时间", Type: customerQueryTime, Sortable: true, column: "created_at"},
+	{Field: "updatedAt", Name: "更新时间", Type: customerQueryTime, Sortable: true, column: "updated_at"},
+	{Field: "ownedAt", Name: "分配时间", Type: customerQueryTime, Sortable: true, column: "owned_at"},
+	{Field: "lastFollowedAt", Name: "最近跟进时间", Type: customerQueryTime, Sortable: true, column: "last_followed_at"},
+}
+
+// customerFieldQueryTypes 自定义字段类型对应的查询类型
+var customerFieldQueryTypes = map[string]string{
+	business.CustomerFieldText:        customerQueryText,
+	business.CustomerFieldNumber:      customerQueryNumber,
+	business.CustomerFieldDate:        customerQueryDate,
+	business.CustomerFieldSelect:      customerQueryOption,
+	business.CustomerFieldMultiSelect: customerQueryMulti,
+	business.CustomerFieldUser:        customerQueryUser,
+}
+
+// relativeDayPattern 相对日期，如 -7d 表示 7 天前
+var relativeDayPattern = regexp.MustCompile(`^[+-]\d{1,4}d$`)
+
+// CustomerQueryFields 当前用户在租户中可以查询的字段，包括自定义字段，不包括没有权限查询的字段
+func CustomerQueryFields(c *gin.Context, db *gorm.DB, tenantId uint) ([]CustomerQueryField, error) {
+	fields, err := GetCustomerFields(db, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]CustomerQueryField, 0, len(customerQueryFields)+len(fields))
+	for _, field := range customerQueryFields {
+		if field.permission != "" && !HasPermission(c, field.permission) {
+			continue
+		}
+		field.Ops = customerQueryOps[field.Type]
+		result = append(result, field)
+	}
+	for _, field := range fields {
+		queryType := customerFieldQueryTypes[field.Type]
+		result = append(result, CustomerQueryField{
+			Field: CustomerSheetFieldPrefix + field.FieldKey,
+			Name:  field.Name,
+			Type:  queryType,
+			Ops:   customerQueryOps[queryType],
+		})
+	}
+	return result, nil
+}
+
+// MergeCustomerQuery 在保存的视图上叠加临时条件：筛选条件同时生效，关键字和排序不为空时替换视图中的
+func MergeCustomerQuery(view, extra business.CustomerQuery) business.CustomerQuery {
+	merged := business.CustomerQuery{
+		Keyword: view.Keyword,
+		Filters: append(append([]business.CustomerFilter{}, view.Filters...), extra.Filters...),
+		Sorts:   view.Sorts,
+	}
+	if extra.Keyword != "" {
+		merged.Keyword = extra.Keyword
+	}
+	if len(extra.Sorts) > 0 {
+		merged.Sorts = extra.Sorts
+	}
+	return merged
+}
+
+// CustomerQueryScope 校验查询条件并生成查询，sorted 为 false 时忽略排序（如按主键分批导出）
+// 自定义字段按当前请求的租户查找，超级管理员跨租户查询时匹配所有租户中同标识的字段
+func CustomerQueryScope(c *gin.Context, query business.CustomerQuery, sorted bool) (func(db *gorm.DB) *gorm.DB, error) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		return nil, errors.New("获取用户信息失败")
+	}
+	if len(query.Filters) > CustomerQueryMaxFilters {
+		return nil, fmt.Errorf("筛选条件不能超过%d个", CustomerQueryMaxFilters)
+	}
+	if len(query.Sorts) > CustomerQueryMaxSorts {
+		return nil, fmt.Errorf("排序条件不能超过%d个", CustomerQueryMaxSorts)
+	}
+
+	builder := customerQueryBuilder{c: c, userId: claims.(*CustomClaims).ID}
+	conditions := make([]*gorm.DB, 0, len(query.Filters)+1)
+	if keyword := strings.TrimSpace(query.Keyword); keyword != "" {
+		// 关键字匹配名称、电话、标签、联系人和地址，见 search.CustomerIDs
+		conditions = append(conditions, global.JY_DB.Where("id IN (?)", search.CustomerIDs(global.JY_DB, keyword)))
+	}
+	for _, filter := range query.Filters {
+		condition, err := builder.condition(filter)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	orders := make([]clause.OrderByColumn, 0, len(query.Sorts)+1)
+	for _, sort := range query.Sorts {
+		field, ok := findCustomerQueryField(sort.Field)
+		if !ok || !field.Sortable {
+			return nil, fmt.Errorf("不能按 %s 排序", sort.Field)
+		}
+		if field.permission != "" && !HasPermission(c, field.permission) {
+			return nil, fmt.Errorf("没有权限按%s排序", field.Name)
+		}
+		orders = append(orders, clause.OrderByColumn{Column: clause.Column{Name: field.column}, Desc: sort.Desc})
+	}
+	// 排序值相同时按 ID 排序，保证分页稳定
+	if len(orders) > 0 && orders[len(orders)-1].Column.Name != "id" {
+		orders = append(orders, clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: true})
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		for _, condition := range conditions {
+			db = db.Where(condition)
+		}
+		if sorted {
+			for _, order := range orders {
+				db = db.Order(order)
+			}
+		}
+		return db
+	}, nil
+}
+
+func findCustomerQueryField(name string) (CustomerQueryField, bool) {
+	for _, field := range customerQueryFields {
+		if field.Field == name {
+			return field, true
+		}
+	}
+	return CustomerQueryField{}, false
+}
+
+// customerQueryBuilder 将筛选条件转换为 SQL 条件
+type customerQueryBuilder struct {
+	c      *gin.Context
+	userId uint
+}
+
+func (b customerQueryBuilder) condition(filter business.CustomerFilter) (*gorm.DB, error) {
+	if key, ok := strings.CutPrefix(filter.Field, CustomerSheetFieldPrefix); ok {
+		return b.customFieldCondition(key, filter)
+	}
+	field, ok := findCustomerQueryField(filter.Field)
+	if !ok {
+		return nil, fmt.Errorf("不能按 %s 筛选", filter.Field)
+	}
+	if !ContainsString(customerQueryOps[field.Type], filter.Op) {
+		return nil, fmt.Errorf("%s不支持 %s 条件", field.Name, filter.Op)
+	}
+	if field.permission != "" && !HasPermission(b.c, field.permission) {
+		return nil, fmt.Errorf("没有权限按%s筛选", field.Name)
+	}
+
+	column := clause.Column{Name: field.column}
+	if filter.Op == business.CustomerOpIsNull {
+		isNull, err := b.isNullValue(field.Name, filter.Value)
+		if err != nil {
+			return nil, err
+		}
+		// 用户为 0 表示未指定（负责人为 0 即在公海中），文本、标签为空串或空数组也视为未填写
+		var empty clause.Expression
+		switch field.Type {
+		case customerQueryUser:
+			empty = clause.Expr{SQL: "? = 0", Vars: []interface{}{column}}
+		case customerQueryTime:
+			empty = clause.Expr{SQL: "? IS NULL", Vars: []interface{}{column}}
+		case customerQueryMulti:
+			empty = clause.Expr{SQL: "(? IS NULL OR ? IN ('', 'null', '[]'))", Vars: []interface{}{column, column}}
+		default:
+			empty = clause.Expr{SQL: "(? IS NULL OR ? = '')", Vars: []interface{}{column, column}}
+		}
+		if isNull {
+			return global.JY_DB.Where(empty), nil
+		}
+		return global.JY_DB.Where(clause.Not(empty)), nil
+	}
+
+	if field.Type == customerQueryMulti {
+		// 标签以 JSON 数组保存，按带引号的值匹配
+		values, err := b.values(field.Name, customerQueryText, filter)
+		if err != nil {
+			return nil, err
+		}
+		group := global.JY_DB
+		for i, value := range values {
+			pattern := `%"` + escapeLike(value.(string)) + `"%`
+			if i == 0 {
+				group = group.Where("? LIKE ? ESCAPE '!'", column, pattern)
+			} else {
+				group = group.Or("? LIKE ? ESCAPE '!'", column, pattern)
+			}
+		}
+		return group, nil
+	}
+
+	switch filter.Op {
+	case business.CustomerOpContains:
+		var keyword string
+		if err := json.Unmarshal(filter.Value, &keyword); err != nil || keyword == "" {
+			return nil, fmt.Errorf("%s的 contains 条件值应为文本", field.Name)
+		}
+		if field.column == "customer_phone" {
+			// 电话以 E.164 格式保存，去掉空格、横线等分隔符再匹配
+			keyword = phoneSeparators.Replace(keyword)
+		}
+		return global.JY_DB.Where("? LIKE ?", column, "%"+keyword+"%"), nil
+	case business.CustomerOpRange:
+		from, to, err := b.timeRange(field.Name, filter.Value)
+		if err != nil {
+			return nil, err
+		}
+		condition := global.JY_DB
+		if from != nil {
+			condition = condition.Where("? >= ?", column, *from)
+		}
+		if to != nil {
+			condition = condition.Where("? < ?", column, *to)
+		}
+		return condition, nil
+	default:
+		values, err := b.values(field.Name, field.Type, filter)
+		if err != nil {
+			return nil, err
+		}
+		if field.column == "customer_phone" {
+			// 与保存时一样转换为 E.164 格式，如 138 0013 8000 匹配 +8613800138000
+			for i, value := range values {
+				phone, err := NormalizePhone(value.(string))
+				if err != nil {
+					return nil, fmt.Errorf("%s的条件值 %s 格式不正确", field.Name, value)
+				}
+				values[i] = phone
+			}
+		}
+		return global.JY_DB.Where("? IN ?", column, values), nil
+	}
+}
+
+// customFieldCondition 自定义字段的条件，匹配有符合条件的字段值的客户
+func (b customerQueryBuilder) customFieldCondition(key string, filter business.CustomerFilter) (*gorm.DB, error) {
+	var fields []business.CustomerField
+	if err := global.JY_DB.WithContext(b.c).Where(&business.CustomerField{FieldKey: key}).Find(&fields).Error; err != nil {
+		return nil, errors.New("获取自定义字段失败")
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("自定义字段 %s 不存在", key)
+	}
+
+	if filter.Op == business.CustomerOpIsNull {
+		isNull, err := b.isNullValue(fields[0].Name, filter.Value)
+		if err != nil {
+			return nil, err
+		}
+		fieldIds := make([]uint, 0, len(fields))
+		for _, field := range fields {
+			fieldIds = append(fieldIds, field.ID)
+		}
+		filled := global.JY_DB.Model(&business.CustomerFieldValue{}).Select("customer_id").Where("field_id IN ?", fieldIds)
+		if isNull {
+			return global.JY_DB.Where("id NOT IN (?)", filled), nil
+		}
+		return global.JY_DB.Where("id IN (?)", filled), nil
+	}
+
+	var group *gorm.DB
+	for _, field := range fields {
+		queryType := customerFieldQueryTypes[field.Type]
+		if !ContainsString(customerQueryOps[queryType], filter.Op) {
+			return nil, fmt.Errorf("%s不支持 %s 条件", field.Name, filter.Op)
+		}
+		condition := global.JY_DB.Where("field_id = ?", field.ID)
+		column := "value"
+		if queryType == customerQueryNumber {
+			column = "number_value"
+		}
+		switch filter.Op {
+		case business.CustomerOpContains:
+			var keyword string
+			if err := json.Unmarshal(filter.Value, &keyword); err != nil || keyword == "" {
+				return nil, fmt.Errorf("%s的 contains 条件值应为文本", field.Name)
+			}
+			condition = condition.Where("value LIKE ?", "%"+keyword+"%")
+		case business.CustomerOpRange:
+			from, to, err := b.valueRange(field.Name, queryType, filter.Value)
+			if err != nil {
+				return nil, err
+			}
+			if from != nil {
+				condition = condition.Where(column+" >= ?", from)
+			}
+			if to != nil {
+				condition = condition.Where(column+" <= ?", to)
+			}
+		default:
+			values, err := b.values(field.Name, queryType, filter)
+			if err != nil {
+				return nil, err
+			}
+			if queryType == customerQueryUser {
+				// 用户字段的值以字符串保存
+				for i, value := range values {
+					values[i] = strconv.Itoa(int(value.(uint)))
+				}
+			}
+			condition = condition.Where(column+" IN ?", values)
+		}
+		if group == nil {
+			group = global.JY_DB.Where(condition)
+		} else {
+			group = group.Or(condition)
+		}
+	}
+	return global.JY_DB.Where("id IN (?)", global.JY_DB.Model(&business.CustomerFieldValue{}).Select("customer_id").Where(group)), nil
+}
+
+func (b customerQueryBuilder) isNullValue(name string, raw json.RawMessage) (bool, error) {
+	if len(raw) == 0 {
+		return true, nil
+	}
+	var isNull bool
+	if err := json.Unmarshal(raw, &isNull); err != nil {
+		return false, fmt.Errorf("%s的 isNull 条件值应为 true 或 false", name)
+	}
+	return isNull, nil
+}
+
+// values eq、in 条件的值，eq 视为只有一个值的 in
+func (b customerQueryBuilder) values(name, queryType string, filter business.CustomerFilter) ([]interface{}, error) {
+	var raws []json.RawMessage
+	if filter.Op == business.CustomerOpIn {
+		if err := json.Unmarshal(filter.Value, &raws); err != nil || len(raws) == 0 {
+			return nil, fmt.Errorf("%s的 in 条件值应为非空数组", name)
+		}
+		if len(raws) > CustomerQueryMaxValues {
+			return nil, fmt.Errorf("%s的 in 条件值不能超过%d个", name, CustomerQueryMaxValues)
+		}
+	} else {
+		raws = []json.RawMessage{filter.Value}
+	}
+	values := make([]interface{}, 0, len(raws))
+	for _, raw := range raws {
+		value, err := b.value(name, queryType, raw)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// value 单个值，用户类字段支持 me 表示当前用户，日期支持相对日期
+func (b customerQueryBuilder) value(name, queryType string, raw json.RawMessage) (interface{}, error) {
+	switch queryType {
+	case customerQueryNumber:
+		var num float64
+		if err := json.Unmarshal(raw, &num); err != nil {
+			return nil, fmt.Errorf("%s的条件值应为数字", name)
+		}
+		return num, nil
+	case customerQueryUser:
+		var id uint
+		if err := json.Unmarshal(raw, &id); err == nil {
+			return id, nil
+		}
+		var me string
+		if err := json.Unmarshal(raw, &me); err == nil && me == CustomerQueryCurrentUser {
+			return b.userId, nil
+		}
+		return nil, fmt.Errorf("%s的条件值应为用户ID或 %s", name, CustomerQueryCurrentUser)
+	case customerQueryDate:
+		var date string
+		if err := json.Unmarshal(raw, &date); err != nil {
+			return nil, fmt.Errorf("%s的条件值应为日期", name)
+		}
+		day, err := b.parseTime(date)
+		if err != nil || !day.dateOnly {
+			return nil, fmt.Errorf("%s的条件值 %s 日期格式错误，应为 YYYY-MM-DD、today 或 -7d", name, date)
+		}
+		return day.Format(customerFieldDateLayout), nil
+	default:
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return nil, fmt.Errorf("%s的条件值应为文本", name)
+		}
+		return text, nil
+	}
+}
+
+// valueRange 自定义数字、日期字段的范围，包含两端
+func (b customerQueryBuilder) valueRange(name, queryType string, raw json.RawMessage) (interface{}, interface{}, error) {
+	var bounds struct {
+		From json.RawMessage `json:"from"`
+		To   json.RawMessage `json:"to"`
+	}
+	if err := json.Unmarshal(raw, &bounds); err != nil {
+		return nil, nil, fmt.Errorf("%s的 range 条件值应为 {\"from\": 起, \"to\": 止}", name)
+	}
+	var from, to interface{}
+	var err error
+	if !isJSONNull(bounds.From) {
+		if from, err = b.value(name, queryType, bounds.From); err != nil {
+			return nil, nil, err
+		}
+	}
+	if !isJSONNull(bounds.To) {
+		if to, err = b.value(name, queryType, bounds.To); err != nil {
+			return nil, nil, err
+		}
+	}
+	if from == nil && to == nil {
+		return nil, nil, fmt.Errorf("%s的 range 条件至少需要指定一端", name)
+	}
+	return from, to, nil
+}
+
+// timeRange 时间字段的范围 [from, to)，to 只有日期时包含当天
+func (b customerQueryBuilder) timeRange(name string, raw json.RawMessage) (*time.Time, *time.Time, error) {
+	var bounds struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+	if err := json.Unmarshal(raw, &bounds); err != nil || (bounds.From == "" && bounds.To == "") {
+		return nil, nil, fmt.Errorf("%s的 range 条件值应为 {\"from\": 起, \"to\": 止}，至少指定一端", name)
+	}
+	var from, to *time.Time
+	if bounds.From != "" {
+		t, err := b.parseTime(bounds.From)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s的条件值 %s 时间格式错误", name, bounds.From)
+		}
+		from = &t.Time
+	}
+	if bounds.To != "" {
+		t, err := b.parseTime(bounds.To)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s的条件值 %s 时间格式错误", name, bounds.To)
+		}
+		end := t.Time
+		if t.dateOnly {
+			end = end.AddDate(0, 0, 1)
+		} else {
+			end = end.Add(time.Second)
+		}
+		to = &end
+	}
+	return from, to, nil
+}
+
+type queryTime struct {
+	time.Time
+	dateOnly bool
+}
+
+// parseTime 解析时间条件值：today、-7d 等相对日期，YYYY-MM-DD，YYYY-MM-DD HH:MM:SS 或 RFC3339，没有时区的按用户的时区解析
+func (b customerQueryBuilder) parseTime(value string) (queryTime, error) {
+	location := UserLocation(b.userId)
+	if value == "today" || relativeDayPattern.MatchString(value) {
+		days := 0
+		if value != "today" {
+			days, _ = strconv.Atoi(strings.TrimSuffix(value, "d"))
+		}
+		now := time.Now().In(location)
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+		return queryTime{Time: today.AddDate(0, 0, days), dateOnly: true}, nil
+	}
+	if t, err := time.ParseInLocation(customerFieldDateLayout, value, location); err == nil {
+		return queryTime{Time: t, dateOnly: true}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", value, location); err == nil {
+		return queryTime{Time: t}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return queryTime{Time: t}, err
+}
+
+// escapeLike 转义 LIKE 中的通配符，配合 ESCAPE '!' 使用
+func escapeLike(value string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(value)
+}
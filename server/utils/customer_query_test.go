@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/system"
+)
+
+func TestCustomerQueryScope(t *testing.T) {
+	db := newTestDB(t, &business.Customer{}, &business.CustomerField{}, &business.CustomerFieldValue{}, &system.SysAuthority{}, &system.SysAuthorityGrant{})
+	if err := db.Create(&system.SysAuthority{AuthorityId: "200", AuthorityName: "经理", Enable: true, Permissions: []string{system.PermissionCustomerViewPhone}}).Error; err != nil {
+		t.Fatal(err)
+	}
+	day := func(value string) time.Time {
+		d, _ := time.ParseInLocation("2006-01-02", value, time.Local)
+		return d
+	}
+	customers := []business.Customer{
+		{CustomerName: "上海贸易", CustomerPhone: "+8613800138000", CustomerStatus: "new", OwnerID: 7, Tags: []string{"vip"}},
+		{CustomerName: "北京科技", CustomerPhone: "+861012345678", CustomerStatus: "won", OwnerID: 0, Tags: []string{"vip", "渠道"}},
+		{CustomerName: "上海科技", CustomerPhone: "+8613900139000", CustomerStatus: "new", OwnerID: 8},
+	}
+	for i, created := range []string{"2024-01-10", "2024-02-10", "2024-03-10"} {
+		customers[i].CreatedAt = day(created)
+	}
+	if err := db.Create(&customers).Error; err != nil {
+		t.Fatal(err)
+	}
+	level := business.CustomerField{FieldKey: "level", Name: "等级", Type: business.CustomerFieldSelect, Options: []string{"A", "B"}}
+	if err := db.Create(&level).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&[]business.CustomerFieldValue{
+		{CustomerID: customers[0].ID, FieldID: level.ID, Value: "A"},
+		{CustomerID: customers[1].ID, FieldID: level.ID, Value: "B"},
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	filter := func(field, op, value string) business.CustomerFilter {
+		return business.CustomerFilter{Field: field, Op: op, Value: json.RawMessage(value)}
+	}
+	tests := []struct {
+		name      string
+		query     business.CustomerQuery
+		viewPhone bool // 当前用户有查看完整电话的权限
+		want      []uint
+		wantErr   bool
+	}{
+		{name: "文本包含", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("customerName", "contains", `"上海"`)}}, want: []uint{1, 3}},
+		{name: "状态等于", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("customerStatus", "eq", `"new"`)}}, want: []uint{1, 3}},
+		{name: "状态属于", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("customerStatus", "in", `["won","lost"]`)}}, want: []uint{2}},
+		{name: "负责人为当前用户", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("ownerId", "eq", `"me"`)}}, want: []uint{1}},
+		{name: "负责人为空即公海", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("ownerId", "isNull", `true`)}}, want: []uint{2}},
+		{name: "负责人不为空", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("ownerId", "isNull", `false`)}}, want: []uint{1, 3}},
+		{name: "标签", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("tags", "eq", `"vip"`)}}, want: []uint{1, 2}},
+		{name: "没有标签", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("tags", "isNull", `true`)}}, want: []uint{3}},
+		{name: "创建时间范围包含结束日期", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("createdAt", "range", `{"from":"2024-02-01","to":"2024-02-10"}`)}}, want: []uint{2}},
+		{name: "只指定开始时间", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("createdAt", "range", `{"from":"2024-02-11"}`)}}, want: []uint{3}},
+		{name: "自定义字段", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("cf.level", "eq", `"A"`)}}, want: []uint{1}},
+		{name: "自定义字段未填写", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("cf.level", "isNull", `true`)}}, want: []uint{3}},
+		{
+			name: "多个条件同时满足",
+			query: business.CustomerQuery{Filters: []business.CustomerFilter{
+				filter("customerName", "contains", `"上海"`),
+				filter("customerStatus", "eq", `"new"`),
+				filter("ownerId", "in", `["me", 9]`),
+			}},
+			want: []uint{1},
+		},
+		{name: "电话按保存格式匹配", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("customerPhone", "eq", `"138 0013 8000"`)}}, viewPhone: true, want: []uint{1}},
+		{name: "电话属于", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("customerPhone", "in", `["010-12345678", "+86 139-0013-9000"]`)}}, viewPhone: true, want: []uint{2, 3}},
+		{name: "电话包含", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("customerPhone", "contains", `"0013 8"`)}}, viewPhone: true, want: []uint{1}},
+		{name: "电话格式错误", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("customerPhone", "eq", `"12345"`)}}, viewPhone: true, wantErr: true},
+		{name: "没有权限按电话筛选", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("customerPhone", "contains", `"138"`)}}, wantErr: true},
+		{name: "没有权限按电话排序", query: business.CustomerQuery{Sorts: []business.CustomerSort{{Field: "customerPhone"}}}, wantErr: true},
+		{name: "按电话排序", query: business.CustomerQuery{Sorts: []business.CustomerSort{{Field: "customerPhone"}}}, viewPhone: true, want: []uint{2, 1, 3}},
+		{name: "排序", query: business.CustomerQuery{Sorts: []business.CustomerSort{{Field: "createdAt", Desc: true}}}, want: []uint{3, 2, 1}},
+		{name: "不存在的字段", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("foo", "eq", `"a"`)}}, wantErr: true},
+		{name: "不存在的自定义字段", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("cf.foo", "eq", `"a"`)}}, wantErr: true},
+		{name: "字段不支持的运算符", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("customerName", "range", `{"from":"a"}`)}}, wantErr: true},
+		{name: "in 条件为空", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("customerStatus", "in", `[]`)}}, wantErr: true},
+		{name: "用户条件值错误", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("ownerId", "eq", `"bob"`)}}, wantErr: true},
+		{name: "时间格式错误", query: business.CustomerQuery{Filters: []business.CustomerFilter{filter("createdAt", "range", `{"from":"2024/02/01"}`)}}, wantErr: true},
+		{name: "不能排序的字段", query: business.CustomerQuery{Sorts: []business.CustomerSort{{Field: "createdBy"}}}, wantErr: true},
+		{name: "筛选条件过多", query: business.CustomerQuery{Filters: make([]business.CustomerFilter, CustomerQueryMaxFilters+1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &CustomClaims{ID: 7, AuthorityId: "100", TenantId: 1}
+			if tt.viewPhone {
+				claims.AuthorityId = "200"
+			}
+			scope, err := CustomerQueryScope(newTestContext(claims), tt.query, true)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CustomerQueryScope() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			query := db.Model(&business.Customer{}).Scopes(scope)
+			if len(tt.query.Sorts) == 0 {
+				query = query.Order("id ASC")
+			}
+			var ids []uint
+			if err = query.Pluck("id", &ids).Error; err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("匹配的客户 = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeCustomerQuery(t *testing.T) {
+	view := business.CustomerQuery{
+		Keyword: "上海",
+		Filters: []business.CustomerFilter{{Field: "customerStatus", Op: "eq", Value: json.RawMessage(`"new"`)}},
+		Sorts:   []business.CustomerSort{{Field: "createdAt", Desc: true}},
+	}
+	extra := business.CustomerQuery{
+		Filters: []business.CustomerFilter{{Field: "ownerId", Op: "eq", Value: json.RawMessage(`"me"`)}},
+	}
+	merged := MergeCustomerQuery(view, extra)
+	if merged.Keyword != "上海" || len(merged.Filters) != 2 || !reflect.DeepEqual(merged.Sorts, view.Sorts) {
+		t.Errorf("MergeCustomerQuery() = %+v", merged)
+	}
+	if len(view.Filters) != 1 {
+		t.Errorf("MergeCustomerQuery() 修改了视图的条件")
+	}
+
+	extra.Keyword, extra.Sorts = "北京", []business.CustomerSort{{Field: "id"}}
+	merged = MergeCustomerQuery(view, extra)
+	if merged.Keyword != "北京" || !reflect.DeepEqual(merged.Sorts, extra.Sorts) {
+		t.Errorf("MergeCustomerQuery() 关键字和排序 = %q, %+v", merged.Keyword, merged.Sorts)
+	}
+}
+
+func TestCustomerQueryFieldsHidesPhoneWithoutPermission(t *testing.T) {
+	db := newTestDB(t, &business.CustomerField{}, &system.SysAuthority{}, &system.SysAuthorityGrant{})
+	if err := db.Create(&system.SysAuthority{AuthorityId: "200", AuthorityName: "经理", Enable: true, Permissions: []string{system.PermissionCustomerViewPhone}}).Error; err != nil {
+		t.Fatal(err)
+	}
+	for authorityId, want := range map[string]bool{"100": false, "200": true, "888": true} {
+		fields, err := CustomerQueryFields(newTestContext(&CustomClaims{ID: 7, AuthorityId: authorityId, TenantId: 1}), db, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, field := range fields {
+			found = found || field.Field == "customerPhone"
+		}
+		if found != want {
+			t.Errorf("角色 %s 的查询字段包含客户电话 = %v, want %v", authorityId, found, want)
+		}
+	}
+}
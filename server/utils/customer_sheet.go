@@ -0,0 +1,272 @@
+package utils
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/system"
+)
+
+// CustomerSheetFieldPrefix 自定义字段列标识的前缀，如 cf.level
+const CustomerSheetFieldPrefix = "cf."
+
+// CustomerPoolOwnerName 导入导出时表示公海的负责人名称
+const CustomerPoolOwnerName = "公海"
+
+// CustomerSheetColumn 客户导入导出的列
+type CustomerSheetColumn struct {
+	Key      string `json:"key"`      // 列标识，自定义字段为 cf.字段标识
+	Name     string `json:"name"`     // 列名，即导出的表头
+	Required bool   `json:"required"` // 导入时是否必填
+	Import   bool   `json:"import"`   // 是否可以导入
+	Export   bool   `json:"export"`   // 是否可以导出
+	Remark   string `json:"remark"`   // 导入时的填写说明
+}
+
+// customerSheetColumns 客户的固定列，自定义字段列在其后
+var customerSheetColumns = []CustomerSheetColumn{
+	{Key: "id", Name: "ID", Export: true},
+	{Key: "customerName", Name: "客户名称", Required: true, Import: true, Export: true},
+	{Key: "customerPhone", Name: "客户电话", Required: true, Import: true, Export: true, Remark: "保存为 E.164 格式"},
+	{Key: "customerStatus", Name: "客户状态", Import: true, Export: true, Remark: "状态名称或编码，不填时为初始状态"},
+	{Key: "owner", Name: "负责人", Import: true, Export: true, Remark: "用户ID、用户名或昵称，填“公海”时放入公海，不填时由导入人负责"},
+	{Key: "contactName", Name: "联系人", Import: true, Export: true, Remark: "主联系人姓名"},
+	{Key: "contactPhone", Name: "联系人电话", Import: true, Export: true, Remark: "多个电话用逗号分隔"},
+	{Key: "contactEmail", Name: "联系人邮箱", Import: true, Export: true, Remark: "多个邮箱用逗号分隔"},
+	{Key: "address", Name: "地址", Import: true, Export: true, Remark: "默认地址的详细地址"},
+	{Key: "tags", Name: "标签", Import: true, Export: true, Remark: "多个标签用逗号分隔"},
+	{Key: "lastFollowedAt", Name: "最近跟进时间", Export: true},
+	{Key: "createdAt", Name: "创建时间", Export: true},
+}
+
+// CustomerSheetColumns 租户的客户导入导出列，包括自定义字段
+func CustomerSheetColumns(db *gorm.DB, tenantId uint) ([]CustomerSheetColumn, []business.CustomerField, error) {
+	fields, err := GetCustomerFields(db, tenantId)
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := append([]CustomerSheetColumn{}, customerSheetColumns...)
+	for _, field := range fields {
+		column := CustomerSheetColumn{
+			Key:      CustomerSheetFieldPrefix + field.FieldKey,
+			Name:     field.Name,
+			Required: field.Required,
+			Import:   true,
+			Export:   true,
+		}
+		switch field.Type {
+		case business.CustomerFieldDate:
+			column.Remark = "格式 YYYY-MM-DD"
+		case business.CustomerFieldSelect:
+			column.Remark = "可选值: " + strings.Join(field.Options, ", ")
+		case business.CustomerFieldMultiSelect:
+			column.Remark = "多个值用逗号分隔，可选值: " + strings.Join(field.Options, ", ")
+		case business.CustomerFieldUser:
+			column.Remark = "用户ID、用户名或昵称"
+		}
+		columns = append(columns, column)
+	}
+	return columns, fields, nil
+}
+
+// MatchCustomerSheetColumns 按列名或列标识（不区分大小写）自动匹配表头，返回表头 -> 列标识，未匹配的表头不包含在内
+func MatchCustomerSheetColumns(header []string, columns []CustomerSheetColumn) map[string]string {
+	mapping := make(map[string]string)
+	used := make(map[string]bool)
+	for _, name := range header {
+		name = strings.TrimSpace(name)
+		for _, column := range columns {
+			if !column.Import || used[column.Key] {
+				continue
+			}
+			if strings.EqualFold(name, column.Name) || strings.EqualFold(name, column.Key) {
+				mapping[name] = column.Key
+				used[column.Key] = true
+				break
+			}
+		}
+	}
+	return mapping
+}
+
+// SplitSheetList 拆分单元格中用逗号（含中文逗号）、分号分隔的多个值
+func SplitSheetList(cell string) []string {
+	items := strings.FieldsFunc(cell, func(r rune) bool {
+		return r == ',' || r == '，' || r == ';' || r == '；'
+	})
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// CustomerFieldCellValue 将单元格内容转换为自定义字段值，交由 SaveCustomerFieldValues 校验
+// 用户字段按 users（用户ID、用户名、昵称 -> 用户ID）转换，无法识别时原样传入，由校验给出错误
+func CustomerFieldCellValue(field business.CustomerField, cell string, users map[string]uint) json.RawMessage {
+	var value interface{} = cell
+	switch field.Type {
+	case business.CustomerFieldNumber:
+		if num, err := strconv.ParseFloat(cell, 64); err == nil {
+			value = num
+		}
+	case business.CustomerFieldMultiSelect:
+		value = SplitSheetList(cell)
+	case business.CustomerFieldUser:
+		if id, ok := users[cell]; ok {
+			value = id
+		}
+	}
+	data, _ := json.Marshal(value)
+	return data
+}
+
+// CustomerFieldCellText 将 FillCustomerFields 填充的自定义字段值转换为单元格内容，用户字段显示为昵称
+func CustomerFieldCellText(value interface{}, userNames map[uint]string) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case uint:
+		if name, ok := userNames[v]; ok {
+			return name
+		}
+		return strconv.Itoa(int(v))
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// TenantUserKeys 租户内用户的用户ID、用户名、昵称 -> 用户ID，用于导入时识别用户
+func TenantUserKeys(db *gorm.DB, tenantId uint) (map[string]uint, error) {
+	var users []system.SysUser
+	if err := db.Select("id", "username", "nick_name").Where(&system.SysUser{TenantId: tenantId}).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	keys := make(map[string]uint, len(users)*3)
+	for _, user := range users {
+		keys[user.NickName] = user.ID
+		keys[user.Username] = user.ID
+	}
+	// 用户ID优先，避免与纯数字的用户名混淆
+	for _, user := range users {
+		keys[strconv.Itoa(int(user.ID))] = user.ID
+	}
+	return keys, nil
+}
+
+// CustomerSheetExporter 按列将客户转换为导出的行，客户需已填充自定义字段
+type CustomerSheetExporter struct {
+	db        *gorm.DB
+	keys      []string
+	workflows map[uint]business.CustomerWorkflow
+	c         *gin.Context // 不为空时按当前用户的权限脱敏，见 Mask
+}
+
+// NewCustomerSheetExporter 创建客户导出器，keys 为导出的列标识
+func NewCustomerSheetExporter(db *gorm.DB, keys []string) *CustomerSheetExporter {
+	return &CustomerSheetExporter{db: db, keys: keys, workflows: make(map[uint]business.CustomerWorkflow)}
+}
+
+// MaskFor 按用户的权限对导出的电话等字段脱敏
+func (e *CustomerSheetExporter) MaskFor(c *gin.Context) *CustomerSheetExporter {
+	e.c = c
+	return e
+}
+
+// Rows 转换一批客户，状态显示为名称，负责人和用户字段显示为昵称，联系人和地址取主联系人和默认地址
+func (e *CustomerSheetExporter) Rows(customers []business.Customer) ([][]string, error) {
+	ids := make([]uint, 0, len(customers))
+	userIds := make([]uint, 0)
+	for _, customer := range customers {
+		ids = append(ids, customer.ID)
+		userIds = append(userIds, customer.OwnerID)
+		for _, value := range customer.CustomFields {
+			if id, ok := value.(uint); ok {
+				userIds = append(userIds, id)
+			}
+		}
+		if _, ok := e.workflows[customer.TenantID]; !ok {
+			workflow, err := GetCustomerWorkflow(e.db, customer.TenantID)
+			if err != nil {
+				return nil, err
+			}
+			e.workflows[customer.TenantID] = workflow
+		}
+	}
+	userNames, err := customerOwnerNames(e.db, userIds...)
+	if err != nil {
+		return nil, err
+	}
+	var contacts []business.CustomerContact
+	if err = e.db.Where("customer_id IN ? AND is_primary = ?", ids, true).Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+	if e.c != nil {
+		Mask(e.c, customers)
+		Mask(e.c, contacts)
+	}
+	contactMap := make(map[uint]business.CustomerContact, len(contacts))
+	for _, contact := range contacts {
+		contactMap[contact.CustomerID] = contact
+	}
+	var addresses []business.CustomerAddress
+	if err = e.db.Where("customer_id IN ? AND is_primary = ?", ids, true).Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+	addressMap := make(map[uint]business.CustomerAddress, len(addresses))
+	for _, address := range addresses {
+		addressMap[address.CustomerID] = address
+	}
+
+	rows := make([][]string, 0, len(customers))
+	for _, customer := range customers {
+		contact := contactMap[customer.ID]
+		row := make([]string, len(e.keys))
+		for i, key := range e.keys {
+			switch key {
+			case "id":
+				row[i] = strconv.Itoa(int(customer.ID))
+			case "customerName":
+				row[i] = customer.CustomerName
+			case "customerPhone":
+				row[i] = customer.CustomerPhone
+			case "customerStatus":
+				row[i] = WorkflowStatusName(e.workflows[customer.TenantID], customer.CustomerStatus)
+			case "owner":
+				row[i] = userNames[customer.OwnerID]
+			case "contactName":
+				row[i] = contact.Name
+			case "contactPhone":
+				row[i] = strings.Join(contact.Phones, ",")
+			case "contactEmail":
+				row[i] = strings.Join(contact.Emails, ",")
+			case "address":
+				row[i] = addressMap[customer.ID].Detail
+			case "tags":
+				row[i] = strings.Join(customer.Tags, ",")
+			case "lastFollowedAt":
+				if customer.LastFollowedAt != nil {
+					row[i] = customer.LastFollowedAt.Format("2006-01-02 15:04:05")
+				}
+			case "createdAt":
+				row[i] = customer.CreatedAt.Format("2006-01-02 15:04:05")
+			default:
+				row[i] = CustomerFieldCellText(customer.CustomFields[strings.TrimPrefix(key, CustomerSheetFieldPrefix)], userNames)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
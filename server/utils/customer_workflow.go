@@ -0,0 +1,269 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/system"
+)
+
+// workflowStatusPattern 状态编码格式：字母开头，只能包含字母、数字和下划线
+var workflowStatusPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,31}$`)
+
+// DefaultCustomerWorkflow 内置的客户状态流程，租户未配置流程时使用
+func DefaultCustomerWorkflow(tenantId uint) business.CustomerWorkflow {
+	return business.CustomerWorkflow{
+		TenantID: tenantId,
+		Statuses: []business.WorkflowStatus{
+			{Code: "new", Name: "新建", Initial: true, Color: "#909399"},
+			{Code: "following", Name: "跟进中", Color: "#409EFF"},
+			{Code: "negotiating", Name: "商务洽谈", Color: "#E6A23C"},
+			{Code: "won", Name: "成交", Final: true, Color: "#67C23A"},
+			{Code: "lost", Name: "流失", Final: true, Color: "#F56C6C"},
+		},
+		Transitions: []business.WorkflowTransition{
+			{From: "new", To: "following", Name: "开始跟进"},
+			{From: "following", To: "negotiating", Name: "进入洽谈"},
+			{From: "negotiating", To: "following", Name: "退回跟进"},
+			{From: "negotiating", To: "won", Name: "成交"},
+			{From: business.WorkflowAnyStatus, To: "lost", Name: "标记流失", RequireReason: true},
+			{From: "lost", To: "following", Name: "重新跟进"},
+		},
+	}
+}
+
+// GetCustomerWorkflow 获取租户的客户状态流程，未配置时返回内置的默认流程
+func GetCustomerWorkflow(db *gorm.DB, tenantId uint) (business.CustomerWorkflow, error) {
+	var workflow business.CustomerWorkflow
+	result := db.Where(&business.CustomerWorkflow{TenantID: tenantId}).Limit(1).Find(&workflow)
+	if result.Error != nil {
+		return workflow, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return DefaultCustomerWorkflow(tenantId), nil
+	}
+	return workflow, nil
+}
+
+// ValidateCustomerWorkflow 检查客户状态流程是否合法
+func ValidateCustomerWorkflow(db *gorm.DB, workflow business.CustomerWorkflow) error {
+	if len(workflow.Statuses) == 0 {
+		return errors.New("至少需要一个状态")
+	}
+	codes := make(map[string]bool, len(workflow.Statuses))
+	initials := 0
+	for _, status := range workflow.Statuses {
+		if !workflowStatusPattern.MatchString(status.Code) {
+			return fmt.Errorf("状态编码 %s 格式不正确，必须以字母开头，只能包含字母、数字和下划线，且不超过32个字符", status.Code)
+		}
+		if strings.TrimSpace(status.Name) == "" {
+			return fmt.Errorf("状态 %s 的名称不能为空", status.Code)
+		}
+		if codes[status.Code] {
+			return fmt.Errorf("状态编码 %s 重复", status.Code)
+		}
+		codes[status.Code] = true
+		if status.Initial {
+			initials++
+		}
+	}
+	if initials != 1 {
+		return errors.New("必须有且只有一个初始状态")
+	}
+
+	var fieldKeys []string
+	if err := db.Model(&business.CustomerField{}).Where(&business.CustomerField{TenantID: workflow.TenantID}).Pluck("field_key", &fieldKeys).Error; err != nil {
+		return errors.New("获取自定义字段失败")
+	}
+	pairs := make(map[string]bool, len(workflow.Transitions))
+	for _, transition := range workflow.Transitions {
+		if transition.From != business.WorkflowAnyStatus && !codes[transition.From] {
+			return fmt.Errorf("流转规则的起始状态 %s 不存在", transition.From)
+		}
+		if !codes[transition.To] {
+			return fmt.Errorf("流转规则的目标状态 %s 不存在", transition.To)
+		}
+		if transition.From == transition.To {
+			return fmt.Errorf("流转规则的起始状态和目标状态不能相同: %s", transition.To)
+		}
+		pair := transition.From + "->" + transition.To
+		if pairs[pair] {
+			return fmt.Errorf("流转规则 %s 重复", pair)
+		}
+		pairs[pair] = true
+		for _, key := range transition.RequiredFields {
+			if !ContainsString(fieldKeys, key) {
+				return fmt.Errorf("流转规则 %s 的必填字段 %s 不存在", pair, key)
+			}
+		}
+		if len(transition.AuthorityIds) > 0 {
+			var count int64
+			if err := db.Model(&system.SysAuthority{}).Where("authority_id IN ?", transition.AuthorityIds).Count(&count).Error; err != nil {
+				return errors.New("查询角色失败")
+			}
+			if int(count) != len(transition.AuthorityIds) {
+				return fmt.Errorf("流转规则 %s 中的角色不存在", pair)
+			}
+		}
+	}
+	return nil
+}
+
+// WorkflowInitialStatus 流程的初始状态
+func WorkflowInitialStatus(workflow business.CustomerWorkflow) business.WorkflowStatus {
+	for _, status := range workflow.Statuses {
+		if status.Initial {
+			return status
+		}
+	}
+	return business.WorkflowStatus{}
+}
+
+// WorkflowHasStatus 流程中是否包含该状态
+func WorkflowHasStatus(workflow business.CustomerWorkflow, code string) bool {
+	for _, status := range workflow.Statuses {
+		if status.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkflowStatusName 状态名称，状态不在流程中时（如旧数据）返回编码本身
+func WorkflowStatusName(workflow business.CustomerWorkflow, code string) string {
+	for _, status := range workflow.Statuses {
+		if status.Code == code {
+			return status.Name
+		}
+	}
+	return code
+}
+
+// FindWorkflowTransition 查找从 from 到 to 的流转规则，明确指定起始状态的规则优先于任意状态的规则
+func FindWorkflowTransition(workflow business.CustomerWorkflow, from, to string) (business.WorkflowTransition, bool) {
+	var fallback *business.WorkflowTransition
+	for i, transition := range workflow.Transitions {
+		if transition.To != to {
+			continue
+		}
+		if transition.From == from {
+			return transition, true
+		}
+		if transition.From == business.WorkflowAnyStatus && from != to {
+			fallback = &workflow.Transitions[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return business.WorkflowTransition{}, false
+}
+
+// CanPerformTransition 判断用户是否可以执行流转，超级管理员和当前生效的临时授权角色同样适用
+func CanPerformTransition(claims *CustomClaims, transition business.WorkflowTransition) bool {
+	if len(transition.AuthorityIds) == 0 || claims.AuthorityId == "888" || ContainsString(transition.AuthorityIds, claims.AuthorityId) {
+		return true
+	}
+	for _, grant := range ActiveGrants(claims.ID) {
+		if ContainsString(transition.AuthorityIds, grant.AuthorityId) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRequiredCustomerFields 检查客户的自定义字段是否已填写
+func CheckRequiredCustomerFields(tx *gorm.DB, customer business.Customer, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	fields, err := GetCustomerFields(tx, customer.TenantID)
+	if err != nil {
+		return errors.New("获取自定义字段失败")
+	}
+	for _, field := range fields {
+		if !ContainsString(keys, field.FieldKey) {
+			continue
+		}
+		var count int64
+		err = tx.Model(&business.CustomerFieldValue{}).Where(&business.CustomerFieldValue{CustomerID: customer.ID, FieldID: field.ID}).Count(&count).Error
+		if err != nil {
+			return errors.New("查询自定义字段失败")
+		}
+		if count == 0 {
+			return fmt.Errorf("请先填写%s", field.Name)
+		}
+	}
+	return nil
+}
+
+// StartCustomerStatusHistory 新建客户时记录进入初始状态
+func StartCustomerStatusHistory(tx *gorm.DB, customer business.Customer, userId uint) error {
+	return tx.Create(&business.CustomerStatusHistory{
+		TenantID:   customer.TenantID,
+		CustomerID: customer.ID,
+		Status:     customer.CustomerStatus,
+		ChangedBy:  userId,
+		EnteredAt:  customer.CreatedAt,
+	}).Error
+}
+
+// ChangeCustomerStatus 修改客户状态：结束当前状态的历史记录、记录进入新状态，并写入客户时间线
+// 需要在事务中调用，客户状态已被其他请求修改时返回错误
+func ChangeCustomerStatus(tx *gorm.DB, customer business.Customer, workflow business.CustomerWorkflow, to string, reason string, userId uint) error {
+	now := time.Now()
+	result := tx.Model(&business.Customer{}).
+		Where("id = ? AND customer_status = ?", customer.ID, customer.CustomerStatus).
+		Update("customer_status", to)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("客户状态已被修改，请刷新后重试")
+	}
+
+	// 结束当前状态；升级前创建的客户没有历史记录，以客户创建时间作为进入当前状态的时间
+	var current business.CustomerStatusHistory
+	found := tx.Where("customer_id = ? AND left_at IS NULL", customer.ID).Order("id DESC").Limit(1).Find(&current)
+	if found.Error != nil {
+		return found.Error
+	}
+	if found.RowsAffected == 0 {
+		current = business.CustomerStatusHistory{
+			TenantID:   customer.TenantID,
+			CustomerID: customer.ID,
+			Status:     customer.CustomerStatus,
+			EnteredAt:  customer.CreatedAt,
+		}
+	}
+	current.LeftAt = &now
+	current.Duration = int64(now.Sub(current.EnteredAt).Seconds())
+	if err := tx.Save(&current).Error; err != nil {
+		return err
+	}
+
+	err := tx.Create(&business.CustomerStatusHistory{
+		TenantID:   customer.TenantID,
+		CustomerID: customer.ID,
+		FromStatus: customer.CustomerStatus,
+		Status:     to,
+		Reason:     reason,
+		ChangedBy:  userId,
+		EnteredAt:  now,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf("%s: %s → %s", ActivityTypeNames[business.ActivityStatus],
+		displayActivityValue(WorkflowStatusName(workflow, customer.CustomerStatus)), WorkflowStatusName(workflow, to))
+	if reason != "" {
+		content += "，原因: " + reason
+	}
+	return RecordCustomerChange(tx, customer, business.ActivityStatus, customer.CustomerStatus, to, content, userId)
+}
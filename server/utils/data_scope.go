@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/system"
+)
+
+// DeptAndChildIds 获取部门及其所有下级部门的ID
+func DeptAndChildIds(deptId uint) []uint {
+	var depts []system.SysDept
+	global.JY_DB.Select("id", "parent_id").Find(&depts)
+
+	children := make(map[uint][]uint)
+	for _, dept := range depts {
+		children[dept.ParentId] = append(children[dept.ParentId], dept.ID)
+	}
+
+	ids := []uint{deptId}
+	visited := map[uint]bool{deptId: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			// 防止脏数据形成环导致死循环
+			if !visited[child] {
+				visited[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// dataScopeRange 角色数据范围解析后的结果
+type dataScopeRange struct {
+	All     bool   // 不做过滤
+	Self    bool   // 只能访问本人的数据
+	DeptIds []uint // 可访问这些部门下用户的数据，All 和 Self 都为 false 且为空时不能访问任何数据
+	Detail  string // 判定说明
+}
+
+// resolveDataScope 按角色的数据范围和用户所在部门解析出实际可访问的数据，DataScope 和权限模拟共用
+// authority 需要预加载 DataScopeDepts
+func resolveDataScope(authority *system.SysAuthority, userId uint) dataScopeRange {
+	switch authority.DataScope {
+	case system.DataScopeAll, "":
+		return dataScopeRange{All: true, Detail: "数据范围为全部数据"}
+	case system.DataScopeSelf:
+		return dataScopeRange{Self: true, Detail: "数据范围为仅本人数据"}
+	case system.DataScopeCustom:
+		var deptIds []uint
+		for _, dept := range authority.DataScopeDepts {
+			deptIds = append(deptIds, dept.ID)
+		}
+		return dataScopeRange{DeptIds: deptIds, Detail: "数据范围为自定义部门"}
+	case system.DataScopeDept, system.DataScopeDeptAndChild:
+		var user system.SysUser
+		if err := global.JY_DB.Select("id", "dept_id").Where("id = ?", userId).First(&user).Error; err != nil || user.DeptId == 0 {
+			// 未分配部门的用户只能看到自己的数据
+			return dataScopeRange{Self: true, Detail: fmt.Sprintf("数据范围为 %s，但用户未分配部门，只能访问本人数据", authority.DataScope)}
+		}
+		if authority.DataScope == system.DataScopeDeptAndChild {
+			return dataScopeRange{DeptIds: DeptAndChildIds(user.DeptId), Detail: fmt.Sprintf("数据范围为本部门(ID:%d)及以下", user.DeptId)}
+		}
+		return dataScopeRange{DeptIds: []uint{user.DeptId}, Detail: fmt.Sprintf("数据范围为本部门(ID:%d)", user.DeptId)}
+	default:
+		return dataScopeRange{Detail: fmt.Sprintf("未知的数据范围 %s", authority.DataScope)}
+	}
+}
+
+// ExplainDataScope 说明用户实际生效的数据范围，与 DataScope 的过滤规则一致
+// 数据范围只取用户自己的角色，不受临时授权影响；userId 为 0 时按部门计算的范围无法展开
+func ExplainDataScope(userId uint, authority *system.SysAuthority) PermissionStep {
+	step := PermissionStep{Rule: "data-scope", Result: PermissionInfo}
+	if authority.AuthorityId == "888" {
+		step.Detail = "超级管理员(888)可访问全部数据"
+		return step
+	}
+	if userId == 0 && (authority.DataScope == system.DataScopeDept || authority.DataScope == system.DataScopeDeptAndChild) {
+		step.Detail = fmt.Sprintf("数据范围为 %s，需指定用户才能按所在部门计算", authority.DataScope)
+		return step
+	}
+
+	scope := resolveDataScope(authority, userId)
+	switch {
+	case scope.All, scope.Self:
+		step.Detail = scope.Detail
+	case len(scope.DeptIds) == 0:
+		step.Detail = scope.Detail + "，不能访问任何数据"
+	default:
+		ids := make([]string, 0, len(scope.DeptIds))
+		for _, id := range scope.DeptIds {
+			ids = append(ids, strconv.FormatUint(uint64(id), 10))
+		}
+		step.Detail = scope.Detail + "，可访问部门: " + strings.Join(ids, ", ")
+	}
+	return step
+}
+
+// DataScope 按当前用户角色的数据范围过滤数据
+// column 为记录归属用户ID的字段，超级管理员和数据范围为全部的角色不做过滤
+func DataScope(c *gin.Context, column string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		claims, exists := c.Get("claims")
+		if !exists {
+			return db.Where("1 = 0")
+		}
+		waitClaims := claims.(*CustomClaims)
+		if waitClaims.AuthorityId == "888" {
+			return db
+		}
+
+		var authority system.SysAuthority
+		err := global.JY_DB.Where("authority_id = ?", waitClaims.AuthorityId).Preload("DataScopeDepts").First(&authority).Error
+		if err != nil {
+			return db.Where("1 = 0")
+		}
+
+		scope := resolveDataScope(&authority, waitClaims.ID)
+		if scope.All {
+			return db
+		}
+		if scope.Self {
+			return db.Where(column+" = ?", waitClaims.ID)
+		}
+		if len(scope.DeptIds) == 0 {
+			return db.Where("1 = 0")
+		}
+		userIds := global.JY_DB.Model(&system.SysUser{}).Select("id").Where("dept_id IN ?", scope.DeptIds)
+		return db.Where(column+" IN (?)", userIds)
+	}
+}
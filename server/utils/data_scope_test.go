@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"jiangyi.com/global"
+	"jiangyi.com/model/system"
+)
+
+func TestExplainDataScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		userId    uint
+		authority system.SysAuthority
+		want      string
+	}{
+		{name: "超级管理员", userId: 7, authority: system.SysAuthority{AuthorityId: "888", DataScope: system.DataScopeSelf}, want: "超级管理员(888)可访问全部数据"},
+		{name: "全部数据", userId: 7, authority: system.SysAuthority{AuthorityId: "100", DataScope: system.DataScopeAll}, want: "数据范围为全部数据"},
+		{name: "仅本人", userId: 7, authority: system.SysAuthority{AuthorityId: "100", DataScope: system.DataScopeSelf}, want: "数据范围为仅本人数据"},
+		{name: "本部门", userId: 7, authority: system.SysAuthority{AuthorityId: "100", DataScope: system.DataScopeDept}, want: "数据范围为本部门(ID:2)，可访问部门: 2"},
+		{name: "本部门及以下", userId: 7, authority: system.SysAuthority{AuthorityId: "100", DataScope: system.DataScopeDeptAndChild}, want: "数据范围为本部门(ID:2)及以下，可访问部门: 2, 3, 4"},
+		{name: "未分配部门", userId: 8, authority: system.SysAuthority{AuthorityId: "100", DataScope: system.DataScopeDeptAndChild}, want: "数据范围为 deptAndChild，但用户未分配部门，只能访问本人数据"},
+		{name: "只指定角色", authority: system.SysAuthority{AuthorityId: "100", DataScope: system.DataScopeDept}, want: "数据范围为 dept，需指定用户才能按所在部门计算"},
+		{
+			name:      "自定义部门",
+			userId:    7,
+			authority: system.SysAuthority{AuthorityId: "100", DataScope: system.DataScopeCustom, DataScopeDepts: []system.SysDept{{GlobalModel: global.GlobalModel{ID: 1}}, {GlobalModel: global.GlobalModel{ID: 3}}}},
+			want:      "数据范围为自定义部门，可访问部门: 1, 3",
+		},
+		{name: "自定义部门为空", userId: 7, authority: system.SysAuthority{AuthorityId: "100", DataScope: system.DataScopeCustom}, want: "数据范围为自定义部门，不能访问任何数据"},
+	}
+
+	db := newTestDB(t, &system.SysUser{}, &system.SysDept{})
+	// 部门树：1 -> 2 -> 3 -> 4，用户7属于部门2，用户8未分配部门
+	for id, parentId := range map[uint]uint{1: 0, 2: 1, 3: 2, 4: 3} {
+		dept := system.SysDept{ParentId: parentId, Name: "部门"}
+		dept.ID = id
+		if err := db.Create(&dept).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for id, deptId := range map[uint]uint{7: 2, 8: 0} {
+		user := system.SysUser{Username: fmt.Sprintf("u%d", id), NickName: fmt.Sprintf("用户%d", id), DeptId: deptId}
+		user.ID = id
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step := ExplainDataScope(tt.userId, &tt.authority)
+			if step.Rule != "data-scope" || step.Result != PermissionInfo {
+				t.Errorf("step = %s/%s, want data-scope/%s", step.Rule, step.Result, PermissionInfo)
+			}
+			if step.Detail != tt.want {
+				t.Errorf("Detail = %q, want %q", step.Detail, tt.want)
+			}
+		})
+	}
+}
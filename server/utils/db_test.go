@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"jiangyi.com/global"
+)
+
+// newTestDB 创建临时的 sqlite 数据库并迁移 models，测试期间替换 global.JY_DB
+func newTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	previous := global.JY_DB
+	global.JY_DB = db
+	t.Cleanup(func() {
+		global.JY_DB = previous
+	})
+	return db
+}
+
+// newTestContext 创建已登录用户的请求上下文
+func newTestContext(claims *CustomClaims) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("claims", claims)
+	return c
+}
@@ -0,0 +1,28 @@
+package utils
+
+import "jiangyi.com/model/system"
+
+// 临时角色授权的通知事件
+const (
+	GrantEventCreated = "created" // 创建授权
+	GrantEventRevoked = "revoked" // 手动撤销
+	GrantEventExpired = "expired" // 到期收回
+)
+
+// GrantNotifier 临时角色授权的通知方式，如站内信、邮件，收件人（被授权用户、审批人）由实现决定
+type GrantNotifier func(grant system.SysAuthorityGrant, event string)
+
+// grantNotifiers 已注册的通知方式
+var grantNotifiers []GrantNotifier
+
+// RegisterGrantNotifier 注册临时角色授权的通知方式，需在服务启动前注册
+func RegisterGrantNotifier(notifier GrantNotifier) {
+	grantNotifiers = append(grantNotifiers, notifier)
+}
+
+// NotifyGrant 通知临时角色授权的变化，未注册通知方式时不做任何处理
+func NotifyGrant(grant system.SysAuthorityGrant, event string) {
+	for _, notifier := range grantNotifiers {
+		notifier(grant, event)
+	}
+}
@@ -2,7 +2,10 @@ package utils
 
 import (
 	"crypto/md5"
+	"crypto/rand"
 	"encoding/hex"
+	"errors"
+	"math/big"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -19,6 +22,30 @@ func BcryptCheck(password, hash string) bool {
 	return err == nil
 }
 
+// PasswordMinLength 密码最小长度
+const PasswordMinLength = 6
+
+// ValidatePassword 校验密码是否符合密码策略
+func ValidatePassword(password string) error {
+	if len(password) < PasswordMinLength {
+		return errors.New("密码长度不能少于6位")
+	}
+	return nil
+}
+
+// passwordChars 随机密码字符集，去掉了容易混淆的 0/O、1/l/I
+const passwordChars = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// RandomPassword 生成指定长度的随机密码
+func RandomPassword(length int) string {
+	b := make([]byte, length)
+	for i := range b {
+		n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(passwordChars))))
+		b[i] = passwordChars[n.Int64()]
+	}
+	return string(b)
+}
+
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: MD5V
 //@description: md5加密
@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// JSONSchema JSON Schema 的常用子集，用于校验配置类数据
+// 支持 type、enum、minimum/maximum、minLength/maxLength、pattern、format(timezone)、
+// properties/required/additionalProperties、items、minItems/maxItems
+type JSONSchema struct {
+	Type                 string                 `json:"type"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+var jsonSchemaTypes = map[string]bool{
+	"": true, "string": true, "number": true, "integer": true, "boolean": true, "object": true, "array": true,
+}
+
+// ParseJSONSchema 解析并检查 JSON Schema 本身是否合法
+func ParseJSONSchema(raw []byte) (*JSONSchema, error) {
+	var schema JSONSchema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("JSON Schema 格式错误: %v", err)
+	}
+	if err := schema.compile("$"); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// compile 检查类型和格式，并预编译正则
+func (s *JSONSchema) compile(path string) error {
+	if !jsonSchemaTypes[s.Type] {
+		return fmt.Errorf("%s: 不支持的类型 %s", path, s.Type)
+	}
+	if s.Format != "" && s.Format != "timezone" {
+		return fmt.Errorf("%s: 不支持的格式 %s", path, s.Format)
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: 正则表达式错误: %v", path, err)
+		}
+		s.pattern = re
+	}
+	for name, property := range s.Properties {
+		if property == nil {
+			return fmt.Errorf("%s.%s: 属性定义不能为空", path, name)
+		}
+		if err := property.compile(path + "." + name); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		if err := s.Items.compile(path + "[]"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateJSON 按 Schema 校验一段 JSON
+func (s *JSONSchema) ValidateJSON(raw []byte) error {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("JSON 格式错误: %v", err)
+	}
+	if decoder.More() {
+		return errors.New("JSON 格式错误: 包含多余的内容")
+	}
+	return s.Validate(value)
+}
+
+// Validate 按 Schema 校验 json.Unmarshal 得到的值
+func (s *JSONSchema) Validate(value interface{}) error {
+	return s.validate(value, "")
+}
+
+func (s *JSONSchema) validate(value interface{}, path string) error {
+	if len(s.Enum) > 0 {
+		found := false
+		for _, item := range s.Enum {
+			if jsonEqual(item, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s取值必须是 %v 之一", schemaErrorPrefix(path), s.Enum)
+		}
+	}
+
+	switch s.Type {
+	case "":
+		return nil
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s应为字符串", schemaErrorPrefix(path))
+		}
+		length := utf8.RuneCountInString(str)
+		if s.MinLength != nil && length < *s.MinLength {
+			return fmt.Errorf("%s长度不能少于%d", schemaErrorPrefix(path), *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			return fmt.Errorf("%s长度不能超过%d", schemaErrorPrefix(path), *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(str) {
+			return fmt.Errorf("%s格式不正确", schemaErrorPrefix(path))
+		}
+		if s.Format == "timezone" {
+			if _, err := time.LoadLocation(str); err != nil || str == "" || str == "Local" {
+				return fmt.Errorf("%s无效的时区 %s", schemaErrorPrefix(path), str)
+			}
+		}
+	case "number", "integer":
+		num, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("%s应为数字", schemaErrorPrefix(path))
+		}
+		if s.Type == "integer" && num != math.Trunc(num) {
+			return fmt.Errorf("%s应为整数", schemaErrorPrefix(path))
+		}
+		if s.Minimum != nil && num < *s.Minimum {
+			return fmt.Errorf("%s不能小于%v", schemaErrorPrefix(path), *s.Minimum)
+		}
+		if s.Maximum != nil && num > *s.Maximum {
+			return fmt.Errorf("%s不能大于%v", schemaErrorPrefix(path), *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s应为布尔值", schemaErrorPrefix(path))
+		}
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s应为对象", schemaErrorPrefix(path))
+		}
+		for _, name := range s.Required {
+			if _, exists := obj[name]; !exists {
+				return fmt.Errorf("%s: 不能为空", joinSchemaPath(path, name))
+			}
+		}
+		// 按属性名排序，保证错误信息稳定
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, defined := s.Properties[name]
+			if !defined {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return fmt.Errorf("%s: 不支持的属性", joinSchemaPath(path, name))
+				}
+				continue
+			}
+			if err := property.validate(obj[name], joinSchemaPath(path, name)); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s应为数组", schemaErrorPrefix(path))
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			return fmt.Errorf("%s至少需要%d项", schemaErrorPrefix(path), *s.MinItems)
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			return fmt.Errorf("%s最多允许%d项", schemaErrorPrefix(path), *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				if err := s.Items.validate(item, joinSchemaPath(path, fmt.Sprintf("[%d]", i))); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// joinSchemaPath 拼接校验错误中的字段路径
+func joinSchemaPath(path, name string) string {
+	if path == "" || strings.HasPrefix(name, "[") {
+		return path + name
+	}
+	return path + "." + name
+}
+
+// schemaErrorPrefix 校验错误的字段前缀，顶层值不加前缀
+func schemaErrorPrefix(path string) string {
+	if path == "" {
+		return ""
+	}
+	return path + ": "
+}
+
+// jsonEqual 比较两个 json.Unmarshal 得到的值是否相等
+func jsonEqual(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
@@ -15,7 +15,10 @@ type CustomClaims struct {
 	Username    string
 	NickName    string
 	AuthorityId string
+	TenantId    uint
 	BufferTime  int64
+
+	MustChangePassword bool // 需要先修改密码，此时 token 只能用于修改密码和登出
 	jwt.RegisteredClaims
 }
 
@@ -86,7 +89,10 @@ func CreateClaims(baseClaims CustomClaims) CustomClaims {
 		Username:    baseClaims.Username,
 		NickName:    baseClaims.NickName,
 		AuthorityId: baseClaims.AuthorityId,
+		TenantId:    baseClaims.TenantId,
 		BufferTime:  int64(bf / time.Second),
+
+		MustChangePassword: baseClaims.MustChangePassword,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Audience:  jwt.ClaimStrings{"GVA"},                   // 受众
 			NotBefore: jwt.NewNumericDate(time.Now().Add(-1000)), // 签名生效时间
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLocale 获取当前请求的语言，取 Accept-Language 中权重最高的语言，未指定时返回空字符串
+func RequestLocale(c *gin.Context) string {
+	return ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+}
+
+// ParseAcceptLanguage 解析 Accept-Language 请求头，如 "en-US,en;q=0.9,zh;q=0.8" 返回 "en-US"
+func ParseAcceptLanguage(header string) string {
+	locale := ""
+	best := -1.0
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, field := range fields[1:] {
+			field = strings.TrimSpace(field)
+			if strings.HasPrefix(field, "q=") {
+				if v, err := strconv.ParseFloat(field[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > best {
+			locale, best = tag, q
+		}
+	}
+	return locale
+}
+
+// MatchLocale 判断两个语言是否匹配，先精确匹配（忽略大小写和 _/- 区别），再按主语言匹配（en-US 与 en）
+func MatchLocale(a, b string) (exact bool, matched bool) {
+	a = strings.ToLower(strings.ReplaceAll(a, "_", "-"))
+	b = strings.ToLower(strings.ReplaceAll(b, "_", "-"))
+	if a == "" || b == "" {
+		return false, false
+	}
+	if a == b {
+		return true, true
+	}
+	return false, strings.SplitN(a, "-", 2)[0] == strings.SplitN(b, "-", 2)[0]
+}
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 脱敏方式，用于结构体字段的 mask 标签
+const (
+	MaskPhone     = "phone"     // 电话号码，如 +8613800138000 显示为 138****8000
+	MaskPhoneText = "phoneText" // 文本中的电话号码，长度不变，可以包含 <em> 等标签（如全文检索的高亮片段）
+)
+
+// maskers 脱敏方式对应的处理函数
+var maskers = map[string]func(string) string{
+	MaskPhone:     MaskPhoneNumber,
+	MaskPhoneText: MaskPhoneInText,
+}
+
+// RegisterMasker 注册脱敏方式，用于其他类型的敏感字段
+func RegisterMasker(kind string, masker func(string) string) {
+	maskers[kind] = masker
+}
+
+// MaskValue 按脱敏方式处理字符串，未注册的方式原样返回
+func MaskValue(kind string, value string) string {
+	if masker, ok := maskers[kind]; ok && value != "" {
+		return masker(value)
+	}
+	return value
+}
+
+// Mask 按结构体字段的 mask 标签对当前用户没有权限查看的字段脱敏，value 需为指针或切片，会直接修改其中的值
+// 标签格式为 mask:"脱敏方式,操作权限标识"，如 mask:"phone,customer:view-phone"，拥有该权限的用户看到完整的值；
+// 支持 string 和 []string 字段，会递归处理嵌套的结构体、指针、切片和接口（如 common.PageResult 的 List）
+func Mask(c *gin.Context, value interface{}) {
+	maskValue(c, reflect.ValueOf(value))
+}
+
+func maskValue(c *gin.Context, rv reflect.Value) {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !rv.IsNil() {
+			maskValue(c, rv.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			maskValue(c, rv.Index(i))
+		}
+	case reflect.Struct:
+		rt := rv.Type()
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			tag, ok := field.Tag.Lookup("mask")
+			if !ok {
+				maskValue(c, rv.Field(i))
+				continue
+			}
+			kind, permission, _ := strings.Cut(tag, ",")
+			if !rv.Field(i).CanSet() || (permission != "" && HasPermission(c, permission)) {
+				continue
+			}
+			maskField(rv.Field(i), kind)
+		}
+	}
+}
+
+// maskField 脱敏 string 或 []string 字段
+func maskField(field reflect.Value, kind string) {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(MaskValue(kind, field.String()))
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String || field.IsNil() {
+			return
+		}
+		// 复制后再修改，避免改动与其他值共享的底层数组
+		masked := reflect.MakeSlice(field.Type(), field.Len(), field.Len())
+		for i := 0; i < field.Len(); i++ {
+			masked.Index(i).SetString(MaskValue(kind, field.Index(i).String()))
+		}
+		field.Set(masked)
+	}
+}
+
+// MaskPhoneNumber 电话号码脱敏，保留前 3 位和后 4 位，中国大陆号码去掉 +86，分机号原样保留
+// 如 +8613800138000 显示为 138****8000，+861012345678 显示为 101****5678，过短的号码只保留后 2 位
+func MaskPhoneNumber(phone string) string {
+	number, extension := phone, ""
+	if i := strings.IndexAny(phone, "#*,;"); i >= 0 {
+		number, extension = phone[:i], phone[i:]
+	}
+	prefix := ""
+	if strings.HasPrefix(number, "+"+DefaultPhoneCountryCode) {
+		number = number[len(DefaultPhoneCountryCode)+1:]
+	} else if strings.HasPrefix(number, "+") {
+		prefix, number = "+", number[1:]
+	}
+	runes := []rune(number)
+	keepHead, keepTail := 3, 4
+	if len(runes) < keepHead+keepTail {
+		keepHead, keepTail = 0, 2
+	}
+	if len(runes) <= keepTail {
+		return prefix + strings.Repeat("*", len(runes)) + extension
+	}
+	for i := keepHead; i < len(runes)-keepTail; i++ {
+		runes[i] = '*'
+	}
+	return prefix + string(runes) + extension
+}
+
+// phoneTextMinDigits 文本中至少连续多少位数字视为电话号码
+const phoneTextMinDigits = 7
+
+// MaskPhoneInText 将文本中连续 7 位及以上的数字按电话号码脱敏，保留前 3 位和后 4 位，+86 不计入号码
+// 数字之间的 <em> 等标签不打断号码，文本长度和标签位置不变
+func MaskPhoneInText(text string) string {
+	result := []byte(text)
+	var digits []int // 当前号码中各数字的位置
+	flush := func() {
+		if len(digits) >= phoneTextMinDigits {
+			national := digits
+			// +86 开头的号码跳过区号
+			if first := digits[0]; first > 0 && text[first-1] == '+' && strings.HasPrefix(text[first:], DefaultPhoneCountryCode) &&
+				len(digits) > len(DefaultPhoneCountryCode)+phoneTextMinDigits {
+				national = digits[len(DefaultPhoneCountryCode):]
+			}
+			for _, i := range national[3 : len(national)-4] {
+				result[i] = '*'
+			}
+		}
+		digits = digits[:0]
+	}
+	for i := 0; i < len(text); i++ {
+		switch ch := text[i]; {
+		case ch >= '0' && ch <= '9':
+			digits = append(digits, i)
+		case ch == '<':
+			// 跳过 <em>、</em> 这样的标签
+			if end := strings.IndexByte(text[i:], '>'); end > 0 && isTagName(strings.TrimPrefix(text[i+1:i+end], "/")) {
+				i += end
+				continue
+			}
+			flush()
+		default:
+			flush()
+		}
+	}
+	flush()
+	return string(result)
+}
+
+func isTagName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// RestoreMasked 请求中的值是某个原值脱敏后的结果时（前端将脱敏显示的值原样提交），返回该原值，否则原样返回
+func RestoreMasked(kind string, value string, originals ...string) string {
+	for _, original := range originals {
+		if original != "" && value != original && MaskValue(kind, original) == value {
+			return original
+		}
+	}
+	return value
+}
@@ -0,0 +1,67 @@
+package utils
+
+import "testing"
+
+func TestMaskPhoneNumber(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string
+	}{
+		{"+8613800138000", "138****8000"},
+		{"+861012345678", "101***5678"},
+		{"+861012345678#123", "101***5678#123"},
+		{"+14155552671", "+141****2671"},
+		{"12345", "***45"},
+		{"12", "**"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := MaskPhoneNumber(tt.phone); got != tt.want {
+			t.Errorf("MaskPhoneNumber(%q) = %q, want %q", tt.phone, got, tt.want)
+		}
+	}
+}
+
+func TestMaskPhoneInText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"电话 13800138000，请回电", "电话 138****8000，请回电"},
+		{"+8613800138000", "+86138****8000"},
+		{"<em>138</em>00138000", "<em>138</em>****8000"},
+		{"订单号 123456", "订单号 123456"},
+		{"12345678", "123*5678"},
+		{"1380013800<b>0</b>", "138****800<b>0</b>"},
+	}
+	for _, tt := range tests {
+		if got := MaskPhoneInText(tt.text); got != tt.want {
+			t.Errorf("MaskPhoneInText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRestoreMasked(t *testing.T) {
+	tests := []struct {
+		name      string
+		kind      string
+		value     string
+		originals []string
+		want      string
+	}{
+		{"原样提交脱敏值", MaskPhone, "138****8000", []string{"+8613800138000"}, "+8613800138000"},
+		{"从多个原值中匹配", MaskPhone, "138****8000", []string{"", "+8613900138000", "+8613800138000"}, "+8613800138000"},
+		{"修改后的新号码", MaskPhone, "13900139000", []string{"+8613800138000"}, "13900139000"},
+		{"与脱敏值不一致", MaskPhone, "139****8000", []string{"+8613800138000"}, "139****8000"},
+		{"提交完整号码", MaskPhone, "+8613800138000", []string{"+8613800138000"}, "+8613800138000"},
+		{"没有原值", MaskPhone, "138****8000", nil, "138****8000"},
+		{"未注册的脱敏方式", "unknown", "138****8000", []string{"+8613800138000"}, "138****8000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RestoreMasked(tt.kind, tt.value, tt.originals...); got != tt.want {
+				t.Errorf("RestoreMasked(%q, %q, %q) = %q, want %q", tt.kind, tt.value, tt.originals, got, tt.want)
+			}
+		})
+	}
+}
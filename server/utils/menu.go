@@ -0,0 +1,502 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+	"gorm.io/gorm"
+	"jiangyi.com/model/system"
+)
+
+// MenuExport 菜单导出文件结构
+// 父子关系通过嵌套的 children 表达，菜单和角色的关联通过菜单完整路径表达，不依赖数据库ID，可以在不同环境之间迁移
+type MenuExport struct {
+	Menus       []MenuExportItem      `json:"menus" yaml:"menus"`
+	Authorities []AuthorityMenuExport `json:"authorities" yaml:"authorities"`
+}
+
+// MenuExportItem 导出的菜单
+type MenuExportItem struct {
+	Path        string           `json:"path" yaml:"path"`
+	Name        string           `json:"name" yaml:"name"`
+	Component   string           `json:"component" yaml:"component"`
+	Hidden      bool             `json:"hidden" yaml:"hidden"`
+	Sort        int              `json:"sort" yaml:"sort"`
+	Enable      bool             `json:"enable" yaml:"enable"`
+	Title       string           `json:"title" yaml:"title"`
+	Icon        string           `json:"icon" yaml:"icon"`
+	CloseTab    bool             `json:"closeTab" yaml:"closeTab"`
+	KeepAlive   bool             `json:"keepAlive" yaml:"keepAlive"`
+	DefaultMenu bool             `json:"defaultMenu" yaml:"defaultMenu"`
+	Children    []MenuExportItem `json:"children,omitempty" yaml:"children,omitempty"`
+
+	I18n       map[string]string     `json:"i18n,omitempty" yaml:"i18n,omitempty"` // 多语言标题，key 为语言
+	Parameters []MenuParameterExport `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
+// MenuParameterExport 导出的菜单路由参数
+type MenuParameterExport struct {
+	Type  string `json:"type" yaml:"type"`
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// AuthorityMenuExport 导出的角色菜单关联
+type AuthorityMenuExport struct {
+	AuthorityId string   `json:"authorityId" yaml:"authorityId"`
+	Menus       []string `json:"menus" yaml:"menus"` // 菜单完整路径，如 /system/user
+}
+
+// MenuImportResult 菜单导入结果
+type MenuImportResult struct {
+	Created            int      `json:"created"`            // 新建菜单数
+	Updated            int      `json:"updated"`            // 更新菜单数
+	Authorities        int      `json:"authorities"`        // 更新菜单权限的角色数
+	MissingAuthorities []string `json:"missingAuthorities"` // 不存在而被跳过的角色
+	MissingMenus       []string `json:"missingMenus"`       // 角色关联中引用了但不存在的菜单
+}
+
+// MenuFullPath 拼接菜单完整路径
+func MenuFullPath(parentPath string, path string) string {
+	return strings.TrimRight(parentPath, "/") + "/" + strings.Trim(path, "/")
+}
+
+// menuFullPaths 计算所有菜单的完整路径，key 为菜单ID
+func menuFullPaths(menus []system.SysBaseMenu) map[uint]string {
+	byId := make(map[string]system.SysBaseMenu, len(menus))
+	for _, menu := range menus {
+		byId[strconv.Itoa(int(menu.ID))] = menu
+	}
+
+	paths := make(map[uint]string, len(menus))
+	var resolve func(menu system.SysBaseMenu, depth int) string
+	resolve = func(menu system.SysBaseMenu, depth int) string {
+		if p, ok := paths[menu.ID]; ok {
+			return p
+		}
+		parentPath := ""
+		// depth 限制防止脏数据形成环导致死循环
+		if parent, ok := byId[menu.ParentId]; ok && depth < len(menus) {
+			parentPath = resolve(parent, depth+1)
+		}
+		paths[menu.ID] = MenuFullPath(parentPath, menu.Path)
+		return paths[menu.ID]
+	}
+	for _, menu := range menus {
+		resolve(menu, 0)
+	}
+	return paths
+}
+
+// ExportMenus 导出全部菜单树以及角色菜单关联
+func ExportMenus(db *gorm.DB) (MenuExport, error) {
+	var export MenuExport
+	var menus []system.SysBaseMenu
+	if err := db.Preload("I18n").Preload("Parameters").Order("sort ASC").Find(&menus).Error; err != nil {
+		return export, err
+	}
+	export.Menus = buildMenuExportTree(menus, "0", map[uint]bool{})
+
+	paths := menuFullPaths(menus)
+	var authorities []system.SysAuthority
+	if err := db.Preload("SysBaseMenus").Order("authority_id ASC").Find(&authorities).Error; err != nil {
+		return export, err
+	}
+	for _, authority := range authorities {
+		item := AuthorityMenuExport{AuthorityId: authority.AuthorityId, Menus: []string{}}
+		for _, menu := range authority.SysBaseMenus {
+			if p, ok := paths[menu.ID]; ok {
+				item.Menus = append(item.Menus, p)
+			}
+		}
+		sort.Strings(item.Menus)
+		export.Authorities = append(export.Authorities, item)
+	}
+	return export, nil
+}
+
+// buildMenuExportTree 构建导出菜单树
+func buildMenuExportTree(menus []system.SysBaseMenu, parentId string, visited map[uint]bool) []MenuExportItem {
+	var tree []MenuExportItem
+	for _, menu := range menus {
+		if menu.ParentId != parentId || visited[menu.ID] {
+			continue
+		}
+		visited[menu.ID] = true
+		item := MenuExportItem{
+			Path:        menu.Path,
+			Name:        menu.Name,
+			Component:   menu.Component,
+			Hidden:      menu.Hidden,
+			Sort:        menu.Sort,
+			Enable:      menu.Enable,
+			Title:       menu.Title,
+			Icon:        menu.Icon,
+			CloseTab:    menu.CloseTab,
+			KeepAlive:   menu.KeepAlive,
+			DefaultMenu: menu.DefaultMenu,
+			Children:    buildMenuExportTree(menus, strconv.Itoa(int(menu.ID)), visited),
+		}
+		if len(menu.I18n) > 0 {
+			item.I18n = make(map[string]string, len(menu.I18n))
+			for _, i18n := range menu.I18n {
+				item.I18n[i18n.Locale] = i18n.Title
+			}
+		}
+		for _, parameter := range menu.Parameters {
+			item.Parameters = append(item.Parameters, MenuParameterExport{Type: parameter.Type, Key: parameter.Key, Value: parameter.Value})
+		}
+		tree = append(tree, item)
+	}
+	return tree
+}
+
+// ImportMenus 导入菜单树以及角色菜单关联
+// 以菜单完整路径作为唯一标识：已存在的菜单更新，不存在的菜单新建，文件中没有的菜单保持不变，因此可以重复导入
+// 角色的菜单权限会被替换为文件中的菜单，文件中没有列出的角色保持不变
+func ImportMenus(db *gorm.DB, data MenuExport) (MenuImportResult, error) {
+	return importMenus(db, data, false)
+}
+
+// ImportMissingMenus 只导入还不存在的菜单，用于启动时补充新版本的菜单
+// 已存在的菜单保持不变，新建的菜单追加到文件中列出的角色，角色原有的菜单权限保持不变
+func ImportMissingMenus(db *gorm.DB, data MenuExport) (MenuImportResult, error) {
+	return importMenus(db, data, true)
+}
+
+func importMenus(db *gorm.DB, data MenuExport, onlyMissing bool) (MenuImportResult, error) {
+	result := MenuImportResult{MissingAuthorities: []string{}, MissingMenus: []string{}}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var menus []system.SysBaseMenu
+		if err := tx.Find(&menus).Error; err != nil {
+			return err
+		}
+		paths := menuFullPaths(menus)
+		existing := make(map[string]system.SysBaseMenu, len(menus))
+		for _, menu := range menus {
+			existing[paths[menu.ID]] = menu
+		}
+
+		imported := make(map[string]uint)
+		var importTree func(items []MenuExportItem, parentId string, parentPath string, level uint) error
+		importTree = func(items []MenuExportItem, parentId string, parentPath string, level uint) error {
+			for _, item := range items {
+				fullPath := MenuFullPath(parentPath, item.Path)
+				menu, ok := existing[fullPath]
+				if ok && onlyMissing {
+					if err := importTree(item.Children, strconv.Itoa(int(menu.ID)), fullPath, level+1); err != nil {
+						return err
+					}
+					continue
+				}
+				values := map[string]interface{}{
+					"menu_level":   level,
+					"parent_id":    parentId,
+					"path":         item.Path,
+					"name":         item.Name,
+					"component":    item.Component,
+					"hidden":       item.Hidden,
+					"sort":         item.Sort,
+					"enable":       item.Enable,
+					"title":        item.Title,
+					"icon":         item.Icon,
+					"close_tab":    item.CloseTab,
+					"keep_alive":   item.KeepAlive,
+					"default_menu": item.DefaultMenu,
+				}
+				if ok {
+					if err := tx.Model(&menu).Updates(values).Error; err != nil {
+						return fmt.Errorf("更新菜单 %s 失败: %v", fullPath, err)
+					}
+					result.Updated++
+				} else {
+					menu = system.SysBaseMenu{ParentId: parentId, Path: item.Path}
+					if err := tx.Create(&menu).Error; err != nil {
+						return fmt.Errorf("创建菜单 %s 失败: %v", fullPath, err)
+					}
+					// 使用 map 更新，保证 false 值也能写入
+					if err := tx.Model(&menu).Updates(values).Error; err != nil {
+						return fmt.Errorf("创建菜单 %s 失败: %v", fullPath, err)
+					}
+					result.Created++
+				}
+				imported[fullPath] = menu.ID
+
+				var i18n []system.SysBaseMenuI18n
+				for locale, title := range item.I18n {
+					i18n = append(i18n, system.SysBaseMenuI18n{Locale: locale, Title: title})
+				}
+				if err := ReplaceMenuI18n(tx, menu.ID, i18n); err != nil {
+					return fmt.Errorf("更新菜单 %s 的多语言标题失败: %v", fullPath, err)
+				}
+				var parameters []system.SysBaseMenuParameter
+				for _, parameter := range item.Parameters {
+					parameters = append(parameters, system.SysBaseMenuParameter{Type: parameter.Type, Key: parameter.Key, Value: parameter.Value})
+				}
+				if err := ValidateMenuParameters(parameters); err != nil {
+					return fmt.Errorf("菜单 %s 的路由参数错误: %v", fullPath, err)
+				}
+				if err := ReplaceMenuParameters(tx, menu.ID, parameters); err != nil {
+					return fmt.Errorf("更新菜单 %s 的路由参数失败: %v", fullPath, err)
+				}
+				if err := importTree(item.Children, strconv.Itoa(int(menu.ID)), fullPath, level+1); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err := importTree(data.Menus, "0", "", 0); err != nil {
+			return err
+		}
+
+		for _, item := range data.Authorities {
+			var authority system.SysAuthority
+			if err := tx.Where("authority_id = ?", item.AuthorityId).First(&authority).Error; err != nil {
+				result.MissingAuthorities = append(result.MissingAuthorities, item.AuthorityId)
+				continue
+			}
+			var authorityMenus []system.SysBaseMenu
+			for _, p := range item.Menus {
+				id, ok := imported[p]
+				if !ok && onlyMissing {
+					// 只追加新建的菜单，已存在的菜单是否关联以角色当前的设置为准
+					continue
+				}
+				if !ok {
+					if menu, found := existing[p]; found {
+						id, ok = menu.ID, true
+					}
+				}
+				if !ok {
+					result.MissingMenus = append(result.MissingMenus, p)
+					continue
+				}
+				authorityMenus = append(authorityMenus, system.SysBaseMenu{Model: gorm.Model{ID: id}})
+			}
+			if onlyMissing {
+				if len(authorityMenus) == 0 {
+					continue
+				}
+				if err := tx.Model(&authority).Association("SysBaseMenus").Append(authorityMenus); err != nil {
+					return fmt.Errorf("设置角色 %s 的菜单失败: %v", item.AuthorityId, err)
+				}
+			} else if err := tx.Model(&authority).Association("SysBaseMenus").Replace(authorityMenus); err != nil {
+				return fmt.Errorf("设置角色 %s 的菜单失败: %v", item.AuthorityId, err)
+			}
+			result.Authorities++
+		}
+		return nil
+	})
+	return result, err
+}
+
+// MarshalMenuExport 按格式（json、yaml）序列化菜单导出数据
+func MarshalMenuExport(data MenuExport, format string) ([]byte, error) {
+	switch format {
+	case "yaml", "yml":
+		return yaml.Marshal(data)
+	case "json", "":
+		return json.MarshalIndent(data, "", "  ")
+	default:
+		return nil, fmt.Errorf("不支持的格式: %s", format)
+	}
+}
+
+// UnmarshalMenuExport 按格式（json、yaml）解析菜单导入数据
+func UnmarshalMenuExport(content []byte, format string) (MenuExport, error) {
+	var data MenuExport
+	var err error
+	switch format {
+	case "yaml", "yml":
+		err = yaml.Unmarshal(content, &data)
+	case "json", "":
+		err = json.Unmarshal(content, &data)
+	default:
+		err = fmt.Errorf("不支持的格式: %s", format)
+	}
+	return data, err
+}
+
+// ImportMenusFromFile 从 json/yaml 文件导入菜单，格式由文件后缀决定
+func ImportMenusFromFile(db *gorm.DB, file string) (MenuImportResult, error) {
+	data, err := ReadMenuFile(file)
+	if err != nil {
+		return MenuImportResult{}, err
+	}
+	return ImportMenus(db, data)
+}
+
+// ReadMenuFile 读取 json/yaml 菜单文件，格式由文件后缀决定
+func ReadMenuFile(file string) (MenuExport, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return MenuExport{}, fmt.Errorf("读取菜单文件失败: %v", err)
+	}
+	data, err := UnmarshalMenuExport(content, strings.TrimPrefix(filepath.Ext(file), "."))
+	if err != nil {
+		return MenuExport{}, fmt.Errorf("解析菜单文件失败: %v", err)
+	}
+	return data, nil
+}
+
+// MenuMaxDepth 菜单树最大层级数，顶级菜单为第1层
+const MenuMaxDepth = 5
+
+// MenuMove 菜单移动项
+type MenuMove struct {
+	ID       uint   `json:"id" binding:"required"` // 菜单ID
+	ParentId string `json:"parentId"`              // 新的父菜单ID，为空或"0"表示顶级菜单
+	Sort     int    `json:"sort"`                  // 新的排序标记
+}
+
+// ValidateMenuTree 校验菜单树：父菜单必须存在、不能形成环、不能超过最大层级
+// 校验通过时返回每个菜单的层级（顶级菜单为0），key 为菜单ID
+func ValidateMenuTree(menus []system.SysBaseMenu) (map[uint]uint, error) {
+	byId := make(map[string]system.SysBaseMenu, len(menus))
+	for _, menu := range menus {
+		byId[strconv.Itoa(int(menu.ID))] = menu
+	}
+
+	levels := make(map[uint]uint, len(menus))
+	for _, menu := range menus {
+		var level uint
+		visited := map[uint]bool{menu.ID: true}
+		current := menu
+		for current.ParentId != "0" && current.ParentId != "" {
+			parent, ok := byId[current.ParentId]
+			if !ok {
+				return nil, fmt.Errorf("菜单 %s(ID:%d) 的父菜单(ID:%s)不存在", current.Title, current.ID, current.ParentId)
+			}
+			if visited[parent.ID] {
+				return nil, fmt.Errorf("菜单 %s(ID:%d) 的父菜单形成了循环", menu.Title, menu.ID)
+			}
+			visited[parent.ID] = true
+			current = parent
+			level++
+		}
+		if level >= MenuMaxDepth {
+			return nil, fmt.Errorf("菜单 %s(ID:%d) 超过最大层级 %d", menu.Title, menu.ID, MenuMaxDepth)
+		}
+		levels[menu.ID] = level
+	}
+	return levels, nil
+}
+
+// MoveMenus 批量调整菜单的父菜单和排序
+// 先在内存中应用全部移动再整体校验，校验通过后在同一事务中写入，并重新计算所有受影响菜单的层级
+func MoveMenus(db *gorm.DB, moves []MenuMove) (int, error) {
+	var updated int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var menus []system.SysBaseMenu
+		if err := tx.Find(&menus).Error; err != nil {
+			return err
+		}
+		index := make(map[uint]int, len(menus))
+		for i, menu := range menus {
+			index[menu.ID] = i
+		}
+
+		moved := make(map[uint]bool, len(moves))
+		for _, move := range moves {
+			i, ok := index[move.ID]
+			if !ok {
+				return fmt.Errorf("菜单(ID:%d)不存在", move.ID)
+			}
+			if move.ParentId == "" {
+				move.ParentId = "0"
+			}
+			menus[i].ParentId = move.ParentId
+			menus[i].Sort = move.Sort
+			moved[move.ID] = true
+		}
+
+		levels, err := ValidateMenuTree(menus)
+		if err != nil {
+			return err
+		}
+
+		for _, menu := range menus {
+			if !moved[menu.ID] && menu.MenuLevel == levels[menu.ID] {
+				continue
+			}
+			values := map[string]interface{}{"menu_level": levels[menu.ID]}
+			if moved[menu.ID] {
+				values["parent_id"] = menu.ParentId
+				values["sort"] = menu.Sort
+			}
+			if err := tx.Model(&system.SysBaseMenu{}).Where("id = ?", menu.ID).Updates(values).Error; err != nil {
+				return fmt.Errorf("更新菜单(ID:%d)失败: %v", menu.ID, err)
+			}
+			updated++
+		}
+		return nil
+	})
+	return updated, err
+}
+
+// LocalizeMenuTitle 按语言返回菜单标题，精确匹配优先，其次按主语言匹配，没有对应翻译时返回默认标题
+func LocalizeMenuTitle(menu system.SysBaseMenu, locale string) string {
+	title := menu.Title
+	if locale == "" {
+		return title
+	}
+	for _, i18n := range menu.I18n {
+		exact, matched := MatchLocale(i18n.Locale, locale)
+		if exact {
+			return i18n.Title
+		}
+		if matched && title == menu.Title {
+			title = i18n.Title
+		}
+	}
+	return title
+}
+
+// ValidateMenuParameters 校验菜单路由参数
+func ValidateMenuParameters(parameters []system.SysBaseMenuParameter) error {
+	for _, parameter := range parameters {
+		if parameter.Type != system.MenuParameterQuery && parameter.Type != system.MenuParameterParams {
+			return fmt.Errorf("参数类型只能是 %s 或 %s", system.MenuParameterQuery, system.MenuParameterParams)
+		}
+		if parameter.Key == "" {
+			return fmt.Errorf("参数名不能为空")
+		}
+	}
+	return nil
+}
+
+// ReplaceMenuI18n 替换菜单的多语言标题
+func ReplaceMenuI18n(tx *gorm.DB, menuId uint, i18n []system.SysBaseMenuI18n) error {
+	if err := tx.Unscoped().Where("sys_base_menu_id = ?", menuId).Delete(&system.SysBaseMenuI18n{}).Error; err != nil {
+		return err
+	}
+	for i := range i18n {
+		i18n[i].ID = 0
+		i18n[i].SysBaseMenuID = menuId
+	}
+	if len(i18n) == 0 {
+		return nil
+	}
+	return tx.Create(&i18n).Error
+}
+
+// ReplaceMenuParameters 替换菜单的路由参数
+func ReplaceMenuParameters(tx *gorm.DB, menuId uint, parameters []system.SysBaseMenuParameter) error {
+	if err := tx.Unscoped().Where("sys_base_menu_id = ?", menuId).Delete(&system.SysBaseMenuParameter{}).Error; err != nil {
+		return err
+	}
+	for i := range parameters {
+		parameters[i].ID = 0
+		parameters[i].SysBaseMenuID = menuId
+	}
+	if len(parameters) == 0 {
+		return nil
+	}
+	return tx.Create(&parameters).Error
+}
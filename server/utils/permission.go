@@ -0,0 +1,258 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"jiangyi.com/global"
+	"jiangyi.com/model/common"
+	"jiangyi.com/model/system"
+)
+
+// 权限判定链中每一步的结果
+const (
+	PermissionAllow = "allow" // 该规则放行
+	PermissionDeny  = "deny"  // 该规则拒绝
+	PermissionSkip  = "skip"  // 该规则未命中，继续判定
+	PermissionInfo  = "info"  // 仅供诊断参考，不参与判定
+)
+
+// PermissionStep 权限判定链中的一步
+type PermissionStep struct {
+	Rule   string `json:"rule"`   // 规则类型：user, role, super-admin, menu, permission, parent, data-scope, grant
+	Result string `json:"result"` // allow, deny, skip, info
+	Detail string `json:"detail"` // 规则说明
+}
+
+// PermissionDecision 权限判定结果
+type PermissionDecision struct {
+	Allowed     bool             `json:"allowed"`
+	AuthorityId string           `json:"authorityId"`
+	Method      string           `json:"method"`
+	Path        string           `json:"path"`
+	Action      string           `json:"action,omitempty"` // 操作权限标识，见 system.PermissionDefinitions
+	Chain       []PermissionStep `json:"chain"`
+}
+
+// AddStep 追加一步判定记录
+func (d *PermissionDecision) AddStep(rule, result, detail string) {
+	d.Chain = append(d.Chain, PermissionStep{Rule: rule, Result: result, Detail: detail})
+}
+
+// ExplainPermission 判定角色是否有权限访问指定路径，并返回决定该结果的规则链
+// authority 需要预加载 SysBaseMenus，DataAuthority 预加载后会作为诊断信息输出
+func ExplainPermission(authority *system.SysAuthority, path string, method string) PermissionDecision {
+	decision := PermissionDecision{
+		AuthorityId: authority.AuthorityId,
+		Method:      strings.ToUpper(method),
+		Path:        path,
+	}
+
+	// 父角色和数据权限不参与接口权限判定，仅输出方便排查
+	if authority.ParentId != "" && authority.ParentId != "0" {
+		decision.AddStep("parent", PermissionInfo, fmt.Sprintf("父角色 %s 的菜单权限不会被继承", authority.ParentId))
+	}
+	if len(authority.DataAuthority) > 0 {
+		ids := make([]string, 0, len(authority.DataAuthority))
+		for _, data := range authority.DataAuthority {
+			ids = append(ids, data.AuthorityId)
+		}
+		decision.AddStep("data-scope", PermissionInfo, "可访问角色数据: "+strings.Join(ids, ", "))
+	}
+
+	// 超级管理员（888）拥有所有权限
+	if authority.AuthorityId == "888" {
+		decision.Allowed = true
+		decision.AddStep("super-admin", PermissionAllow, "超级管理员(888)拥有所有权限")
+		return decision
+	}
+	decision.AddStep("super-admin", PermissionSkip, "非超级管理员")
+
+	// 检查角色的菜单权限
+	for _, menu := range authority.SysBaseMenus {
+		// 简单匹配：如果路径包含菜单路径，则认为有权限
+		// 实际项目中可以根据需要实现更复杂的匹配逻辑
+		if menu.Path != "" && containsPath(path, menu.Path) {
+			decision.Allowed = true
+			decision.AddStep("menu", PermissionAllow, fmt.Sprintf("匹配菜单 %s(ID:%d) 的路径 %s", menu.Title, menu.ID, menu.Path))
+			return decision
+		}
+	}
+
+	decision.AddStep("menu", PermissionDeny, fmt.Sprintf("角色的 %d 个菜单中没有与该路径匹配的菜单", len(authority.SysBaseMenus)))
+	return decision
+}
+
+// ActiveGrants 查询用户当前处于有效期内的临时角色授权
+func ActiveGrants(userId uint) []system.SysAuthorityGrant {
+	var grants []system.SysAuthorityGrant
+	now := time.Now()
+	global.JY_DB.Where("user_id = ? AND status = ? AND start_at <= ? AND end_at > ?", userId, system.GrantStatusActive, now, now).
+		Order("end_at ASC").Find(&grants)
+	return grants
+}
+
+// ExplainUserPermission 在角色判定的基础上，继续判定用户当前生效的临时授权角色
+func ExplainUserPermission(userId uint, authority *system.SysAuthority, path string, method string) PermissionDecision {
+	decision := ExplainPermission(authority, path, method)
+	if decision.Allowed || userId == 0 {
+		return decision
+	}
+
+	for _, grant := range ActiveGrants(userId) {
+		var granted system.SysAuthority
+		err := global.JY_DB.Where("authority_id = ?", grant.AuthorityId).Preload("SysBaseMenus").First(&granted).Error
+		if err != nil {
+			decision.AddStep("grant", PermissionSkip, fmt.Sprintf("临时授权(ID:%d)的角色 %s 不存在", grant.ID, grant.AuthorityId))
+			continue
+		}
+		decision.AddStep("grant", PermissionInfo, fmt.Sprintf("临时授权(ID:%d)角色 %s，有效期至 %s", grant.ID, grant.AuthorityId, grant.EndAt.Format("2006-01-02 15:04:05")))
+		sub := ExplainPermission(&granted, path, method)
+		decision.Chain = append(decision.Chain, sub.Chain...)
+		if sub.Allowed {
+			decision.Allowed = true
+			return decision
+		}
+	}
+	return decision
+}
+
+// ExplainAction 判定角色是否拥有操作权限，并返回决定该结果的规则链，判定规则与 HasPermission 一致
+func ExplainAction(authority *system.SysAuthority, action string) PermissionDecision {
+	decision := PermissionDecision{AuthorityId: authority.AuthorityId, Action: action}
+	if authority.AuthorityId == "888" {
+		decision.Allowed = true
+		decision.AddStep("super-admin", PermissionAllow, "超级管理员(888)拥有所有操作权限")
+		return decision
+	}
+	decision.AddStep("super-admin", PermissionSkip, "非超级管理员")
+
+	if !authority.Enable {
+		decision.AddStep("role", PermissionDeny, fmt.Sprintf("角色 %s 已禁用，操作权限不生效", authority.AuthorityId))
+		return decision
+	}
+	if slices.Contains(authority.Permissions, action) {
+		decision.Allowed = true
+		decision.AddStep("permission", PermissionAllow, fmt.Sprintf("角色 %s 拥有操作权限 %s", authority.AuthorityId, action))
+		return decision
+	}
+	decision.AddStep("permission", PermissionDeny, fmt.Sprintf("角色 %s 的 %d 个操作权限中没有 %s", authority.AuthorityId, len(authority.Permissions), action))
+	return decision
+}
+
+// ExplainUserAction 在角色判定的基础上，继续判定用户当前生效的临时授权角色是否拥有操作权限
+func ExplainUserAction(userId uint, authority *system.SysAuthority, action string) PermissionDecision {
+	if !system.IsPermissionDefined(action) {
+		decision := PermissionDecision{AuthorityId: authority.AuthorityId, Action: action}
+		decision.AddStep("permission", PermissionDeny, fmt.Sprintf("操作权限 %s 不存在", action))
+		return decision
+	}
+	decision := ExplainAction(authority, action)
+	if decision.Allowed || userId == 0 {
+		return decision
+	}
+
+	for _, grant := range ActiveGrants(userId) {
+		var granted system.SysAuthority
+		err := global.JY_DB.Where("authority_id = ?", grant.AuthorityId).First(&granted).Error
+		if err != nil {
+			decision.AddStep("grant", PermissionSkip, fmt.Sprintf("临时授权(ID:%d)的角色 %s 不存在", grant.ID, grant.AuthorityId))
+			continue
+		}
+		decision.AddStep("grant", PermissionInfo, fmt.Sprintf("临时授权(ID:%d)角色 %s，有效期至 %s", grant.ID, grant.AuthorityId, grant.EndAt.Format("2006-01-02 15:04:05")))
+		sub := ExplainAction(&granted, action)
+		decision.Chain = append(decision.Chain, sub.Chain...)
+		if sub.Allowed {
+			decision.Allowed = true
+			return decision
+		}
+	}
+	return decision
+}
+
+// containsPath 检查请求路径是否匹配菜单路径
+func containsPath(requestPath string, menuPath string) bool {
+	// 精确匹配
+	if requestPath == menuPath {
+		return true
+	}
+	// 前缀匹配（支持子路径）
+	if len(menuPath) > 0 && len(requestPath) >= len(menuPath) {
+		if requestPath[:len(menuPath)] == menuPath {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSuperAdmin 只允许超级管理员（888）操作的接口调用，不是超级管理员时直接返回失败响应
+// 租户、菜单导入导出、角色授权、客户设置等影响全局或跨租户的维护操作都通过这里判断
+func CheckSuperAdmin(c *gin.Context) bool {
+	claims, exists := c.Get("claims")
+	if !exists {
+		common.FailWithMsg(c, "获取用户信息失败")
+		return false
+	}
+	if claims.(*CustomClaims).AuthorityId != "888" {
+		common.FailWithMsg(c, "仅超级管理员可以操作")
+		return false
+	}
+	return true
+}
+
+// permissionsKey 当前请求已查询的操作权限缓存在 gin.Context 中的键
+const permissionsKey = "permissions"
+
+// HasPermission 判断当前用户是否拥有操作权限，超级管理员拥有所有权限，生效中的临时授权角色的权限同样有效（包括临时授权超级管理员）
+// 同一请求中只查询一次
+func HasPermission(c *gin.Context, code string) bool {
+	if cached, exists := c.Get(permissionsKey); exists {
+		return cached.(map[string]bool)[code]
+	}
+	claims, exists := c.Get("claims")
+	if !exists {
+		return false
+	}
+	waitClaims := claims.(*CustomClaims)
+	authorityIds := []string{waitClaims.AuthorityId}
+	for _, grant := range ActiveGrants(waitClaims.ID) {
+		authorityIds = append(authorityIds, grant.AuthorityId)
+	}
+	permissions := make(map[string]bool)
+	// 与接口权限判定一致，自身角色或临时授权的角色是超级管理员（888）时拥有所有权限
+	if slices.Contains(authorityIds, "888") {
+		for _, definition := range system.PermissionDefinitions {
+			permissions[definition.Code] = true
+		}
+	} else {
+		var authorities []system.SysAuthority
+		global.JY_DB.Select("authority_id", "permissions").Where("authority_id IN ? AND enable = ?", authorityIds, true).Find(&authorities)
+		for _, authority := range authorities {
+			for _, code := range authority.Permissions {
+				permissions[code] = true
+			}
+		}
+	}
+	c.Set(permissionsKey, permissions)
+	return permissions[code]
+}
+
+// NormalizePermissions 校验操作权限标识并去重
+func NormalizePermissions(codes []string) ([]string, error) {
+	permissions := make([]string, 0, len(codes))
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if !system.IsPermissionDefined(code) {
+			return nil, fmt.Errorf("操作权限 %s 不存在", code)
+		}
+		if !seen[code] {
+			seen[code] = true
+			permissions = append(permissions, code)
+		}
+	}
+	return permissions, nil
+}
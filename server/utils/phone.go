@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultPhoneCountryCode 未带国际区号的号码按中国大陆号码处理
+const DefaultPhoneCountryCode = "86"
+
+// phoneSeparators 号码中常见的分隔符，规范化时去掉
+var phoneSeparators = strings.NewReplacer(" ", "", "\u3000", "", "\u00a0", "", "-", "", "(", "", ")", "", ".", "")
+
+// NormalizePhone 将电话号码转换为 E.164 格式，如 “138 0013 8000”、“+86 138-0013-8000”、“008613800138000” 都转换为 +8613800138000
+// 未带国际区号时按中国大陆号码处理：11 位 1 开头的为手机号，0 开头的为带区号的固定电话
+func NormalizePhone(phone string) (string, error) {
+	number := phoneSeparators.Replace(strings.TrimSpace(phone))
+	switch {
+	case strings.HasPrefix(number, "+"):
+		number = number[1:]
+	case strings.HasPrefix(number, "00"):
+		number = number[2:]
+	case strings.HasPrefix(number, "0"):
+		number = DefaultPhoneCountryCode + number[1:]
+	case len(number) == 11 && number[0] == '1':
+		number = DefaultPhoneCountryCode + number
+	case len(number) == 13 && strings.HasPrefix(number, DefaultPhoneCountryCode+"1"):
+	default:
+		return "", errors.New("电话号码格式不正确")
+	}
+	// +86 后误带了长途区号前的 0，如 +86 010 12345678
+	if strings.HasPrefix(number, DefaultPhoneCountryCode+"0") {
+		number = DefaultPhoneCountryCode + number[len(DefaultPhoneCountryCode)+1:]
+	}
+
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", errors.New("电话号码格式不正确")
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return "", errors.New("电话号码格式不正确")
+		}
+	}
+	return "+" + number, nil
+}
+
+// NormalizeContactPhone 规范化联系人电话，号码部分转换为 E.164 格式，分机号（#、*、逗号、分号之后的部分）原样保留
+func NormalizeContactPhone(phone string) (string, error) {
+	number, extension := phone, ""
+	if i := strings.IndexAny(phone, "#*,;"); i >= 0 {
+		number, extension = phone[:i], phone[i:]
+		if len(extension) < 2 || len(extension) > 9 || strings.Trim(extension[1:], "0123456789") != "" {
+			return "", errors.New("分机号格式不正确")
+		}
+	}
+	number, err := NormalizePhone(number)
+	if err != nil {
+		return "", err
+	}
+	return number + extension, nil
+}
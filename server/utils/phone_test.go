@@ -0,0 +1,62 @@
+package utils
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		phone   string
+		want    string
+		wantErr bool
+	}{
+		{phone: "13800138000", want: "+8613800138000"},
+		{phone: "138 0013 8000", want: "+8613800138000"},
+		{phone: "+86 138-0013-8000", want: "+8613800138000"},
+		{phone: "008613800138000", want: "+8613800138000"},
+		{phone: "8613800138000", want: "+8613800138000"},
+		{phone: " (010) 1234.5678 ", want: "+861012345678"},
+		{phone: "+86 010 12345678", want: "+861012345678"},
+		{phone: "+1 (415) 555-2671", want: "+14155552671"},
+		{phone: "", wantErr: true},
+		{phone: "12345", wantErr: true},
+		{phone: "0", wantErr: true},
+		{phone: "1380013800a", wantErr: true},
+		{phone: "+86 138a0013800", wantErr: true},
+		{phone: "+1234567890123456", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizePhone(tt.phone)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizePhone(%q) error = %v, wantErr %v", tt.phone, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizePhone(%q) = %q, want %q", tt.phone, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeContactPhone(t *testing.T) {
+	tests := []struct {
+		phone   string
+		want    string
+		wantErr bool
+	}{
+		{phone: "138 0013 8000", want: "+8613800138000"},
+		{phone: "010-12345678#123", want: "+861012345678#123"},
+		{phone: "010-12345678,8001", want: "+861012345678,8001"},
+		{phone: "010-12345678#", wantErr: true},
+		{phone: "010-12345678#12a", wantErr: true},
+		{phone: "010-12345678#1234567890", wantErr: true},
+		{phone: "12345#123", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeContactPhone(tt.phone)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeContactPhone(%q) error = %v, wantErr %v", tt.phone, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeContactPhone(%q) = %q, want %q", tt.phone, got, tt.want)
+		}
+	}
+}
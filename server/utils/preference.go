@@ -0,0 +1,252 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/system"
+)
+
+// PreferenceLocale 语言偏好的设置项标识，菜单标题等按该语言返回
+const PreferenceLocale = "locale"
+
+// PreferenceTimezone 时区偏好的设置项标识，按天汇总等场景按该时区计算日期
+const PreferenceTimezone = "timezone"
+
+// preferenceKeyPattern 设置项标识格式：字母开头，只能包含字母、数字、下划线和点
+var preferenceKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.]{0,63}$`)
+
+// DefaultPreferenceDefinitions 内置的偏好设置项，初始化数据库时写入，已存在的不会覆盖
+var DefaultPreferenceDefinitions = []system.SysPreferenceDefinition{
+	{
+		Key:          PreferenceLocale,
+		Name:         "语言",
+		Schema:       json.RawMessage(`{"type":"string","pattern":"^[a-z]{2,3}([-_][A-Za-z0-9]{2,8})*$"}`),
+		DefaultValue: json.RawMessage(`"zh-CN"`),
+		Sort:         1,
+	},
+	{
+		Key:          PreferenceTimezone,
+		Name:         "时区",
+		Schema:       json.RawMessage(`{"type":"string","format":"timezone"}`),
+		DefaultValue: json.RawMessage(`"Asia/Shanghai"`),
+		Sort:         2,
+	},
+	{
+		Key:          "theme",
+		Name:         "主题",
+		Schema:       json.RawMessage(`{"type":"string","enum":["light","dark","auto"]}`),
+		DefaultValue: json.RawMessage(`"light"`),
+		Sort:         3,
+	},
+	{
+		Key:          "pageSize",
+		Name:         "默认每页条数",
+		Schema:       json.RawMessage(`{"type":"integer","minimum":1,"maximum":100}`),
+		DefaultValue: json.RawMessage(`10`),
+		Sort:         4,
+	},
+	{
+		Key:          "notification",
+		Name:         "通知设置",
+		Schema:       json.RawMessage(`{"type":"object","properties":{"site":{"type":"boolean"},"email":{"type":"boolean"},"sound":{"type":"boolean"}},"additionalProperties":false}`),
+		DefaultValue: json.RawMessage(`{"site":true,"email":false,"sound":true}`),
+		Sort:         5,
+	},
+}
+
+// InitPreferenceDefinitions 写入缺少的内置偏好设置项
+func InitPreferenceDefinitions(db *gorm.DB) error {
+	for _, definition := range DefaultPreferenceDefinitions {
+		var count int64
+		if err := db.Model(&system.SysPreferenceDefinition{}).Where(map[string]interface{}{"key": definition.Key}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		definition := definition
+		if err := db.Create(&definition).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidatePreferenceDefinition 校验设置项定义：标识格式、Schema 本身以及默认值是否符合 Schema
+func ValidatePreferenceDefinition(definition system.SysPreferenceDefinition) error {
+	if !preferenceKeyPattern.MatchString(definition.Key) {
+		return errors.New("设置项标识只能包含字母、数字、下划线和点，且以字母开头")
+	}
+	if len(definition.Schema) == 0 {
+		return errors.New("取值规则不能为空")
+	}
+	schema, err := ParseJSONSchema(definition.Schema)
+	if err != nil {
+		return err
+	}
+	if len(definition.DefaultValue) == 0 {
+		return errors.New("默认值不能为空")
+	}
+	if err := schema.ValidateJSON(definition.DefaultValue); err != nil {
+		return fmt.Errorf("默认值不符合取值规则: %v", err)
+	}
+	return nil
+}
+
+// preferenceDefinitions 获取全部设置项定义，key 为设置项标识
+func preferenceDefinitions(db *gorm.DB) (map[string]system.SysPreferenceDefinition, error) {
+	var definitions []system.SysPreferenceDefinition
+	if err := db.Find(&definitions).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string]system.SysPreferenceDefinition, len(definitions))
+	for _, definition := range definitions {
+		result[definition.Key] = definition
+	}
+	return result, nil
+}
+
+// GetUserPreferences 获取用户的全部偏好设置，未设置的项返回默认值
+// 设置项被删除后对应的值不再返回；规则修改后不再符合规则的值回退为默认值；对象类型的值与默认值合并
+func GetUserPreferences(db *gorm.DB, userId uint) (map[string]json.RawMessage, error) {
+	definitions, err := preferenceDefinitions(db)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]json.RawMessage, len(definitions))
+	for key, definition := range definitions {
+		result[key] = definition.DefaultValue
+	}
+
+	var values []system.SysUserPreference
+	if err := db.Where("user_id = ?", userId).Find(&values).Error; err != nil {
+		return nil, err
+	}
+	for _, value := range values {
+		definition, ok := definitions[value.Key]
+		if !ok {
+			continue
+		}
+		schema, err := ParseJSONSchema(definition.Schema)
+		if err != nil || schema.ValidateJSON(value.Value) != nil {
+			continue
+		}
+		result[value.Key] = mergePreferenceValue(definition.DefaultValue, value.Value)
+	}
+	return result, nil
+}
+
+// mergePreferenceValue 合并对象类型的设置值，value 中的属性覆盖 base 中的同名属性，非对象类型直接返回 value
+// 对象类型的设置值只保存用户修改过的属性，返回时用该方法补全默认值中的其他属性
+func mergePreferenceValue(base, value json.RawMessage) json.RawMessage {
+	var bases, values map[string]json.RawMessage
+	if json.Unmarshal(base, &bases) != nil || json.Unmarshal(value, &values) != nil || bases == nil || values == nil {
+		return value
+	}
+	for key, item := range values {
+		bases[key] = item
+	}
+	merged, err := json.Marshal(bases)
+	if err != nil {
+		return value
+	}
+	return merged
+}
+
+// SetUserPreferences 批量修改用户偏好设置，值为 null 时恢复默认值
+// 所有值校验通过后才会保存，任一项不合法时整体不修改
+func SetUserPreferences(db *gorm.DB, userId uint, values map[string]json.RawMessage) error {
+	definitions, err := preferenceDefinitions(db)
+	if err != nil {
+		return err
+	}
+	for key, value := range values {
+		definition, ok := definitions[key]
+		if !ok {
+			return fmt.Errorf("不支持的设置项: %s", key)
+		}
+		if isJSONNull(value) {
+			continue
+		}
+		schema, err := ParseJSONSchema(definition.Schema)
+		if err != nil {
+			return fmt.Errorf("设置项 %s 的取值规则错误: %v", key, err)
+		}
+		if err := schema.ValidateJSON(value); err != nil {
+			return fmt.Errorf("%s: %v", definition.Name, err)
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for key, value := range values {
+			where := system.SysUserPreference{UserId: userId, Key: key}
+			if isJSONNull(value) {
+				if err := tx.Where(&where).Delete(&system.SysUserPreference{}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			var preference system.SysUserPreference
+			result := tx.Where(&where).Limit(1).Find(&preference)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				where.Value = value
+				if err := tx.Create(&where).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			// 对象类型只修改请求中包含的属性
+			if err := tx.Model(&preference).Update("value", mergePreferenceValue(preference.Value, value)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UserLocale 获取用户自己设置的语言，未设置时返回空字符串（不使用默认值，以便回退到请求头）
+func UserLocale(userId uint) string {
+	var preference system.SysUserPreference
+	result := global.JY_DB.Where(&system.SysUserPreference{UserId: userId, Key: PreferenceLocale}).Limit(1).Find(&preference)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return ""
+	}
+	var locale string
+	if json.Unmarshal(preference.Value, &locale) != nil {
+		return ""
+	}
+	return locale
+}
+
+// UserLocation 获取用户设置的时区（未设置时使用默认值），无效时使用服务器时区
+func UserLocation(userId uint) *time.Location {
+	preferences, err := GetUserPreferences(global.JY_DB, userId)
+	if err != nil {
+		return time.Local
+	}
+	var timezone string
+	if json.Unmarshal(preferences[PreferenceTimezone], &timezone) != nil || timezone == "" {
+		return time.Local
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Local
+	}
+	return location
+}
+
+// isJSONNull 判断是否为 JSON null
+func isJSONNull(value json.RawMessage) bool {
+	value = bytes.TrimSpace(value)
+	return len(value) == 0 || string(value) == "null"
+}
@@ -0,0 +1,327 @@
+package search
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+)
+
+// ActivityTypes 建立索引的跟进记录类型，系统自动记录的变更不参与检索
+var ActivityTypes = []string{business.ActivityCall, business.ActivityVisit, business.ActivityEmail, business.ActivityNote}
+
+// rebuildBatchSize 重建索引时每批处理的记录数
+const rebuildBatchSize = 200
+
+// IndexCustomers 重新生成客户文档，已删除的客户删除文档
+func IndexCustomers(db *gorm.DB, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var customers []business.Customer
+	if err := db.Preload("Contacts").Preload("Addresses").Where("id IN ?", ids).Find(&customers).Error; err != nil {
+		return err
+	}
+	documents := make([]business.SearchDocument, 0, len(customers))
+	for _, customer := range customers {
+		documents = append(documents, customerDocument(customer))
+	}
+	if err := saveDocuments(db, documents); err != nil {
+		return err
+	}
+	return deleteStaleDocuments(db, business.SearchDocCustomer, documents, "source_id IN ?", ids)
+}
+
+// IndexActivities 重新生成跟进记录文档，ids 为跟进记录ID，customerIds 下的所有跟进记录也一并处理
+func IndexActivities(db *gorm.DB, ids []uint, customerIds []uint) error {
+	if len(ids) == 0 && len(customerIds) == 0 {
+		return nil
+	}
+	scope := db.Where("id IN ?", ids)
+	stale := clause.Expr{SQL: "source_id IN ?", Vars: []interface{}{ids}}
+	if len(customerIds) > 0 {
+		scope = scope.Or("customer_id IN ?", customerIds)
+		stale = clause.Expr{SQL: "(source_id IN ? OR customer_id IN ?)", Vars: []interface{}{ids, customerIds}}
+	}
+	var activities []business.CustomerActivity
+	if err := db.Where(scope).Where("type IN ?", ActivityTypes).Find(&activities).Error; err != nil {
+		return err
+	}
+	documents := make([]business.SearchDocument, 0, len(activities))
+	for _, activity := range activities {
+		documents = append(documents, activityDocument(activity))
+	}
+	if err := saveDocuments(db, documents); err != nil {
+		return err
+	}
+	return deleteStaleDocuments(db, business.SearchDocActivity, documents, stale.SQL, stale.Vars...)
+}
+
+// Rebuild 清空并重新生成所有文档
+func Rebuild(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&business.SearchDocument{}).Error; err != nil {
+			return err
+		}
+		var customers []business.Customer
+		err := tx.Preload("Contacts").Preload("Addresses").FindInBatches(&customers, rebuildBatchSize, func(batch *gorm.DB, _ int) error {
+			documents := make([]business.SearchDocument, 0, len(customers))
+			for _, customer := range customers {
+				documents = append(documents, customerDocument(customer))
+			}
+			return saveDocuments(tx, documents)
+		}).Error
+		if err != nil {
+			return err
+		}
+		var activities []business.CustomerActivity
+		return tx.Where("type IN ?", ActivityTypes).FindInBatches(&activities, rebuildBatchSize, func(batch *gorm.DB, _ int) error {
+			documents := make([]business.SearchDocument, 0, len(activities))
+			for _, activity := range activities {
+				documents = append(documents, activityDocument(activity))
+			}
+			return saveDocuments(tx, documents)
+		}).Error
+	})
+}
+
+// RebuildIfEmpty 没有任何文档时（首次启用全文检索）按已有数据生成文档
+func RebuildIfEmpty(db *gorm.DB) error {
+	var document business.SearchDocument
+	err := db.Select("id").Limit(1).Find(&document).Error
+	if err != nil || document.ID != 0 {
+		return err
+	}
+	return Rebuild(db)
+}
+
+// customerDocument 客户文档：标题为客户名称，内容为电话、标签、联系人和地址
+func customerDocument(customer business.Customer) business.SearchDocument {
+	parts := []string{customer.CustomerPhone, strings.Join(customer.Tags, " ")}
+	for _, contact := range customer.Contacts {
+		parts = append(parts, contact.Name, contact.Title, strings.Join(contact.Phones, " "), strings.Join(contact.Emails, " "), contact.Remark)
+	}
+	for _, address := range customer.Addresses {
+		parts = append(parts, address.Label, address.Province+address.City+address.District+address.Detail, address.PostalCode)
+	}
+	return business.SearchDocument{
+		TenantID:   customer.TenantID,
+		CustomerID: customer.ID,
+		Type:       business.SearchDocCustomer,
+		SourceID:   customer.ID,
+		Title:      customer.CustomerName,
+		Content:    joinNonEmpty(parts),
+	}
+}
+
+// activityDocument 跟进记录文档：内容为跟进记录内容
+func activityDocument(activity business.CustomerActivity) business.SearchDocument {
+	return business.SearchDocument{
+		TenantID:   activity.TenantID,
+		CustomerID: activity.CustomerID,
+		Type:       business.SearchDocActivity,
+		SourceID:   activity.ID,
+		Content:    activity.Content,
+	}
+}
+
+func joinNonEmpty(parts []string) string {
+	values := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return strings.Join(values, "\n")
+}
+
+// saveDocuments 按文档类型和来源记录ID写入或更新文档
+func saveDocuments(db *gorm.DB, documents []business.SearchDocument) error {
+	if len(documents) == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "type"}, {Name: "source_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "tenant_id", "customer_id", "title", "content"}),
+	}).Create(&documents).Error
+}
+
+// deleteStaleDocuments 删除范围内来源记录已不存在（或不再需要索引）的文档
+func deleteStaleDocuments(db *gorm.DB, docType string, kept []business.SearchDocument, scope string, vars ...interface{}) error {
+	query := db.Where("type = ?", docType).Where(scope, vars...)
+	if len(kept) > 0 {
+		sourceIds := make([]uint, 0, len(kept))
+		for _, document := range kept {
+			sourceIds = append(sourceIds, document.SourceID)
+		}
+		query = query.Where("source_id NOT IN ?", sourceIds)
+	}
+	return query.Delete(&business.SearchDocument{}).Error
+}
+
+// RegisterCallbacks 注册写入回调，客户、联系人、地址、跟进记录写入后同步更新文档
+// 回调与写入使用同一个事务；更新文档失败只记录日志，不影响业务写入
+func RegisterCallbacks(db *gorm.DB) error {
+	if err := db.Callback().Create().After("gorm:create").Register("search:create", indexCallback); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("search:update", indexCallback); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("search:delete", indexCallback)
+}
+
+var (
+	customerType = reflect.TypeOf(business.Customer{})
+	contactType  = reflect.TypeOf(business.CustomerContact{})
+	addressType  = reflect.TypeOf(business.CustomerAddress{})
+	activityType = reflect.TypeOf(business.CustomerActivity{})
+)
+
+func indexCallback(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil || db.RowsAffected == 0 {
+		return
+	}
+	modelType := db.Statement.Schema.ModelType
+	if modelType != customerType && modelType != contactType && modelType != addressType && modelType != activityType {
+		return
+	}
+
+	ids, customerIds := affectedIds(db.Statement)
+	// 文档的租户来自来源记录，不按当前请求的租户过滤或覆盖
+	session := db.Session(&gorm.Session{NewDB: true, Context: context.Background()})
+	var err error
+	switch modelType {
+	case customerType:
+		err = IndexCustomers(session, ids...)
+	case activityType:
+		err = IndexActivities(session, ids, customerIds)
+	default:
+		if len(ids) > 0 {
+			var owners []uint
+			if err = session.Model(reflect.New(modelType).Interface()).Where("id IN ?", ids).Distinct().Pluck("customer_id", &owners).Error; err == nil {
+				customerIds = append(customerIds, owners...)
+			}
+		}
+		if err == nil {
+			err = IndexCustomers(session, customerIds...)
+		}
+	}
+	if err != nil {
+		global.JY_LOG.Warn("更新全文索引失败", zap.String("table", db.Statement.Table), zap.Error(err))
+	}
+}
+
+// affectedIds 从写入的记录、更新的值和查询条件中收集受影响的记录ID和客户ID
+func affectedIds(stmt *gorm.Statement) (ids []uint, customerIds []uint) {
+	collect := func(column string, value interface{}) {
+		switch column {
+		case "id":
+			ids = appendIds(ids, value)
+		case "customer_id":
+			customerIds = appendIds(customerIds, value)
+		}
+	}
+
+	// 写入的记录
+	for _, rv := range structValues(stmt.ReflectValue) {
+		for _, column := range []string{"id", "customer_id"} {
+			if field := stmt.Schema.LookUpField(column); field != nil {
+				if value, isZero := field.ValueOf(stmt.Context, rv); !isZero {
+					collect(column, value)
+				}
+			}
+		}
+	}
+
+	// Update/Updates 中的新值，如合并客户时修改 customer_id
+	if values, ok := stmt.Dest.(map[string]interface{}); ok {
+		for key, value := range values {
+			if field := stmt.Schema.LookUpField(key); field != nil {
+				collect(field.DBName, value)
+			}
+		}
+	}
+
+	// 查询条件中的 id = ?、id IN ?、customer_id = ? 等
+	if where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where); ok {
+		for _, expression := range where.Exprs {
+			switch expr := expression.(type) {
+			case clause.Eq:
+				collect(columnName(expr.Column), expr.Value)
+			case clause.IN:
+				collect(columnName(expr.Column), expr.Values)
+			case clause.Expr:
+				for _, column := range []string{"id", "customer_id"} {
+					if len(expr.Vars) > 0 && (hasConditionPrefix(expr.SQL, column+" = ?") || hasConditionPrefix(expr.SQL, column+" IN ?")) {
+						collect(column, expr.Vars[0])
+					}
+				}
+			}
+		}
+	}
+	return ids, customerIds
+}
+
+// hasConditionPrefix 条件以 prefix 开头，且其后没有紧跟其他字符（如 id = ?0）
+func hasConditionPrefix(sql, prefix string) bool {
+	sql = strings.TrimSpace(sql)
+	return strings.HasPrefix(sql, prefix) && (len(sql) == len(prefix) || sql[len(prefix)] == ' ')
+}
+
+func columnName(column interface{}) string {
+	switch c := column.(type) {
+	case clause.Column:
+		return c.Name
+	case string:
+		return c
+	}
+	return ""
+}
+
+// structValues 写入的记录，单条记录或切片
+func structValues(rv reflect.Value) []reflect.Value {
+	rv = reflect.Indirect(rv)
+	switch rv.Kind() {
+	case reflect.Struct:
+		return []reflect.Value{rv}
+	case reflect.Slice, reflect.Array:
+		values := make([]reflect.Value, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			if item := reflect.Indirect(rv.Index(i)); item.Kind() == reflect.Struct {
+				values = append(values, item)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// appendIds 追加ID，value 可以是单个整数或整数切片，其他类型（如子查询）忽略
+func appendIds(ids []uint, value interface{}) []uint {
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.Int() > 0 {
+			ids = append(ids, uint(rv.Int()))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > 0 {
+			ids = append(ids, uint(rv.Uint()))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			ids = appendIds(ids, rv.Index(i).Interface())
+		}
+	case reflect.Interface:
+		if !rv.IsNil() {
+			ids = appendIds(ids, rv.Elem().Interface())
+		}
+	}
+	return ids
+}
@@ -0,0 +1,32 @@
+package search
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Like 不使用全文索引，按 LIKE 模糊匹配标题和内容，用于数据库不支持全文索引或关键字过短时
+type Like struct{}
+
+func (Like) Name() string {
+	return "like"
+}
+
+func (Like) Init(db *gorm.DB) error {
+	return nil
+}
+
+func (Like) Match(db *gorm.DB, terms []string) (*gorm.DB, clause.Expr, bool) {
+	for _, term := range terms {
+		pattern := "%" + escapeLike(term) + "%"
+		db = db.Where("(title LIKE ? ESCAPE '!' OR content LIKE ? ESCAPE '!')", pattern, pattern)
+	}
+	return db, clause.Expr{SQL: "0"}, true
+}
+
+// escapeLike 转义 LIKE 中的通配符，配合 ESCAPE '!' 使用
+func escapeLike(value string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(value)
+}
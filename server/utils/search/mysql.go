@@ -0,0 +1,57 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"jiangyi.com/model/business"
+)
+
+const (
+	mysqlFulltextIndex = "idx_search_document_fulltext"
+	// mysqlMinTermLength ngram 分词的默认长度（ngram_token_size=2），更短的词无法通过索引匹配
+	mysqlMinTermLength = 2
+)
+
+// MySQL MySQL InnoDB FULLTEXT 索引，使用 ngram 分词以支持中文
+type MySQL struct{}
+
+func (MySQL) Name() string {
+	return "mysql-fulltext"
+}
+
+// Init 在文档表上创建 FULLTEXT 索引
+func (MySQL) Init(db *gorm.DB) error {
+	table, err := tableName(db, &business.SearchDocument{})
+	if err != nil {
+		return err
+	}
+	var exists int64
+	err = db.Raw("SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?", table, mysqlFulltextIndex).
+		Scan(&exists).Error
+	if err != nil || exists > 0 {
+		return err
+	}
+	return db.Exec(fmt.Sprintf("ALTER TABLE `%s` ADD FULLTEXT INDEX `%s` (`title`, `content`) WITH PARSER ngram", table, mysqlFulltextIndex)).Error
+}
+
+// Match 布尔模式下每个词作为一个必须匹配的短语
+func (MySQL) Match(db *gorm.DB, terms []string) (*gorm.DB, clause.Expr, bool) {
+	if len(terms) == 0 {
+		return db, clause.Expr{}, false
+	}
+	phrases := make([]string, 0, len(terms))
+	for _, term := range terms {
+		term = strings.ReplaceAll(term, `"`, " ")
+		if utf8.RuneCountInString(strings.TrimSpace(term)) < mysqlMinTermLength {
+			return db, clause.Expr{}, false
+		}
+		phrases = append(phrases, `+"`+term+`"`)
+	}
+	against := strings.Join(phrases, " ")
+	match := clause.Expr{SQL: "MATCH(title, content) AGAINST (? IN BOOLEAN MODE)", Vars: []interface{}{against}}
+	return db.Where(match), match, true
+}
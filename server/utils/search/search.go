@@ -0,0 +1,187 @@
+package search
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"jiangyi.com/model/business"
+)
+
+// Engine 全文检索的实现，开发环境使用 SQLite FTS5，生产环境使用 MySQL FULLTEXT（ngram 分词），都不可用时使用 LIKE
+type Engine interface {
+	// Name 实现的名称，用于日志
+	Name() string
+	// Init 创建全文索引（虚拟表、触发器或 FULLTEXT 索引），数据库不支持时返回错误
+	Init(db *gorm.DB) error
+	// Match 为文档查询追加关键字条件并返回相关度表达式（越大越相关），
+	// 关键字不适合全文索引时（如短于分词长度）ok 为 false，由调用方降级为 LIKE
+	Match(db *gorm.DB, terms []string) (query *gorm.DB, score clause.Expr, ok bool)
+}
+
+// current 当前使用的实现，启动时由 core.InitSearch 设置
+var current Engine = Like{}
+
+// Use 设置全文检索的实现
+func Use(engine Engine) {
+	current = engine
+}
+
+// Current 当前使用的全文检索实现
+func Current() Engine {
+	return current
+}
+
+// MaxTerms 关键字最多拆分的词数
+const MaxTerms = 5
+
+// Terms 按空白拆分关键字，去掉重复的词
+func Terms(keyword string) []string {
+	terms := make([]string, 0)
+	for _, term := range strings.Fields(keyword) {
+		if len(terms) >= MaxTerms {
+			break
+		}
+		if !containsTerm(terms, term) {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+func containsTerm(terms []string, term string) bool {
+	for _, t := range terms {
+		if strings.EqualFold(t, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// Query 匹配关键字的文档查询，所有词都需要匹配；返回的相关度表达式用于排序，LIKE 查询时为常量
+func Query(db *gorm.DB, keyword string) (*gorm.DB, clause.Expr) {
+	terms := Terms(keyword)
+	base := db.Model(&business.SearchDocument{})
+	if query, score, ok := current.Match(base, terms); ok {
+		return query, score
+	}
+	query, score, _ := Like{}.Match(base, terms)
+	return query, score
+}
+
+// CustomerIDs 名称、电话、标签、联系人、地址匹配关键字的客户ID子查询
+func CustomerIDs(db *gorm.DB, keyword string) *gorm.DB {
+	query, _ := Query(db, keyword)
+	return query.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "type"}, Value: business.SearchDocCustomer}).
+		Select("?", clause.Column{Table: clause.CurrentTable, Name: "customer_id"})
+}
+
+// Hit 一条检索结果
+type Hit struct {
+	Type         string  `json:"type"`                                         // 文档类型 customer, activity
+	SourceID     uint    `json:"sourceId"`                                     // 来源记录ID
+	CustomerID   uint    `json:"customerId"`                                   // 客户ID
+	CustomerName string  `json:"customerName"`                                 // 客户名称
+	Title        string  `json:"title"`                                        // 标题，匹配的词以 <em> 标记，其余内容已转义
+	Snippet      string  `json:"snippet" mask:"phoneText,customer:view-phone"` // 内容中匹配的片段，标记方式同标题，其中的电话按权限脱敏
+	Score        float64 `json:"score"`                                        // 相关度，越大越相关，LIKE 查询时为 0
+}
+
+type scoredDocument struct {
+	business.SearchDocument
+	Score float64
+}
+
+// Search 在 customers（可见客户的ID子查询）范围内检索文档，docType 为空时检索所有类型，按相关度排序
+func Search(db *gorm.DB, keyword string, customers *gorm.DB, docType string, limit, offset int) ([]Hit, int64, error) {
+	query, score := Query(db, keyword)
+	query = query.Where("customer_id IN (?)", customers)
+	if docType != "" {
+		query = query.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "type"}, Value: docType})
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var documents []scoredDocument
+	err := query.Select("?, ? AS score", clause.Column{Table: clause.CurrentTable, Name: "*", Raw: true}, score).
+		Order("score DESC").Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: "id"}, Desc: true}).
+		Limit(limit).Offset(offset).Scan(&documents).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	terms := Terms(keyword)
+	hits := make([]Hit, 0, len(documents))
+	for _, document := range documents {
+		hits = append(hits, Hit{
+			Type:       document.Type,
+			SourceID:   document.SourceID,
+			CustomerID: document.CustomerID,
+			Title:      Highlight(document.Title, terms),
+			Snippet:    Highlight(Snippet(document.Content, terms, 40), terms),
+			Score:      document.Score,
+		})
+	}
+	return hits, total, nil
+}
+
+// termPattern 不区分大小写匹配任一词的正则
+func termPattern(terms []string) *regexp.Regexp {
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		quoted = append(quoted, regexp.QuoteMeta(term))
+	}
+	return regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+}
+
+// Highlight 转义文本并用 <em> 标记匹配的词
+func Highlight(text string, terms []string) string {
+	if len(terms) == 0 || text == "" {
+		return html.EscapeString(text)
+	}
+	var builder strings.Builder
+	last := 0
+	for _, loc := range termPattern(terms).FindAllStringIndex(text, -1) {
+		builder.WriteString(html.EscapeString(text[last:loc[0]]))
+		builder.WriteString("<em>")
+		builder.WriteString(html.EscapeString(text[loc[0]:loc[1]]))
+		builder.WriteString("</em>")
+		last = loc[1]
+	}
+	builder.WriteString(html.EscapeString(text[last:]))
+	return builder.String()
+}
+
+// Snippet 截取第一个匹配的词前后各 width 个字的片段，没有匹配时截取开头
+func Snippet(text string, terms []string, width int) string {
+	start := 0
+	if len(terms) > 0 {
+		if loc := termPattern(terms).FindStringIndex(text); loc != nil {
+			start = loc[0]
+		}
+	}
+	// 向前回退 width 个字
+	from := start
+	for i := 0; i < width && from > 0; i++ {
+		_, size := utf8.DecodeLastRuneInString(text[:from])
+		from -= size
+	}
+	to := start
+	for i := 0; i < width*2 && to < len(text); i++ {
+		_, size := utf8.DecodeRuneInString(text[to:])
+		to += size
+	}
+	snippet := strings.Join(strings.Fields(text[from:to]), " ")
+	if from > 0 {
+		snippet = "…" + snippet
+	}
+	if to < len(text) {
+		snippet += "…"
+	}
+	return snippet
+}
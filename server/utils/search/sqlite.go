@@ -0,0 +1,82 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"jiangyi.com/model/business"
+)
+
+// fts5MinTermLength trigram 分词只能匹配至少 3 个字的词
+const fts5MinTermLength = 3
+
+// FTS5 SQLite FTS5 全文索引，使用 trigram 分词以支持中文等不以空格分词的文字
+// mattn/go-sqlite3 默认不包含 FTS5，需要使用 -tags sqlite_fts5 编译
+type FTS5 struct {
+	table string // 文档表
+	fts   string // 全文索引虚拟表
+}
+
+func (e *FTS5) Name() string {
+	return "sqlite-fts5"
+}
+
+// Init 创建以文档表为外部内容的虚拟表，并通过触发器保持同步；虚拟表首次创建时按已有文档建立索引
+func (e *FTS5) Init(db *gorm.DB) error {
+	table, err := tableName(db, &business.SearchDocument{})
+	if err != nil {
+		return err
+	}
+	e.table, e.fts = table, table+"_fts"
+
+	var exists int64
+	if err = db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", e.fts).Scan(&exists).Error; err != nil {
+		return err
+	}
+	statements := []string{
+		fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %[2]s USING fts5(title, content, content='%[1]s', content_rowid='id', tokenize='trigram')", e.table, e.fts),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %[2]s_ai AFTER INSERT ON %[1]s BEGIN INSERT INTO %[2]s(rowid, title, content) VALUES (new.id, new.title, new.content); END", e.table, e.fts),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %[2]s_ad AFTER DELETE ON %[1]s BEGIN INSERT INTO %[2]s(%[2]s, rowid, title, content) VALUES ('delete', old.id, old.title, old.content); END", e.table, e.fts),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %[2]s_au AFTER UPDATE ON %[1]s BEGIN INSERT INTO %[2]s(%[2]s, rowid, title, content) VALUES ('delete', old.id, old.title, old.content); INSERT INTO %[2]s(rowid, title, content) VALUES (new.id, new.title, new.content); END", e.table, e.fts),
+	}
+	if exists == 0 {
+		statements = append(statements, fmt.Sprintf("INSERT INTO %[1]s(%[1]s) VALUES ('rebuild')", e.fts))
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Match 每个词作为一个短语匹配，相关度为 bm25 的相反数，标题的权重高于内容
+func (e *FTS5) Match(db *gorm.DB, terms []string) (*gorm.DB, clause.Expr, bool) {
+	if len(terms) == 0 {
+		return db, clause.Expr{}, false
+	}
+	phrases := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < fts5MinTermLength {
+			return db, clause.Expr{}, false
+		}
+		phrases = append(phrases, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+	}
+	query := db.Joins(fmt.Sprintf("JOIN %[1]s ON %[1]s.rowid = %[2]s.id", e.fts, e.table)).
+		Where(e.fts+" MATCH ?", strings.Join(phrases, " "))
+	return query, clause.Expr{SQL: fmt.Sprintf("-bm25(%s, 10.0, 1.0)", e.fts)}, true
+}
+
+// tableName 模型对应的表名（含配置的表前缀）
+func tableName(db *gorm.DB, model interface{}) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return "", err
+	}
+	return stmt.Schema.Table, nil
+}
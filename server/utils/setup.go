@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/system"
+)
+
+// 初始化时可选的默认数据
+const (
+	SetupSeedRoles = "roles" // 默认角色（超级管理员之外的常用角色）
+	SetupSeedMenus = "menus" // 内置菜单及默认角色的菜单权限
+)
+
+// DefaultMenuFile 内置菜单文件，未配置 system.menu-seed-file 时初始化使用该文件
+const DefaultMenuFile = "resource/menus.yaml"
+
+// DefaultSetupRoles 初始化时可选写入的默认角色，超级管理员（888）总是会创建
+var DefaultSetupRoles = []system.SysAuthority{
+	{AuthorityId: "8881", AuthorityName: "部门管理员", ParentId: "888", DataScope: system.DataScopeDeptAndChild},
+	{AuthorityId: "9528", AuthorityName: "普通用户", ParentId: "888", DataScope: system.DataScopeSelf},
+}
+
+// setupState 系统初始化状态，未初始化时除初始化接口外的所有接口都不可用
+var setupState struct {
+	sync.Mutex
+	required bool
+	token    string
+}
+
+// SetupOptions 初始化参数
+type SetupOptions struct {
+	Username string
+	Password string
+	NickName string
+	Seeds    []string // 需要写入的默认数据，见 SetupSeedRoles、SetupSeedMenus
+}
+
+// SetupResult 初始化结果
+type SetupResult struct {
+	Admin system.SysUser    `json:"admin"` // 创建的管理员
+	Roles []string          `json:"roles"` // 创建的角色ID
+	Menus *MenuImportResult `json:"menus"` // 菜单导入结果，未选择导入菜单时为空
+}
+
+// InitSetupState 启动时检查系统是否已初始化
+// 已有初始化记录，或者是升级前就已有用户的数据库，视为已初始化；否则进入未初始化状态，生成一次性的初始化令牌并输出到日志
+func InitSetupState(db *gorm.DB) error {
+	setupState.Lock()
+	defer setupState.Unlock()
+
+	var setupCount int64
+	if err := db.Model(&system.SysSetup{}).Count(&setupCount).Error; err != nil {
+		return err
+	}
+	if setupCount > 0 {
+		setupState.required = false
+		return nil
+	}
+
+	var userCount int64
+	if err := db.Unscoped().Model(&system.SysUser{}).Count(&userCount).Error; err != nil {
+		return err
+	}
+	if userCount > 0 {
+		setupState.required = false
+		return db.Create(&system.SysSetup{Remark: "已有用户数据，自动标记为已初始化"}).Error
+	}
+
+	setupState.required = true
+	setupState.token = RandomPassword(32)
+	fmt.Printf("系统尚未初始化，请从本机访问初始化接口，或使用一次性初始化令牌: %s\n", setupState.token)
+	if global.JY_LOG != nil {
+		global.JY_LOG.Warn("系统尚未初始化，请从本机访问初始化接口，或使用一次性初始化令牌", zap.String("setupToken", setupState.token))
+	}
+	return nil
+}
+
+// SetupRequired 系统是否处于未初始化状态
+func SetupRequired() bool {
+	setupState.Lock()
+	defer setupState.Unlock()
+	return setupState.required
+}
+
+// CheckSetupAccess 检查是否允许调用初始化接口：直接来自本机的请求，或者携带了正确的初始化令牌
+// remoteIP 必须是 TCP 连接的对端地址，不能使用可伪造的 X-Forwarded-For
+func CheckSetupAccess(remoteIP string, token string) bool {
+	if ip := net.ParseIP(remoteIP); ip != nil && ip.IsLoopback() {
+		return true
+	}
+	setupState.Lock()
+	defer setupState.Unlock()
+	return setupState.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(setupState.token)) == 1
+}
+
+// RunSetup 初始化系统：创建超级管理员角色和管理员账号，按选择写入默认数据，并写入初始化记录
+// 初始化成功后初始化接口永久关闭，令牌失效
+func RunSetup(db *gorm.DB, options SetupOptions) (SetupResult, error) {
+	setupState.Lock()
+	defer setupState.Unlock()
+	result := SetupResult{Roles: []string{}}
+	if !setupState.required {
+		return result, errors.New("系统已初始化")
+	}
+
+	options.Username = strings.TrimSpace(options.Username)
+	if options.Username == "" {
+		return result, errors.New("用户名不能为空")
+	}
+	if err := ValidatePassword(options.Password); err != nil {
+		return result, err
+	}
+	if options.NickName == "" {
+		options.NickName = "超级管理员"
+	}
+	seeds := make(map[string]bool, len(options.Seeds))
+	for _, seed := range options.Seeds {
+		if seed != SetupSeedRoles && seed != SetupSeedMenus {
+			return result, fmt.Errorf("不支持的默认数据: %s", seed)
+		}
+		seeds[seed] = true
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// 防止多实例同时初始化
+		var setupCount int64
+		if err := tx.Model(&system.SysSetup{}).Count(&setupCount).Error; err != nil {
+			return err
+		}
+		if setupCount > 0 {
+			return errors.New("系统已初始化")
+		}
+
+		roles := []system.SysAuthority{{AuthorityId: "888", AuthorityName: "超级管理员", ParentId: "0", DefaultRouter: "dashboard"}}
+		if seeds[SetupSeedRoles] {
+			roles = append(roles, DefaultSetupRoles...)
+		}
+		for _, role := range roles {
+			var count int64
+			if err := tx.Model(&system.SysAuthority{}).Where("authority_id = ?", role.AuthorityId).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+			role := role
+			role.Enable = true
+			if err := tx.Create(&role).Error; err != nil {
+				return err
+			}
+			result.Roles = append(result.Roles, role.AuthorityId)
+		}
+
+		result.Admin = system.SysUser{
+			Username:    options.Username,
+			Password:    BcryptHash(options.Password),
+			NickName:    options.NickName,
+			AuthorityId: "888",
+			Enable:      true,
+		}
+		if err := tx.Create(&result.Admin).Error; err != nil {
+			return fmt.Errorf("创建管理员失败: %v", err)
+		}
+
+		if seeds[SetupSeedMenus] {
+			menuFile := global.JY_Config.System.MenuSeedFile
+			if menuFile == "" {
+				menuFile = DefaultMenuFile
+			}
+			menus, err := ImportMenusFromFile(tx, menuFile)
+			if err != nil {
+				return fmt.Errorf("导入菜单失败: %v", err)
+			}
+			result.Menus = &menus
+		}
+
+		return tx.Create(&system.SysSetup{
+			AdminUserId: result.Admin.ID,
+			Seeds:       strings.Join(options.Seeds, ","),
+		}).Error
+	})
+	if err != nil {
+		return result, err
+	}
+
+	setupState.required = false
+	setupState.token = ""
+	return result, nil
+}
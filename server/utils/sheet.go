@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 表格文件格式
+const (
+	SheetCSV  = "csv"
+	SheetXLSX = "xlsx"
+)
+
+// utf8BOM 写在 CSV 开头，避免 Excel 打开中文乱码
+const utf8BOM = "\xEF\xBB\xBF"
+
+// SheetContentType 返回表格文件的 Content-Type
+func SheetContentType(format string) string {
+	if format == SheetXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// ReadSheet 读取 CSV 或 XLSX（第一个工作表）的全部行
+func ReadSheet(r io.Reader, format string) ([][]string, error) {
+	switch format {
+	case SheetCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) > 0 && len(rows[0]) > 0 {
+			rows[0][0] = strings.TrimPrefix(rows[0][0], utf8BOM)
+		}
+		return rows, nil
+	case SheetXLSX:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return f.GetRows(f.GetSheetName(0))
+	default:
+		return nil, fmt.Errorf("不支持的格式: %s", format)
+	}
+}
+
+// SheetWriter 逐行写入表格
+type SheetWriter interface {
+	WriteRow(row []string) error
+	// Flush 写入剩余数据，XLSX 在此时才输出完整文件
+	Flush() error
+}
+
+// NewSheetWriter 创建 CSV 或 XLSX 表格写入器
+func NewSheetWriter(w io.Writer, format string) (SheetWriter, error) {
+	switch format {
+	case SheetCSV:
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return nil, err
+		}
+		return &csvSheetWriter{writer: csv.NewWriter(w)}, nil
+	case SheetXLSX:
+		f := excelize.NewFile()
+		stream, err := f.NewStreamWriter(f.GetSheetName(0))
+		if err != nil {
+			return nil, err
+		}
+		return &xlsxSheetWriter{w: w, file: f, stream: stream}, nil
+	default:
+		return nil, fmt.Errorf("不支持的格式: %s", format)
+	}
+}
+
+type csvSheetWriter struct {
+	writer *csv.Writer
+}
+
+func (s *csvSheetWriter) WriteRow(row []string) error {
+	return s.writer.Write(row)
+}
+
+func (s *csvSheetWriter) Flush() error {
+	s.writer.Flush()
+	return s.writer.Error()
+}
+
+type xlsxSheetWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func (s *xlsxSheetWriter) WriteRow(row []string) error {
+	s.row++
+	cells := make([]interface{}, len(row))
+	for i, v := range row {
+		cells[i] = v
+	}
+	cell, err := excelize.CoordinatesToCellName(1, s.row)
+	if err != nil {
+		return err
+	}
+	return s.stream.SetRow(cell, cells)
+}
+
+func (s *xlsxSheetWriter) Flush() error {
+	defer s.file.Close()
+	if err := s.stream.Flush(); err != nil {
+		return err
+	}
+	return s.file.Write(s.w)
+}
@@ -0,0 +1,291 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"jiangyi.com/global"
+	"jiangyi.com/model/business"
+	"jiangyi.com/model/system"
+)
+
+// TrashResource 回收站支持的资源
+type TrashResource struct {
+	Name          string                                                // 资源名称
+	NewList       func() interface{}                                    // 返回模型切片的指针，用于列表查询
+	Model         interface{}                                           // 模型，用于恢复和彻底删除
+	OwnerColumn   string                                                // 记录归属用户的字段，用于数据范围过滤
+	SelfOnly      bool                                                  // 只能操作自己的记录（如AI会话），否则按数据范围过滤
+	AdminOnly     bool                                                  // 只允许超级管理员操作（如用户账号）
+	CheckRestore  func(tx *gorm.DB, id uint) error                      // 恢复前的检查，返回错误时跳过该记录
+	BeforeRestore func(tx *gorm.DB, id uint) (string, error)            // 恢复前的处理，返回需要提示的信息
+	AfterRestore  func(tx *gorm.DB, id uint, deletedAt time.Time) error // 恢复后的处理，deletedAt 为记录原来的删除时间
+	BeforePurge   func(tx *gorm.DB, id uint) error                      // 彻底删除前的处理，返回错误时跳过该记录
+}
+
+// TrashResources 回收站支持的资源，key 为接口中的资源类型
+var TrashResources = map[string]TrashResource{
+	"user": {
+		Name:          "用户",
+		NewList:       func() interface{} { return &[]system.SysUser{} },
+		Model:         &system.SysUser{},
+		AdminOnly:     true,
+		BeforeRestore: beforeRestoreUser,
+		BeforePurge:   beforePurgeUser,
+	},
+	"customer": {
+		Name:         "客户",
+		NewList:      func() interface{} { return &[]business.Customer{} },
+		Model:        &business.Customer{},
+		OwnerColumn:  "owner_id",
+		CheckRestore: checkRestoreCustomer,
+		BeforePurge:  beforePurgeCustomer,
+	},
+	"file": {
+		Name:        "文件",
+		NewList:     func() interface{} { return &[]system.ExaFileUploadAndDownload{} },
+		Model:       &system.ExaFileUploadAndDownload{},
+		OwnerColumn: "created_by",
+		BeforePurge: beforePurgeFile,
+	},
+	"aiConversation": {
+		Name:         "AI会话",
+		NewList:      func() interface{} { return &[]business.AIConversation{} },
+		Model:        &business.AIConversation{},
+		OwnerColumn:  "user_id",
+		SelfOnly:     true,
+		AfterRestore: afterRestoreConversation,
+		BeforePurge:  beforePurgeConversation,
+	},
+}
+
+// TrashItem 回收站列表项
+type TrashItem struct {
+	Data      interface{} `json:"data"`      // 被删除的记录
+	DeletedAt time.Time   `json:"deletedAt"` // 删除时间
+}
+
+// TrashResult 恢复、彻底删除的结果
+type TrashResult struct {
+	Success  int          `json:"success"`  // 成功数
+	Messages []TrashError `json:"messages"` // 失败或需要提示的记录
+}
+
+// TrashError 单条记录的处理信息
+type TrashError struct {
+	ID      uint   `json:"id"`
+	Message string `json:"message"`
+}
+
+// TrashItems 将模型切片转换为带删除时间的列表项
+func TrashItems(list interface{}) []TrashItem {
+	rv := reflect.Indirect(reflect.ValueOf(list))
+	items := make([]TrashItem, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		item := TrashItem{Data: rv.Index(i).Interface()}
+		if deletedAt, ok := rv.Index(i).FieldByName("DeletedAt").Interface().(gorm.DeletedAt); ok {
+			item.DeletedAt = deletedAt.Time
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// RestoreTrash 恢复已删除的记录，scope 为当前用户可操作记录的过滤条件，为 nil 时不过滤
+func RestoreTrash(db *gorm.DB, resource TrashResource, ids []uint, scope func(*gorm.DB) *gorm.DB) TrashResult {
+	result := TrashResult{Messages: []TrashError{}}
+	deleted := trashDeletedAt(db, resource, ids, scope)
+	for _, id := range ids {
+		deletedAt, ok := deleted[id]
+		if !ok {
+			result.Messages = append(result.Messages, TrashError{ID: id, Message: resource.Name + "不在回收站中"})
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if resource.CheckRestore != nil {
+				if err := resource.CheckRestore(tx, id); err != nil {
+					return err
+				}
+			}
+			if resource.BeforeRestore != nil {
+				msg, err := resource.BeforeRestore(tx, id)
+				if err != nil {
+					return err
+				}
+				if msg != "" {
+					result.Messages = append(result.Messages, TrashError{ID: id, Message: msg})
+				}
+			}
+			if err := tx.Unscoped().Model(resource.Model).Where("id = ?", id).Update("deleted_at", nil).Error; err != nil {
+				return fmt.Errorf("恢复%s失败", resource.Name)
+			}
+			if resource.AfterRestore != nil {
+				return resource.AfterRestore(tx, id, deletedAt)
+			}
+			return nil
+		})
+		if err != nil {
+			result.Messages = append(result.Messages, TrashError{ID: id, Message: err.Error()})
+			continue
+		}
+		result.Success++
+	}
+	return result
+}
+
+// PurgeTrash 彻底删除回收站中的记录，scope 为当前用户可操作记录的过滤条件，为 nil 时不过滤
+func PurgeTrash(db *gorm.DB, resource TrashResource, ids []uint, scope func(*gorm.DB) *gorm.DB) TrashResult {
+	result := TrashResult{Messages: []TrashError{}}
+	deleted := trashDeletedAt(db, resource, ids, scope)
+	for _, id := range ids {
+		if _, ok := deleted[id]; !ok {
+			result.Messages = append(result.Messages, TrashError{ID: id, Message: resource.Name + "不在回收站中"})
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if resource.BeforePurge != nil {
+				if err := resource.BeforePurge(tx, id); err != nil {
+					return err
+				}
+			}
+			if err := tx.Unscoped().Where("id = ?", id).Delete(resource.Model).Error; err != nil {
+				return fmt.Errorf("删除%s失败", resource.Name)
+			}
+			return nil
+		})
+		if err != nil {
+			result.Messages = append(result.Messages, TrashError{ID: id, Message: err.Error()})
+			continue
+		}
+		result.Success++
+	}
+	return result
+}
+
+// trashDeletedAt 查询在回收站中且当前用户可操作的记录的删除时间，key 为记录ID
+// 数据范围过滤本身需要查询数据库，需在事务外执行
+func trashDeletedAt(db *gorm.DB, resource TrashResource, ids []uint, scope func(*gorm.DB) *gorm.DB) map[uint]time.Time {
+	query := db.Unscoped().Model(resource.Model).Select("id", "deleted_at").Where("id IN ? AND deleted_at IS NOT NULL", ids)
+	if scope != nil {
+		query = query.Scopes(scope)
+	}
+	var rows []struct {
+		ID        uint
+		DeletedAt gorm.DeletedAt
+	}
+	query.Find(&rows)
+	deleted := make(map[uint]time.Time, len(rows))
+	for _, row := range rows {
+		deleted[row.ID] = row.DeletedAt.Time
+	}
+	return deleted
+}
+
+// PurgeExpiredTrash 彻底删除在回收站中超过保留天数的记录
+func PurgeExpiredTrash(db *gorm.DB, days int) map[string]TrashResult {
+	results := make(map[string]TrashResult)
+	cutoff := time.Now().AddDate(0, 0, -days)
+	for key, resource := range TrashResources {
+		var ids []uint
+		err := db.Unscoped().Model(resource.Model).Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Pluck("id", &ids).Error
+		if err != nil {
+			global.JY_LOG.Error("查询过期回收站记录失败", zap.String("resource", key), zap.Error(err))
+			continue
+		}
+		if len(ids) > 0 {
+			results[key] = PurgeTrash(db, resource, ids, nil)
+		}
+	}
+	return results
+}
+
+// beforeRestoreUser 恢复用户前检查用户名和昵称是否已被其他用户占用
+// 用户名冲突时无法恢复，昵称冲突时自动在昵称后追加用户ID
+func beforeRestoreUser(tx *gorm.DB, id uint) (string, error) {
+	var user system.SysUser
+	if err := tx.Unscoped().Where("id = ?", id).First(&user).Error; err != nil {
+		return "", err
+	}
+	var count int64
+	tx.Model(&system.SysUser{}).Where("username = ? AND id <> ?", user.Username, id).Count(&count)
+	if count > 0 {
+		return "", fmt.Errorf("用户名 %s 已被其他用户使用，无法恢复", user.Username)
+	}
+	tx.Model(&system.SysUser{}).Where("nick_name = ? AND id <> ?", user.NickName, id).Count(&count)
+	if count == 0 {
+		return "", nil
+	}
+	nickName := fmt.Sprintf("%s_%d", user.NickName, user.ID)
+	if err := tx.Unscoped().Model(&user).Update("nick_name", nickName).Error; err != nil {
+		return "", fmt.Errorf("昵称 %s 已被其他用户使用，重命名失败", user.NickName)
+	}
+	return fmt.Sprintf("昵称 %s 已被其他用户使用，已重命名为 %s", user.NickName, nickName), nil
+}
+
+// beforePurgeUser 彻底删除用户前删除用户的偏好设置和上传的头像
+func beforePurgeUser(tx *gorm.DB, id uint) error {
+	var user system.SysUser
+	if err := tx.Unscoped().Where("id = ?", id).First(&user).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", id).Delete(&system.SysUserPreference{}).Error; err != nil {
+		return err
+	}
+	if user.AvatarKey == "" {
+		return nil
+	}
+	oss, ok := global.JY_OSS.(interface{ DeleteFile(key string) error })
+	if !ok {
+		return fmt.Errorf("文件存储服务未初始化")
+	}
+	for _, key := range strings.Split(user.AvatarKey, ",") {
+		// 头像文件删除失败不影响删除用户
+		if err := oss.DeleteFile(key); err != nil {
+			global.JY_LOG.Warn("删除头像文件失败", zap.String("key", key), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// beforePurgeFile 彻底删除文件前解除跟进记录的附件关联，并删除对象存储中的文件
+func beforePurgeFile(tx *gorm.DB, id uint) error {
+	var file system.ExaFileUploadAndDownload
+	if err := tx.Unscoped().Where("id = ?", id).First(&file).Error; err != nil {
+		return err
+	}
+	// utils/upload 依赖 utils，这里只断言需要的方法，避免循环引用
+	oss, ok := global.JY_OSS.(interface{ DeleteFile(key string) error })
+	if !ok {
+		return fmt.Errorf("文件存储服务未初始化")
+	}
+	// 解除与客户跟进记录的附件关联
+	if err := tx.Exec("DELETE FROM customer_activity_files WHERE exa_file_upload_and_download_id = ?", id).Error; err != nil {
+		return err
+	}
+	if err := oss.DeleteFile(file.Key); err != nil {
+		global.JY_LOG.Error("文件删除失败", zap.String("key", file.Key), zap.Error(err))
+		return fmt.Errorf("删除存储中的文件失败: %v", err)
+	}
+	return nil
+}
+
+// afterRestoreConversation 恢复会话时一并恢复随会话删除的消息
+// 删除会话时先删除消息，消息的删除时间不晚于会话
+func afterRestoreConversation(tx *gorm.DB, id uint, deletedAt time.Time) error {
+	err := tx.Unscoped().Model(&business.AIMessage{}).
+		Where("conversation_id = ? AND deleted_at IS NOT NULL AND deleted_at <= ?", id, deletedAt).
+		Update("deleted_at", nil).Error
+	if err != nil {
+		return fmt.Errorf("恢复会话消息失败")
+	}
+	return nil
+}
+
+// beforePurgeConversation 彻底删除会话前删除会话下的消息
+func beforePurgeConversation(tx *gorm.DB, id uint) error {
+	return tx.Unscoped().Where("conversation_id = ?", id).Delete(&business.AIMessage{}).Error
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"path/filepath"
 	"strings"
@@ -97,6 +98,29 @@ func (t *TencentCOS) UploadFile(file *multipart.FileHeader) (string, string, err
 	return fileURL, filename, nil
 }
 
+// UploadReader 按指定文件名上传内容到COS
+func (t *TencentCOS) UploadReader(filename string, r io.Reader) (string, string, error) {
+	if filename == "" || strings.Contains(filename, "..") || strings.ContainsAny(filename, `\/:*?"<>|`) {
+		return "", "", errors.New("非法的文件名")
+	}
+
+	// 构建COS对象键（路径）
+	cosConfig := global.JY_Config.Cos
+	objectKey := filename
+	if cosConfig.PathPrefix != "" {
+		prefix := strings.Trim(cosConfig.PathPrefix, "/")
+		if prefix != "" {
+			objectKey = prefix + "/" + filename
+		}
+	}
+
+	_, err := t.client.Object.Put(context.Background(), objectKey, r, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("上传文件到COS失败: %v", err)
+	}
+	return t.buildFileURL(objectKey), filename, nil
+}
+
 // DeleteFile 从COS删除文件
 func (t *TencentCOS) DeleteFile(key string) error {
 	if key == "" {
@@ -66,6 +66,31 @@ func (*Local) UploadFile(file *multipart.FileHeader) (string, string, error) {
 	return filepath, filename, nil
 }
 
+// UploadReader 按指定文件名保存内容，文件名由调用方生成，不能包含路径
+func (*Local) UploadReader(filename string, r io.Reader) (string, string, error) {
+	if filename == "" || strings.Contains(filename, "..") || strings.ContainsAny(filename, `\/:*?"<>|`) {
+		return "", "", errors.New("非法的文件名")
+	}
+	mkdirErr := os.MkdirAll(global.JY_Config.Local.StorePath, os.ModePerm)
+	if mkdirErr != nil {
+		return "", "", errors.New("function os.MkdirAll() failed, err:" + mkdirErr.Error())
+	}
+	p := global.JY_Config.Local.StorePath + "/" + filename
+	filepath := global.JY_Config.Local.Path + "/" + filename
+
+	out, createErr := os.Create(p)
+	if createErr != nil {
+		return "", "", errors.New("function os.Create() failed, err:" + createErr.Error())
+	}
+	defer out.Close()
+
+	_, copyErr := io.Copy(out, r)
+	if copyErr != nil {
+		return "", "", errors.New("function io.Copy() failed, err:" + copyErr.Error())
+	}
+	return filepath, filename, nil
+}
+
 //@author: [piexlmax](https://github.com/piexlmax)
 //@author: [ccfish86](https://github.com/ccfish86)
 //@author: [SliverHorn](https://github.com/SliverHorn)
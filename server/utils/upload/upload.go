@@ -1,6 +1,7 @@
 package upload
 
 import (
+	"io"
 	"mime/multipart"
 
 	"jiangyi.com/global"
@@ -8,6 +9,8 @@ import (
 
 type OSS interface {
 	UploadFile(file *multipart.FileHeader) (string, string, error)
+	// UploadReader 按指定文件名上传内容（如服务端生成的文件），返回访问路径和 key
+	UploadReader(filename string, r io.Reader) (string, string, error)
 	DeleteFile(key string) error
 }
 